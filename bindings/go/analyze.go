@@ -0,0 +1,190 @@
+package zmin
+
+import (
+	"sort"
+	"strconv"
+)
+
+// analyzeTopKeys is the number of keys listed in Report.TopKeys
+const analyzeTopKeys = 10
+
+// Report describes the shape of a JSON document, as returned by Analyze
+type Report struct {
+	Size         int // input bytes
+	MinifiedSize int // bytes after minification
+	MaxDepth     int // deepest nesting of objects and arrays
+	Keys         int // object members
+
+	Values ValueCounts // values of each type, including the top-level one
+
+	LongestString     int    // length in bytes of the longest string value, escapes included
+	LongestStringPath string // JSON Pointer to the longest string value
+
+	// TopKeys lists the keys whose members take up the most space in the
+	// minified document, largest first
+	TopKeys []KeyFootprint
+}
+
+// ValueCounts is a histogram of value types
+type ValueCounts struct {
+	Objects int
+	Arrays  int
+	Strings int
+	Numbers int
+	Bools   int
+	Nulls   int
+}
+
+// KeyFootprint is the space taken up by the members with a given key
+type KeyFootprint struct {
+	Key   string
+	Count int // members with the key
+	Bytes int // minified size of the members, keys and nested values included
+}
+
+// Analyze scans a JSON document and reports its size, nesting depth, value
+// types, longest string and the keys that take up the most space, to find out
+// why a payload is large. Sizes are those of the minified document, however
+// the input is formatted. Keys are compared after unescaping, and the
+// footprint of a member includes the members nested within it.
+func Analyze(input []byte) (Report, error) {
+	a := &analyzer{s: newScanner(input), keys: make(map[string]*KeyFootprint)}
+	a.report.Size = len(input)
+	tok, err := a.s.next()
+	if err != nil {
+		return Report{}, err
+	}
+	size, err := a.value(tok)
+	if err != nil {
+		return Report{}, err
+	}
+	if _, err := a.s.next(); err != nil {
+		return Report{}, err
+	}
+	a.report.MinifiedSize = size
+
+	for _, key := range a.keys {
+		a.report.TopKeys = append(a.report.TopKeys, *key)
+	}
+	sort.Slice(a.report.TopKeys, func(i, j int) bool {
+		ki, kj := a.report.TopKeys[i], a.report.TopKeys[j]
+		if ki.Bytes != kj.Bytes {
+			return ki.Bytes > kj.Bytes
+		}
+		return ki.Key < kj.Key
+	})
+	if len(a.report.TopKeys) > analyzeTopKeys {
+		a.report.TopKeys = a.report.TopKeys[:analyzeTopKeys]
+	}
+	return a.report, nil
+}
+
+// analyzer walks the token stream recursively, like transformer, tracking the
+// path of the value being scanned
+type analyzer struct {
+	s      *scanner
+	report Report
+	path   []string
+	keys   map[string]*KeyFootprint
+}
+
+// value scans the value that tok begins and returns its minified size
+func (a *analyzer) value(tok token) (int, error) {
+	switch tok.kind {
+	case tokObjectStart:
+		a.report.Values.Objects++
+		return a.object()
+	case tokArrayStart:
+		a.report.Values.Arrays++
+		return a.array()
+	case tokString:
+		a.report.Values.Strings++
+		if n := len(tok.raw) - 2; n > a.report.LongestString || a.report.Values.Strings == 1 {
+			a.report.LongestString = n
+			a.report.LongestStringPath = formatPointer(a.path)
+		}
+	case tokNumber:
+		a.report.Values.Numbers++
+	case tokTrue, tokFalse:
+		a.report.Values.Bools++
+	case tokNull:
+		a.report.Values.Nulls++
+	}
+	return len(tok.raw), nil
+}
+
+// enter records the depth of a container whose opening bracket has been
+// consumed
+func (a *analyzer) enter() {
+	if depth := a.s.depth(); depth > a.report.MaxDepth {
+		a.report.MaxDepth = depth
+	}
+}
+
+// object scans the members of an object whose '{' has been consumed
+func (a *analyzer) object() (int, error) {
+	a.enter()
+	size := 2
+	for i := 0; ; i++ {
+		keyTok, err := a.s.next()
+		if err != nil {
+			return 0, err
+		}
+		if keyTok.kind == tokObjectEnd {
+			return size, nil
+		}
+		if i > 0 {
+			size++
+		}
+		key, err := decodeString(keyTok.raw)
+		if err != nil {
+			return 0, err
+		}
+		tok, err := a.s.next()
+		if err != nil {
+			return 0, err
+		}
+		a.path = append(a.path, key)
+		n, err := a.value(tok)
+		if err != nil {
+			return 0, err
+		}
+		a.path = a.path[:len(a.path)-1]
+
+		member := len(keyTok.raw) + 1 + n
+		footprint, ok := a.keys[key]
+		if !ok {
+			footprint = &KeyFootprint{Key: key}
+			a.keys[key] = footprint
+		}
+		footprint.Count++
+		footprint.Bytes += member
+		a.report.Keys++
+		size += member
+	}
+}
+
+// array scans the elements of an array whose '[' has been consumed
+func (a *analyzer) array() (int, error) {
+	a.enter()
+	size := 2
+	for i := 0; ; i++ {
+		tok, err := a.s.next()
+		if err != nil {
+			return 0, err
+		}
+		if tok.kind == tokArrayEnd {
+			return size, nil
+		}
+		if i > 0 {
+			size++
+		}
+		a.path = append(a.path, strconv.Itoa(i))
+		n, err := a.value(tok)
+		if err != nil {
+			return 0, err
+		}
+		a.path = a.path[:len(a.path)-1]
+		size += n
+	}
+}
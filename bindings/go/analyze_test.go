@@ -0,0 +1,72 @@
+package zmin
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	input := []byte(`{
+		"id": 1,
+		"tags": ["a", "bb"],
+		"items": [
+			{"id": 2, "name": "long name", "ok": true},
+			{"id": 3, "name": null}
+		]
+	}`)
+	report, err := Analyze(input)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	minified, err := MinifyBytes(input, SPORT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Size != len(input) || report.MinifiedSize != len(minified) {
+		t.Errorf("Expected sizes %d and %d, got %d and %d", len(input), len(minified), report.Size, report.MinifiedSize)
+	}
+	if report.MaxDepth != 3 {
+		t.Errorf("Expected depth 3, got %d", report.MaxDepth)
+	}
+	if report.Keys != 8 {
+		t.Errorf("Expected 8 keys, got %d", report.Keys)
+	}
+	expectedValues := ValueCounts{Objects: 3, Arrays: 2, Strings: 3, Numbers: 3, Bools: 1, Nulls: 1}
+	if report.Values != expectedValues {
+		t.Errorf("Expected %+v, got %+v", expectedValues, report.Values)
+	}
+	if report.LongestString != 9 || report.LongestStringPath != "/items/0/name" {
+		t.Errorf("Expected a 9-byte string at /items/0/name, got %d at %q", report.LongestString, report.LongestStringPath)
+	}
+
+	expectedKeys := []KeyFootprint{
+		{Key: "items", Count: 1, Bytes: len(`"items":[{"id":2,"name":"long name","ok":true},{"id":3,"name":null}]`)},
+		{Key: "name", Count: 2, Bytes: len(`"name":"long name"`) + len(`"name":null`)},
+		{Key: "id", Count: 3, Bytes: 3 * len(`"id":1`)},
+		{Key: "tags", Count: 1, Bytes: len(`"tags":["a","bb"]`)},
+		{Key: "ok", Count: 1, Bytes: len(`"ok":true`)},
+	}
+	if !reflect.DeepEqual(report.TopKeys, expectedKeys) {
+		t.Errorf("Expected %+v, got %+v", expectedKeys, report.TopKeys)
+	}
+}
+
+func TestAnalyzeScalar(t *testing.T) {
+	report, err := Analyze([]byte(` "" `))
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if report.MinifiedSize != 2 || report.MaxDepth != 0 || report.Values.Strings != 1 || report.TopKeys != nil {
+		t.Errorf("Unexpected report %+v", report)
+	}
+}
+
+func TestAnalyzeInvalid(t *testing.T) {
+	_, err := Analyze([]byte(`{"a": [1, }`))
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) || !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected a *SyntaxError, got %v", err)
+	}
+}
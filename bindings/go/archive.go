@@ -0,0 +1,210 @@
+package zmin
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path"
+)
+
+// ArchiveOptions configures MinifyArchive
+type ArchiveOptions struct {
+	// Patterns selects the members to minify by base name, using the syntax
+	// of path.Match. Defaults to "*.json".
+	Patterns []string
+
+	// SkipInvalid copies members that fail to minify through unchanged
+	// instead of failing the whole archive
+	SkipInvalid bool
+
+	// Options configures the Minifier used for the members
+	Options []Option
+}
+
+// MinifyArchive rewrites the zip, tar or gzip-compressed tar archive src to
+// dst, minifying the regular members matching opts.Patterns and copying
+// everything else through unchanged. Member names, modes, times and other
+// headers are preserved, as are the archive comment of a zip and the gzip
+// header of a .tar.gz. The format is detected from the content of src.
+//
+// dst is written atomically, as by MinifyFileAtomic. A member that fails to
+// minify is reported as a *FileError holding its name, unless
+// opts.SkipInvalid is set.
+func MinifyArchive(src, dst string, opts ArchiveOptions) error {
+	patterns := opts.Patterns
+	if len(patterns) == 0 {
+		patterns = []string{"*.json"}
+	}
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return err
+		}
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	m := NewMinifier(opts.Options...)
+	defer m.Close()
+	a := &archiveRewriter{m: m, patterns: patterns, skipInvalid: opts.SkipInvalid}
+
+	magic := make([]byte, 4)
+	n, err := io.ReadFull(in, magic)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	magic = magic[:n]
+	if _, err := in.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	return writeAtomic(dst, info, func(w io.Writer) error {
+		switch {
+		case bytes.HasPrefix(magic, []byte("PK")):
+			return a.zip(in, info.Size(), w)
+		case bytes.HasPrefix(magic, gzipCodec.magic):
+			return a.tarGzip(in, w)
+		default:
+			return a.tar(in, w)
+		}
+	})
+}
+
+// archiveRewriter copies archive members, minifying the selected ones
+type archiveRewriter struct {
+	m           *Minifier
+	patterns    []string
+	skipInvalid bool
+}
+
+// member minifies the content of the selected member name
+func (a *archiveRewriter) member(name string, data []byte) ([]byte, error) {
+	output, err := a.m.MinifyBytes(data)
+	if err != nil {
+		if a.skipInvalid {
+			return data, nil
+		}
+		return nil, &FileError{Path: name, Err: err}
+	}
+	return output, nil
+}
+
+// selected reports whether the member name is minified
+func (a *archiveRewriter) selected(name string) bool {
+	return matchAnyPath(a.patterns, path.Base(name))
+}
+
+// zip rewrites a zip archive
+func (a *archiveRewriter) zip(r io.ReaderAt, size int64, w io.Writer) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+	zw := zip.NewWriter(w)
+	if err := zw.SetComment(zr.Comment); err != nil {
+		return err
+	}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || !a.selected(f.Name) {
+			if err := zw.Copy(f); err != nil {
+				return err
+			}
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+		output, err := a.member(f.Name, data)
+		if err != nil {
+			return err
+		}
+
+		// Sizes and checksum are recomputed by the writer
+		header := f.FileHeader
+		header.CRC32 = 0
+		header.CompressedSize, header.CompressedSize64 = 0, 0
+		header.UncompressedSize, header.UncompressedSize64 = 0, 0
+		fw, err := zw.CreateHeader(&header)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(output); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// tarGzip rewrites a gzip-compressed tar archive, keeping the gzip header
+func (a *archiveRewriter) tarGzip(r io.Reader, w io.Writer) error {
+	zr, err := gzip.NewReader(bufio.NewReader(r))
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	zw := gzip.NewWriter(w)
+	zw.Header = zr.Header
+	if err := a.tar(zr, zw); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// tar rewrites a tar archive
+func (a *archiveRewriter) tar(r io.Reader, w io.Writer) error {
+	tr := tar.NewReader(r)
+	tw := tar.NewWriter(w)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg || !a.selected(header.Name) {
+			if err := tw.WriteHeader(header); err != nil {
+				return err
+			}
+			if _, err := io.Copy(tw, tr); err != nil {
+				return err
+			}
+			continue
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		output, err := a.member(header.Name, data)
+		if err != nil {
+			return err
+		}
+		header.Size = int64(len(output))
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := tw.Write(output); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
@@ -0,0 +1,168 @@
+package zmin
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMinifyArchiveZip(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "in.zip")
+	dst := filepath.Join(dir, "out.zip")
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, member := range []struct{ name, content string }{
+		{"data/", ""},
+		{"data/a.json", `{ "a" : [1, 2] }`},
+		{"data/notes.txt", `{ "kept" : true }`},
+	} {
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: member.name, Method: zip.Deflate, Modified: modified})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write([]byte(member.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.SetComment("release 1.2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(src, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := MinifyArchive(src, dst, ArchiveOptions{}); err != nil {
+		t.Fatalf("MinifyArchive failed: %v", err)
+	}
+
+	zr, err := zip.OpenReader(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	if zr.Comment != "release 1.2" {
+		t.Errorf("Expected the comment to be kept, got %q", zr.Comment)
+	}
+	expected := map[string]string{"data/": "", "data/a.json": `{"a":[1,2]}`, "data/notes.txt": `{ "kept" : true }`}
+	if len(zr.File) != len(expected) {
+		t.Fatalf("Expected %d members, got %d", len(expected), len(zr.File))
+	}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != expected[f.Name] {
+			t.Errorf("%s: expected %q, got %q", f.Name, expected[f.Name], data)
+		}
+		if !f.Modified.Equal(modified) {
+			t.Errorf("%s: expected modification time %v, got %v", f.Name, modified, f.Modified)
+		}
+	}
+}
+
+// writeTarGz writes a gzip-compressed tar archive of members to path
+func writeTarGz(t *testing.T, path string, members map[string]string) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Name = "bundle.tar"
+	tw := tar.NewWriter(zw)
+	for _, name := range []string{"a.json", "b.json", "c.txt"} {
+		content, ok := members[name]
+		if !ok {
+			continue
+		}
+		header := &tar.Header{Name: name, Mode: 0600, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMinifyArchiveTarGz(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "in.tar.gz")
+	dst := filepath.Join(dir, "out.tar.gz")
+	writeTarGz(t, src, map[string]string{"a.json": `{ "a" : 1 }`, "b.json": `{"b": }`, "c.txt": "text"})
+
+	var fileErr *FileError
+	if err := MinifyArchive(src, dst, ArchiveOptions{}); !errors.As(err, &fileErr) || fileErr.Path != "b.json" {
+		t.Errorf("Expected a FileError for b.json, got %v", err)
+	}
+	if _, err := os.Stat(dst); !os.IsNotExist(err) {
+		t.Errorf("Expected no output after a failure, got %v", err)
+	}
+
+	if err := MinifyArchive(src, dst, ArchiveOptions{SkipInvalid: true}); err != nil {
+		t.Fatalf("MinifyArchive failed: %v", err)
+	}
+	f, err := os.Open(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if zr.Name != "bundle.tar" {
+		t.Errorf("Expected the gzip header to be kept, got name %q", zr.Name)
+	}
+	tr := tar.NewReader(zr)
+	expected := map[string]string{"a.json": `{"a":1}`, "b.json": `{"b": }`, "c.txt": "text"}
+	count := 0
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != expected[header.Name] {
+			t.Errorf("%s: expected %q, got %q", header.Name, expected[header.Name], data)
+		}
+		if header.Mode != 0600 {
+			t.Errorf("%s: expected mode 0600, got %o", header.Name, header.Mode)
+		}
+		count++
+	}
+	if count != len(expected) {
+		t.Errorf("Expected %d members, got %d", len(expected), count)
+	}
+}
@@ -0,0 +1,109 @@
+package zmin
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// MinifyFileAtomic minifies a JSON file like MinifyFile, but never exposes
+// partial output: the result is written to a temporary file in the
+// destination directory, synced to disk, given the input file's permissions
+// and, where permitted, its owner, and then renamed over outputPath. Readers
+// of outputPath see either the previous contents or the complete result.
+func MinifyFileAtomic(inputPath, outputPath string, mode ProcessingMode) error {
+	info, err := os.Stat(inputPath)
+	if err != nil {
+		return err
+	}
+	input, err := os.ReadFile(inputPath)
+	if err != nil {
+		return err
+	}
+	output, err := MinifyBytes(input, mode)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(outputPath, output, info)
+}
+
+// MinifyFileInPlace replaces a JSON file with its minified form, as
+// MinifyFileAtomic does with the same path for input and output, and reports
+// the sizes before and after, as MinifyWithStats does. A file that is
+// already minified is left untouched.
+func MinifyFileInPlace(path string, mode ProcessingMode) (Stats, error) {
+	var stats Stats
+	start := time.Now()
+	info, err := os.Stat(path)
+	if err != nil {
+		return stats, err
+	}
+	input, err := os.ReadFile(path)
+	if err != nil {
+		return stats, err
+	}
+	output, err := MinifyBytes(input, mode)
+	if err != nil {
+		return stats, err
+	}
+	stats.InputBytes = int64(len(input))
+	stats.OutputBytes = int64(len(output))
+	stats.Values = 1
+	stats.Unchanged = bytes.Equal(input, output)
+	if !stats.Unchanged {
+		if err := writeFileAtomic(path, output, info); err != nil {
+			return stats, err
+		}
+	}
+	stats.finish(resolveMode(mode, len(input)), start)
+	return stats, nil
+}
+
+// writeFileAtomic replaces path with data through a synced temporary file,
+// copying the mode and owner of source
+func writeFileAtomic(path string, data []byte, source os.FileInfo) error {
+	return writeAtomic(path, source, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// writeAtomic replaces path with the output of write through a synced
+// temporary file, copying the mode and owner of source
+func writeAtomic(path string, source os.FileInfo, write func(w io.Writer) error) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if err := writeTemp(tmp, source, write); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return syncDir(dir)
+}
+
+// writeTemp fills a temporary file and brings it to its final state
+func writeTemp(tmp *os.File, source os.FileInfo, write func(w io.Writer) error) error {
+	if err := write(tmp); err != nil {
+		return err
+	}
+	if err := tmp.Chmod(source.Mode().Perm()); err != nil {
+		return err
+	}
+	if err := chownLike(tmp, source); err != nil {
+		return err
+	}
+	return tmp.Sync()
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package zmin
+
+import "os"
+
+// chownLike is a no-op where files have no Unix owner
+func chownLike(f *os.File, source os.FileInfo) error {
+	return nil
+}
+
+// syncDir is a no-op where directories cannot be synced
+func syncDir(dir string) error {
+	return nil
+}
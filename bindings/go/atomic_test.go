@@ -0,0 +1,90 @@
+package zmin
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestMinifyFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.json")
+	output := filepath.Join(dir, "output.json")
+	if err := os.WriteFile(input, []byte(`{ "a" : [1, 2, 3] }`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(output, []byte("previous"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := MinifyFileAtomic(input, output, SPORT); err != nil {
+		t.Fatalf("MinifyFileAtomic failed: %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"a":[1,2,3]}`; string(data) != expected {
+		t.Errorf("Expected %q, got %q", expected, data)
+	}
+	info, err := os.Stat(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("Expected mode 0600, got %v", info.Mode().Perm())
+	}
+
+	// A failure leaves the destination and the directory untouched
+	if err := os.WriteFile(input, []byte(`{"a": [1, 2}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := MinifyFileAtomic(input, output, SPORT); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON, got %v", err)
+	}
+	if data, _ := os.ReadFile(output); string(data) != `{"a":[1,2,3]}` {
+		t.Errorf("Expected the previous output to be kept, got %q", data)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("Expected no temporary files to remain, got %d entries", len(entries))
+	}
+}
+
+func TestMinifyFileInPlace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+	input := "{\n  \"a\" : [1, 2, 3]\n}\n"
+	expected := `{"a":[1,2,3]}`
+	if err := os.WriteFile(path, []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := MinifyFileInPlace(path, SPORT)
+	if err != nil {
+		t.Fatalf("MinifyFileInPlace failed: %v", err)
+	}
+	if stats.InputBytes != int64(len(input)) || stats.OutputBytes != int64(len(expected)) {
+		t.Errorf("Expected %d -> %d bytes, got %d -> %d", len(input), len(expected), stats.InputBytes, stats.OutputBytes)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != expected {
+		t.Errorf("Expected %q, got %q", expected, data)
+	}
+
+	// Running again finds nothing to do
+	stats, err = MinifyFileInPlace(path, SPORT)
+	if err != nil {
+		t.Fatalf("MinifyFileInPlace failed: %v", err)
+	}
+	if stats.InputBytes != stats.OutputBytes {
+		t.Errorf("Expected an unchanged size, got %d -> %d", stats.InputBytes, stats.OutputBytes)
+	}
+}
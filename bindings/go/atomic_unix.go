@@ -0,0 +1,32 @@
+//go:build unix
+
+package zmin
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// chownLike gives f the owner and group of source. Only privileged processes
+// may give files away, so a permission error is ignored.
+func chownLike(f *os.File, source os.FileInfo) error {
+	st, ok := source.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	if err := f.Chown(int(st.Uid), int(st.Gid)); err != nil && !errors.Is(err, syscall.EPERM) {
+		return err
+	}
+	return nil
+}
+
+// syncDir flushes a directory entry change, such as a rename, to disk
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -0,0 +1,163 @@
+package zmin
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// autoTurboSize is the input size from which AUTO prefers TURBO
+	autoTurboSize = 1 << 20
+	// autoMemoryFactor is the multiple of the input size the native library
+	// may hold at once outside ECO mode: the input, the output and a copy
+	autoMemoryFactor = 3
+	// unlimitedMemory is the cgroup v1 sentinel for "no limit", rounded down
+	unlimitedMemory = 1 << 62
+	// cgroupRoot is where the cgroup hierarchies are mounted
+	cgroupRoot = "/sys/fs/cgroup"
+)
+
+// availableMemory reports the memory available to the process in bytes; it
+// is a variable so that tests can simulate memory pressure
+var availableMemory = readAvailableMemory
+
+// resolveMode returns the concrete mode to use for an input of size bytes
+func resolveMode(mode ProcessingMode, size int) ProcessingMode {
+	if mode != AUTO {
+		return mode
+	}
+	if size < autoTurboSize {
+		return SPORT
+	}
+	// Fall back to the constant-memory mode when holding the document
+	// several times over would not fit
+	if available, ok := availableMemory(); ok && int64(size)*autoMemoryFactor > available {
+		return ECO
+	}
+	return TURBO
+}
+
+// readAvailableMemory returns the memory left under the process's cgroup
+// limits (v2 or v1) or, without any, the system's available memory. ok is
+// false when neither can be determined, for example outside Linux.
+func readAvailableMemory() (available int64, ok bool) {
+	if available, ok := cgroupAvailableMemory(cgroupRoot, "/proc/self/cgroup"); ok {
+		return available, true
+	}
+	return readMemAvailable()
+}
+
+// cgroupAvailableMemory returns the memory left under the tightest limit on
+// the cgroup listed in the file self and on its ancestors, in the hierarchies
+// mounted under root. Ancestors matter in Kubernetes, where the pod's limit
+// sits above the container's. ok is false when no limit applies.
+func cgroupAvailableMemory(root, self string) (available int64, ok bool) {
+	// Without a listing, or inside a cgroup namespace, the process's cgroup
+	// is the root of the hierarchy
+	unified, memory := "/", "/"
+	if data, err := os.ReadFile(self); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			fields := strings.SplitN(line, ":", 3)
+			if len(fields) != 3 {
+				continue
+			}
+			if fields[0] == "0" && fields[1] == "" {
+				unified = fields[2]
+			}
+			for _, controller := range strings.Split(fields[1], ",") {
+				if controller == "memory" {
+					memory = fields[2]
+				}
+			}
+		}
+	}
+
+	for _, hierarchy := range []struct {
+		top, path string
+		limits    []string
+		usage     string
+	}{
+		{root, unified, []string{"memory.max", "memory.high"}, "memory.current"},
+		{filepath.Join(root, "memory"), memory, []string{"memory.limit_in_bytes"}, "memory.usage_in_bytes"},
+	} {
+		dir := filepath.Join(hierarchy.top, hierarchy.path)
+		if !strings.HasPrefix(dir, hierarchy.top) {
+			dir = hierarchy.top
+		}
+		for {
+			if headroom, limited := cgroupHeadroom(dir, hierarchy.limits, hierarchy.usage); limited && (!ok || headroom < available) {
+				available, ok = headroom, true
+			}
+			if dir == hierarchy.top {
+				break
+			}
+			dir = filepath.Dir(dir)
+		}
+		if ok {
+			return available, true
+		}
+	}
+	return 0, false
+}
+
+// cgroupHeadroom returns the memory left under the lowest of the limits in
+// the cgroup directory dir. limited is false when none of them is set.
+func cgroupHeadroom(dir string, limits []string, usage string) (headroom int64, limited bool) {
+	limit := int64(unlimitedMemory)
+	for _, name := range limits {
+		if value, err := readMemoryFile(filepath.Join(dir, name)); err == nil && value < limit {
+			limit = value
+		}
+	}
+	if limit >= unlimitedMemory {
+		return 0, false
+	}
+	used, err := readMemoryFile(filepath.Join(dir, usage))
+	if err != nil {
+		return 0, false
+	}
+	if used > limit {
+		return 0, true
+	}
+	return limit - used, true
+}
+
+// readMemoryFile parses a cgroup memory file holding a byte count or "max"
+func readMemoryFile(path string) (int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	value := string(bytes.TrimSpace(data))
+	if value == "max" {
+		return unlimitedMemory, nil
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// readMemAvailable returns MemAvailable from /proc/meminfo
+func readMemAvailable() (int64, bool) {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "MemAvailable:" {
+			continue
+		}
+		kb, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		return kb << 10, true
+	}
+	return 0, false
+}
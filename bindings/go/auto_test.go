@@ -0,0 +1,131 @@
+package zmin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveMode(t *testing.T) {
+	saved := availableMemory
+	defer func() { availableMemory = saved }()
+
+	const mb = 1 << 20
+	tests := []struct {
+		mode      ProcessingMode
+		size      int
+		available int64
+		known     bool
+		expected  ProcessingMode
+	}{
+		{ECO, 100 * mb, 0, true, ECO}, // explicit modes are kept
+		{TURBO, 10, 0, true, TURBO},
+		{AUTO, 10, 0, true, SPORT},
+		{AUTO, 100 * mb, 1024 * mb, true, TURBO},
+		{AUTO, 100 * mb, 200 * mb, true, ECO},
+		{AUTO, 100 * mb, 0, false, TURBO}, // unknown memory
+	}
+	for _, tt := range tests {
+		available, known := tt.available, tt.known
+		availableMemory = func() (int64, bool) { return available, known }
+		if mode := resolveMode(tt.mode, tt.size); mode != tt.expected {
+			t.Errorf("resolveMode(%d, %d) with %d bytes available = %d, expected %d",
+				tt.mode, tt.size, tt.available, mode, tt.expected)
+		}
+	}
+}
+
+func TestCgroupAvailableMemory(t *testing.T) {
+	write := func(root string, files map[string]string) {
+		for name, content := range files {
+			path := filepath.Join(root, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(content+"\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	tests := []struct {
+		name      string
+		files     map[string]string
+		available int64
+		ok        bool
+	}{
+		{"v2 namespaced", map[string]string{
+			"self":           "0::/\n",
+			"memory.max":     "1000",
+			"memory.current": "400",
+		}, 600, true},
+		{"v2 pod limit", map[string]string{
+			"self":                             "0::/kubepods/pod1/ctr\n",
+			"memory.max":                       "max",
+			"kubepods/pod1/memory.max":         "1000",
+			"kubepods/pod1/memory.current":     "900",
+			"kubepods/pod1/ctr/memory.max":     "max",
+			"kubepods/pod1/ctr/memory.current": "50",
+		}, 100, true},
+		{"v2 memory.high", map[string]string{
+			"self":               "0::/svc\n",
+			"svc/memory.max":     "1000",
+			"svc/memory.high":    "500",
+			"svc/memory.current": "200",
+		}, 300, true},
+		{"v2 unlimited", map[string]string{
+			"self":           "0::/\n",
+			"memory.max":     "max",
+			"memory.current": "400",
+		}, 0, false},
+		{"v1", map[string]string{
+			"self":                         "4:memory:/docker/abc\n3:cpu,cpuacct:/docker/abc\n",
+			"memory/memory.limit_in_bytes": "9223372036854771712",
+			"memory/docker/abc/memory.limit_in_bytes": "2000",
+			"memory/docker/abc/memory.usage_in_bytes": "500",
+		}, 1500, true},
+		{"over the limit", map[string]string{
+			"memory.max":     "1000",
+			"memory.current": "1200",
+		}, 0, true},
+	}
+	for _, tt := range tests {
+		root := t.TempDir()
+		write(root, tt.files)
+		available, ok := cgroupAvailableMemory(root, filepath.Join(root, "self"))
+		if available != tt.available || ok != tt.ok {
+			t.Errorf("%s: got %d, %v; expected %d, %v", tt.name, available, ok, tt.available, tt.ok)
+		}
+	}
+}
+
+func TestAutoMode(t *testing.T) {
+	input := `{ "name" : "John", "age" : 30 }`
+	expected := `{"name":"John","age":30}`
+
+	output, err := MinifyWithMode(input, AUTO)
+	if err != nil {
+		t.Fatalf("MinifyWithMode failed: %v", err)
+	}
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+
+	m := NewMinifier(WithMode(AUTO))
+	defer m.Close()
+	bytesOutput, err := m.MinifyBytes([]byte(input))
+	if err != nil {
+		t.Fatalf("MinifyBytes failed: %v", err)
+	}
+	if string(bytesOutput) != expected {
+		t.Errorf("Expected %q, got %q", expected, bytesOutput)
+	}
+
+	outputs, errs := MinifyBatch([][]byte{[]byte(input)}, AUTO)
+	if errs[0] != nil {
+		t.Fatalf("MinifyBatch failed: %v", errs[0])
+	}
+	if string(outputs[0]) != expected {
+		t.Errorf("Expected %q, got %q", expected, outputs[0])
+	}
+}
@@ -0,0 +1,109 @@
+package zmin
+
+import "sync"
+
+// Backend is an engine that minifies and validates JSON. The package-level
+// functions and Minifiers use NativeBackend unless SetDefaultBackend selects
+// another, so that tests and constrained environments can swap the engine
+// without recompiling.
+type Backend interface {
+	// Minify returns the minified form of input. Invalid JSON is reported
+	// with an error matching ErrInvalidJSON.
+	Minify(input []byte, mode ProcessingMode) ([]byte, error)
+	// Validate returns nil if input is valid JSON, and an error matching
+	// ErrInvalidJSON otherwise
+	Validate(input []byte) error
+	// Version returns the version of the engine, or "" if it is unavailable
+	Version() string
+	// Capabilities describes the engine
+	Capabilities() Capabilities
+}
+
+var (
+	// NativeBackend is the backend the package was built with: the native
+	// library through cgo, dlopen or WebAssembly, or the pure-Go fallback
+	// when none of them is available. Capabilities().Backend names it.
+	NativeBackend Backend = nativeBackend{}
+	// GoBackend minifies in pure Go. It is always available and needs no
+	// native library, but it is slower and ignores the processing mode.
+	GoBackend Backend = goBackend{}
+)
+
+var (
+	backendMu sync.RWMutex
+	backend   Backend // set by SetDefaultBackend; nil for NativeBackend
+)
+
+// SetDefaultBackend makes b the backend of the package-level functions and of
+// Minifiers. A nil b restores NativeBackend. Minifier contexts and batches
+// are native features, so with another backend Minifiers and MinifyBatch
+// make one Minify call per document.
+func SetDefaultBackend(b Backend) {
+	if b == NativeBackend {
+		b = nil
+	}
+	backendMu.Lock()
+	backend = b
+	backendMu.Unlock()
+}
+
+// DefaultBackend returns the backend selected by SetDefaultBackend
+func DefaultBackend() Backend {
+	if b := customBackend(); b != nil {
+		return b
+	}
+	return NativeBackend
+}
+
+// customBackend returns the backend selected by SetDefaultBackend, or nil if
+// the package uses NativeBackend
+func customBackend() Backend {
+	backendMu.RLock()
+	defer backendMu.RUnlock()
+	return backend
+}
+
+// backendMinifyInto minifies src into dst with b, like minifyInto
+func backendMinifyInto(b Backend, dst, src []byte, mode ProcessingMode) (int, error) {
+	output, err := b.Minify(src, mode)
+	if err != nil {
+		return 0, err
+	}
+	if len(output) > len(dst) {
+		return len(output), ErrBufferTooSmall
+	}
+	return copy(dst, output), nil
+}
+
+// nativeBackend minifies with the backend compiled in
+type nativeBackend struct{}
+
+func (nativeBackend) Minify(input []byte, mode ProcessingMode) ([]byte, error) {
+	output := make([]byte, len(input))
+	n, err := minifyNative(output, input, mode)
+	if err != nil {
+		return nil, err
+	}
+	return output[:n], nil
+}
+
+func (nativeBackend) Validate(input []byte) error {
+	if err := Init(); err != nil {
+		return err
+	}
+	if nativeValidate(input) == 0 {
+		return nil
+	}
+	if err := scanDocument(input); err != nil {
+		return err
+	}
+	return ErrInvalidJSON
+}
+
+func (nativeBackend) Version() string {
+	return Version()
+}
+
+func (nativeBackend) Capabilities() Capabilities {
+	return LibraryCapabilities()
+}
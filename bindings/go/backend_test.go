@@ -0,0 +1,241 @@
+package zmin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingBackend wraps GoBackend and counts Minify calls
+type countingBackend struct {
+	goBackend
+	calls int
+}
+
+func (b *countingBackend) Minify(input []byte, mode ProcessingMode) ([]byte, error) {
+	b.calls++
+	return b.goBackend.Minify(input, mode)
+}
+
+func TestGoBackend(t *testing.T) {
+	tests := []struct{ input, want string }{
+		{" { \"a\" : [ 1 , 2.5e3 , true , null ] } ", `{"a":[1,2.5e3,true,null]}`},
+		{`{ "s": "a \" b", "t": "c \\" }`, `{"s":"a \" b","t":"c \\"}`},
+		{`[ "\\\"", "x y" ]`, `["\\\"","x y"]`},
+		{"\t\"\"\n", `""`},
+	}
+	for _, tt := range tests {
+		got, err := GoBackend.Minify([]byte(tt.input), AUTO)
+		if err != nil || string(got) != tt.want {
+			t.Errorf("Minify(%q) = %q, %v; want %q", tt.input, got, err, tt.want)
+		}
+		if native, err := MinifyBytes([]byte(tt.input), SPORT); err != nil || string(native) != tt.want {
+			t.Errorf("Expected the native backend to agree on %q, got %q (%v)", tt.input, native, err)
+		}
+	}
+
+	var syntaxErr *SyntaxError
+	if _, err := GoBackend.Minify([]byte(`{"a": }`), SPORT); !errors.As(err, &syntaxErr) {
+		t.Errorf("Expected a *SyntaxError, got %v", err)
+	}
+	if err := GoBackend.Validate([]byte(`[1,]`)); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON, got %v", err)
+	}
+	if _, err := GoBackend.Minify([]byte(`{}`), ProcessingMode(9)); !errors.Is(err, ErrInvalidMode) {
+		t.Errorf("Expected ErrInvalidMode, got %v", err)
+	}
+	if caps := GoBackend.Capabilities(); caps.Backend != "go" || !caps.Supports(TURBO) {
+		t.Errorf("Unexpected capabilities %+v", caps)
+	}
+}
+
+func TestSetDefaultBackend(t *testing.T) {
+	if DefaultBackend() != NativeBackend {
+		t.Fatal("Expected NativeBackend by default")
+	}
+	b := &countingBackend{}
+	SetDefaultBackend(b)
+	defer SetDefaultBackend(nil)
+	if DefaultBackend() != b {
+		t.Fatal("Expected the backend to be selected")
+	}
+
+	if output, err := Minify(`{ "a": 1 }`); err != nil || output != `{"a":1}` {
+		t.Errorf("Unexpected result %q (%v)", output, err)
+	}
+	m := NewMinifier(WithMode(TURBO))
+	defer m.Close()
+	if _, err := m.MinifyBytes([]byte(`[ 1 ]`)); err != nil {
+		t.Errorf("Minifier failed: %v", err)
+	}
+	_, errs := MinifyBatch([][]byte{[]byte(`[ 1 ]`), []byte(`[ 2 ]`)}, SPORT)
+	if errs[0] != nil || errs[1] != nil {
+		t.Errorf("MinifyBatch failed: %v", errs)
+	}
+	if b.calls != 4 {
+		t.Errorf("Expected 4 calls to the backend, got %d", b.calls)
+	}
+
+	dst := make([]byte, 2)
+	var tooSmall *BufferTooSmallError
+	if _, err := MinifyInto(dst, []byte(`[ 1 ]`), SPORT); !errors.As(err, &tooSmall) || tooSmall.Required != 3 {
+		t.Errorf("Expected 3 bytes required, got %v", err)
+	}
+	if Validate(`[1,]`) {
+		t.Error("Expected invalid JSON to fail validation")
+	}
+
+	SetDefaultBackend(nil)
+	if DefaultBackend() != NativeBackend {
+		t.Error("Expected nil to restore NativeBackend")
+	}
+}
+
+// TestSubprocessHelper stands in for zmin in the worker processes started by
+// TestSubprocessBackend, serving requests with GoBackend. Documents
+// containing "crash" end the process mid-call, those containing "hang" are
+// never answered, and after one containing "linger" the process ignores the
+// end of its input.
+func TestSubprocessHelper(t *testing.T) {
+	if os.Getenv("ZMIN_TEST_SUBPROCESS") != "1" {
+		t.Skip("run by TestSubprocessBackend")
+	}
+	args := os.Args[len(os.Args)-1]
+	if args == "--version" {
+		fmt.Fprintln(os.Stderr, "zmin 1.2.3")
+		os.Exit(0)
+	}
+
+	linger := false
+	for {
+		var header [6]byte
+		if _, err := io.ReadFull(os.Stdin, header[:]); err != nil {
+			if linger {
+				// select {} would be reported as a deadlock and exit
+				for {
+					time.Sleep(time.Hour)
+				}
+			}
+			os.Exit(0)
+		}
+		input := make([]byte, binary.LittleEndian.Uint32(header[2:]))
+		io.ReadFull(os.Stdin, input)
+		if bytes.Contains(input, []byte("crash")) {
+			fmt.Fprintln(os.Stderr, "Segmentation fault")
+			os.Exit(3)
+		}
+		if bytes.Contains(input, []byte("hang")) {
+			for {
+				time.Sleep(time.Hour)
+			}
+		}
+		linger = linger || bytes.Contains(input, []byte("linger"))
+
+		var output []byte
+		err := GoBackend.Validate(input)
+		if err == nil && header[0] == 'm' {
+			output, err = GoBackend.Minify(input, ProcessingMode(header[1]))
+		}
+		var reply [8]byte
+		if err != nil {
+			binary.LittleEndian.PutUint32(reply[:], uint32(0xffffffff)) // -1
+		}
+		binary.LittleEndian.PutUint32(reply[4:], uint32(len(output)))
+		os.Stdout.Write(append(reply[:], output...))
+	}
+}
+
+func TestSubprocessBackend(t *testing.T) {
+	t.Setenv("ZMIN_TEST_SUBPROCESS", "1")
+	b := NewSubprocessBackend(os.Args[0], "-test.run=^TestSubprocessHelper$", "--")
+	defer b.Close()
+
+	if output, err := b.Minify([]byte("{ \"a\": [1, 2] }\n"), TURBO); err != nil || string(output) != `{"a":[1,2]}` {
+		t.Errorf("Unexpected result %q (%v)", output, err)
+	}
+	if err := b.Validate([]byte(`[1, 2]`)); err != nil {
+		t.Errorf("Validate failed: %v", err)
+	}
+	var syntaxErr *SyntaxError
+	if err := b.Validate([]byte(`[1,]`)); !errors.As(err, &syntaxErr) {
+		t.Errorf("Expected a *SyntaxError, got %v", err)
+	}
+	if len(b.idle) != 1 {
+		t.Errorf("Expected one idle worker, got %d", len(b.idle))
+	}
+
+	// A crashing worker fails its call and is replaced
+	if _, err := b.Minify([]byte(`"crash"`), SPORT); !errors.Is(err, ErrUnknown) || !strings.Contains(err.Error(), "Segmentation fault") {
+		t.Errorf("Expected the crash to be reported, got %v", err)
+	}
+	if output, err := b.Minify([]byte(`[ true ]`), ECO); err != nil || string(output) != `[true]` {
+		t.Errorf("Unexpected result %q (%v)", output, err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := b.Minify([]byte(`{ }`), SPORT); err != nil {
+				t.Errorf("Concurrent Minify failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if caps := b.Capabilities(); caps.Version != "1.2.3" || caps.Backend != "subprocess" || !caps.Supports(ECO) {
+		t.Errorf("Unexpected capabilities %+v", caps)
+	}
+	b.Close()
+	if _, err := b.Minify([]byte(`{}`), SPORT); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+
+	missing := NewSubprocessBackend(filepath.Join(t.TempDir(), "missing"))
+	if _, err := missing.Minify([]byte(`{}`), SPORT); !errors.Is(err, ErrLibraryUnavailable) {
+		t.Errorf("Expected ErrLibraryUnavailable, got %v", err)
+	}
+	if caps := missing.Capabilities(); caps.Version != "" || len(caps.Modes) != 0 {
+		t.Errorf("Unexpected capabilities %+v", caps)
+	}
+}
+
+func TestSubprocessBackendTimeout(t *testing.T) {
+	t.Setenv("ZMIN_TEST_SUBPROCESS", "1")
+	defer func(d time.Duration) { subprocessStopTimeout = d }(subprocessStopTimeout)
+	subprocessStopTimeout = 100 * time.Millisecond
+	b := NewSubprocessBackend(os.Args[0], "-test.run=^TestSubprocessHelper$", "--")
+	defer b.Close()
+	b.SetTimeout(200 * time.Millisecond)
+
+	// A worker that does not reply is killed and replaced
+	start := time.Now()
+	if _, err := b.Minify([]byte(`"hang"`), SPORT); !errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected ErrTimeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the call to end after the timeout, took %v", elapsed)
+	}
+	if output, err := b.Minify([]byte(`[ 1 ]`), SPORT); err != nil || string(output) != `[1]` {
+		t.Errorf("Unexpected result %q (%v)", output, err)
+	}
+
+	// A worker that does not exit when stopped is killed
+	if _, err := b.Minify([]byte(`"linger"`), SPORT); err != nil {
+		t.Fatalf("Minify failed: %v", err)
+	}
+	start = time.Now()
+	b.Close()
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected Close to kill the worker, took %v", elapsed)
+	}
+}
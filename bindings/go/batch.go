@@ -0,0 +1,114 @@
+package zmin
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// MinifyBatch minifies many documents with a single call into the native
+// library, amortizing the cgo overhead that dominates for small payloads.
+// outputs[i] and errs[i] correspond to inputs[i]; a failed document has a nil
+// output and a non-nil error. The outputs share one backing array, so each is
+// capped at its own length.
+func MinifyBatch(inputs [][]byte, mode ProcessingMode) (outputs [][]byte, errs []error) {
+	outputs = make([][]byte, len(inputs))
+	errs = make([]error, len(inputs))
+	if len(inputs) == 0 {
+		return outputs, errs
+	}
+	if b := customBackend(); b != nil {
+		for i, input := range inputs {
+			outputs[i], errs[i] = b.Minify(input, mode)
+		}
+		return outputs, errs
+	}
+	if err := Init(); err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return outputs, errs
+	}
+
+	// Lay the documents out back to back; each output is written at the
+	// offset of its input
+	total, largest := 0, 0
+	for _, input := range inputs {
+		total += len(input)
+		if len(input) > largest {
+			largest = len(input)
+		}
+	}
+	// AUTO settles on one mode for the batch, sized for its largest document
+	mode = resolveMode(mode, largest)
+	src := defaultBufferPool.Get(total)
+	defer defaultBufferPool.Put(src)
+	sizes := make([]uintptr, len(inputs))
+	offset := 0
+	for i, input := range inputs {
+		offset += copy(src[offset:], input)
+		sizes[i] = uintptr(len(input))
+	}
+
+	dst := make([]byte, total)
+	outputSizes := make([]uintptr, len(inputs))
+	codes := make([]int32, len(inputs))
+	errorCode := nativeMinifyBatch(src, sizes, mode, dst, outputSizes, codes)
+	if errorCode != 0 {
+		err := getError(errorCode)
+		for i := range errs {
+			errs[i] = err
+		}
+		return outputs, errs
+	}
+
+	offset = 0
+	for i, input := range inputs {
+		if codes[i] != 0 {
+			errs[i] = locateError(input, getError(int(codes[i])))
+		} else {
+			end := offset + int(outputSizes[i])
+			outputs[i] = dst[offset:end:end]
+		}
+		offset += len(input)
+	}
+	return outputs, errs
+}
+
+// MinifyAll minifies inputs in parallel on GOMAXPROCS workers, each with its
+// own Minifier configured by opts. Results are in input order, as with
+// MinifyBatch. Once ctx is done, inputs that have not been started fail with
+// ctx.Err(); documents already being minified run to completion.
+func MinifyAll(ctx context.Context, inputs [][]byte, opts ...Option) (outputs [][]byte, errs []error) {
+	outputs = make([][]byte, len(inputs))
+	errs = make([]error, len(inputs))
+
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(inputs) {
+		workers = len(inputs)
+	}
+	var next int64
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			m := NewMinifier(opts...)
+			defer m.Close()
+			for {
+				i := int(atomic.AddInt64(&next, 1) - 1)
+				if i >= len(inputs) {
+					return
+				}
+				if err := ctx.Err(); err != nil {
+					errs[i] = err
+					continue
+				}
+				outputs[i], errs[i] = m.MinifyBytes(inputs[i])
+			}
+		}()
+	}
+	wg.Wait()
+	return outputs, errs
+}
@@ -0,0 +1,102 @@
+package zmin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestMinifyBatch(t *testing.T) {
+	inputs := [][]byte{
+		[]byte(`{ "a": 1 }`),
+		[]byte(`[1, 2, }`),
+		[]byte(` "text" `),
+		[]byte(`[ ]`),
+	}
+
+	outputs, errs := MinifyBatch(inputs, SPORT)
+	if len(outputs) != len(inputs) || len(errs) != len(inputs) {
+		t.Fatalf("Expected %d results, got %d outputs and %d errors", len(inputs), len(outputs), len(errs))
+	}
+
+	expected := []string{`{"a":1}`, "", `"text"`, `[]`}
+	for i := range inputs {
+		if i == 1 {
+			if !errors.Is(errs[i], ErrInvalidJSON) || outputs[i] != nil {
+				t.Errorf("Expected ErrInvalidJSON and no output for input %d, got %q, %v", i, outputs[i], errs[i])
+			}
+			continue
+		}
+		if errs[i] != nil {
+			t.Errorf("Input %d failed: %v", i, errs[i])
+		}
+		if string(outputs[i]) != expected[i] {
+			t.Errorf("Expected %q, got %q", expected[i], outputs[i])
+		}
+	}
+
+	// Appending to one output must not overwrite the next
+	_ = append(outputs[0], "xxxx"...)
+	if string(outputs[2]) != `"text"` {
+		t.Errorf("Outputs overlap: %q", outputs[2])
+	}
+
+	if _, errs := MinifyBatch(inputs, ProcessingMode(7)); !errors.Is(errs[0], ErrInvalidMode) {
+		t.Errorf("Expected ErrInvalidMode, got %v", errs[0])
+	}
+	if outputs, errs := MinifyBatch(nil, SPORT); len(outputs) != 0 || len(errs) != 0 {
+		t.Error("Expected empty results for no inputs")
+	}
+}
+
+func BenchmarkMinifyBatch(b *testing.B) {
+	inputs := make([][]byte, 1000)
+	for i := range inputs {
+		inputs[i] = []byte(fmt.Sprintf(`{"id": %d, "name": "item", "tags": ["a", "b"]}`, i))
+	}
+
+	b.Run("Batch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			MinifyBatch(inputs, SPORT)
+		}
+	})
+
+	b.Run("Loop", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, input := range inputs {
+				MinifyBytes(input, SPORT)
+			}
+		}
+	})
+}
+
+func TestMinifyAll(t *testing.T) {
+	inputs := make([][]byte, 100)
+	for i := range inputs {
+		inputs[i] = []byte(fmt.Sprintf(`{ "id": %d }`, i))
+	}
+	inputs[42] = []byte(`{"id": }`)
+
+	outputs, errs := MinifyAll(context.Background(), inputs, WithMode(TURBO), WithSortKeys())
+	for i := range inputs {
+		if i == 42 {
+			if !errors.Is(errs[i], ErrInvalidJSON) {
+				t.Errorf("Expected ErrInvalidJSON for input 42, got %v", errs[i])
+			}
+			continue
+		}
+		if expected := fmt.Sprintf(`{"id":%d}`, i); string(outputs[i]) != expected || errs[i] != nil {
+			t.Errorf("Expected %q, got %q (%v)", expected, outputs[i], errs[i])
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, errs = MinifyAll(ctx, inputs)
+	for i, err := range errs {
+		if err != context.Canceled {
+			t.Fatalf("Expected context.Canceled for input %d, got %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package zmin
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// ToCBOR converts a JSON document to CBOR (RFC 8949). Objects and arrays are
+// encoded with definite lengths, integers that fit 64 bits as CBOR integers
+// and other numbers as 64-bit floats, with numbers beyond float64 range as
+// ±Inf; object members keep their order.
+func ToCBOR(input []byte) ([]byte, error) {
+	root, err := parseTree(input)
+	if err != nil {
+		return nil, err
+	}
+	return appendCBOR(make([]byte, 0, len(input)), root)
+}
+
+// ToMsgPack converts a JSON document to MessagePack, using the smallest
+// encoding of each value. Integers that fit 64 bits are encoded as
+// MessagePack integers and other numbers as 64-bit floats, with numbers
+// beyond float64 range as ±Inf; object members keep their order.
+func ToMsgPack(input []byte) ([]byte, error) {
+	root, err := parseTree(input)
+	if err != nil {
+		return nil, err
+	}
+	return appendMsgPack(make([]byte, 0, len(input)), root)
+}
+
+// binaryNumber is a JSON number decoded for a binary encoding: an int64, a
+// uint64 beyond the int64 range, or a float64
+type binaryNumber struct {
+	i        int64
+	u        uint64
+	f        float64
+	unsigned bool
+	float    bool
+}
+
+// parseBinaryNumber decodes a JSON number token
+func parseBinaryNumber(raw []byte) (binaryNumber, error) {
+	if isInteger(raw) {
+		if i, err := strconv.ParseInt(string(raw), 10, 64); err == nil {
+			return binaryNumber{i: i}, nil
+		}
+		if u, err := strconv.ParseUint(string(raw), 10, 64); err == nil {
+			return binaryNumber{u: u, unsigned: true}, nil
+		}
+	}
+	// Numbers beyond float64 range parse as ±Inf, which both formats encode
+	f, err := strconv.ParseFloat(string(raw), 64)
+	if err != nil && !errors.Is(err, strconv.ErrRange) {
+		return binaryNumber{}, fmt.Errorf("%w %s", ErrInvalidNumber, raw)
+	}
+	return binaryNumber{f: f, float: true}, nil
+}
+
+// CBOR major types
+const (
+	cborUnsigned = 0 << 5
+	cborNegative = 1 << 5
+	cborText     = 3 << 5
+	cborArray    = 4 << 5
+	cborMap      = 5 << 5
+)
+
+// appendCBOR appends the CBOR encoding of n to dst
+func appendCBOR(dst []byte, n *jsonNode) ([]byte, error) {
+	var err error
+	switch n.kind {
+	case tokObjectStart:
+		dst = appendCBORHead(dst, cborMap, uint64(len(n.keys)))
+		for _, key := range n.keys {
+			dst = appendCBORHead(dst, cborText, uint64(len(key)))
+			dst = append(dst, key...)
+			if dst, err = appendCBOR(dst, n.members[key]); err != nil {
+				return nil, err
+			}
+		}
+		return dst, nil
+	case tokArrayStart:
+		dst = appendCBORHead(dst, cborArray, uint64(len(n.elems)))
+		for _, elem := range n.elems {
+			if dst, err = appendCBOR(dst, elem); err != nil {
+				return nil, err
+			}
+		}
+		return dst, nil
+	case tokString:
+		dst = appendCBORHead(dst, cborText, uint64(len(n.scalar)))
+		return append(dst, n.scalar...), nil
+	case tokNumber:
+		num, err := parseBinaryNumber(n.raw)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case num.float:
+			dst = append(dst, 0xfb)
+			return binary.BigEndian.AppendUint64(dst, math.Float64bits(num.f)), nil
+		case num.unsigned:
+			return appendCBORHead(dst, cborUnsigned, num.u), nil
+		case num.i < 0:
+			return appendCBORHead(dst, cborNegative, uint64(-1-num.i)), nil
+		default:
+			return appendCBORHead(dst, cborUnsigned, uint64(num.i)), nil
+		}
+	case tokFalse:
+		return append(dst, 0xf4), nil
+	case tokTrue:
+		return append(dst, 0xf5), nil
+	default:
+		return append(dst, 0xf6), nil
+	}
+}
+
+// appendCBORHead appends the initial byte of a data item of the given major
+// type and its argument in the shortest form
+func appendCBORHead(dst []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(dst, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(dst, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, major|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(dst, major|26), uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64(append(dst, major|27), n)
+	}
+}
+
+// appendMsgPack appends the MessagePack encoding of n to dst
+func appendMsgPack(dst []byte, n *jsonNode) ([]byte, error) {
+	var err error
+	switch n.kind {
+	case tokObjectStart:
+		dst = appendMsgPackHead(dst, 0x80, 0xde, len(n.keys))
+		for _, key := range n.keys {
+			dst = appendMsgPackString(dst, key)
+			if dst, err = appendMsgPack(dst, n.members[key]); err != nil {
+				return nil, err
+			}
+		}
+		return dst, nil
+	case tokArrayStart:
+		dst = appendMsgPackHead(dst, 0x90, 0xdc, len(n.elems))
+		for _, elem := range n.elems {
+			if dst, err = appendMsgPack(dst, elem); err != nil {
+				return nil, err
+			}
+		}
+		return dst, nil
+	case tokString:
+		return appendMsgPackString(dst, n.scalar), nil
+	case tokNumber:
+		num, err := parseBinaryNumber(n.raw)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case num.float:
+			dst = append(dst, 0xcb)
+			return binary.BigEndian.AppendUint64(dst, math.Float64bits(num.f)), nil
+		case num.unsigned:
+			return binary.BigEndian.AppendUint64(append(dst, 0xcf), num.u), nil
+		default:
+			return appendMsgPackInt(dst, num.i), nil
+		}
+	case tokFalse:
+		return append(dst, 0xc2), nil
+	case tokTrue:
+		return append(dst, 0xc3), nil
+	default:
+		return append(dst, 0xc0), nil
+	}
+}
+
+// appendMsgPackHead appends the header of a map or array of n entries, given
+// the fix format prefix and the 16-bit format byte, which the 32-bit format
+// byte follows
+func appendMsgPackHead(dst []byte, fix, format16 byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(dst, fix|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, format16), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(dst, format16+1), uint32(n))
+	}
+}
+
+// appendMsgPackString appends s as a MessagePack str
+func appendMsgPackString(dst []byte, s string) []byte {
+	switch n := len(s); {
+	case n < 32:
+		dst = append(dst, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		dst = append(dst, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		dst = binary.BigEndian.AppendUint16(append(dst, 0xda), uint16(n))
+	default:
+		dst = binary.BigEndian.AppendUint32(append(dst, 0xdb), uint32(n))
+	}
+	return append(dst, s...)
+}
+
+// appendMsgPackInt appends i in the smallest MessagePack integer format
+func appendMsgPackInt(dst []byte, i int64) []byte {
+	switch {
+	case i >= 0 && i < 128, i < 0 && i >= -32:
+		return append(dst, byte(i))
+	case i >= 0 && i <= math.MaxUint8:
+		return append(dst, 0xcc, byte(i))
+	case i >= 0 && i <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, 0xcd), uint16(i))
+	case i >= 0 && i <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(dst, 0xce), uint32(i))
+	case i >= 0:
+		return binary.BigEndian.AppendUint64(append(dst, 0xcf), uint64(i))
+	case i >= math.MinInt8:
+		return append(dst, 0xd0, byte(i))
+	case i >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(dst, 0xd1), uint16(i))
+	case i >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(dst, 0xd2), uint32(i))
+	default:
+		return binary.BigEndian.AppendUint64(append(dst, 0xd3), uint64(i))
+	}
+}
@@ -0,0 +1,81 @@
+package zmin
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestToCBOR(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`0`, "00"},
+		{`23`, "17"},
+		{`24`, "1818"},
+		{`1000000`, "1a000f4240"},
+		{`18446744073709551615`, "1bffffffffffffffff"},
+		{`-1`, "20"},
+		{`-1000`, "3903e7"},
+		{`1.5`, "fb3ff8000000000000"},
+		{`1e2`, "fb4059000000000000"},
+		{`1e400`, "fb7ff0000000000000"},
+		{`-1e400`, "fbfff0000000000000"},
+		{`"IETF"`, "6449455446"},
+		{`"ü"`, "62c3bc"},
+		{`[1, [2, 3]]`, "8201820203"},
+		{`{"a": 1, "b": [2, 3]}`, "a26161016162820203"},
+		{`[false, true, null]`, "83f4f5f6"},
+	}
+	for _, tt := range tests {
+		output, err := ToCBOR([]byte(tt.input))
+		if err != nil {
+			t.Errorf("ToCBOR(%s): %v", tt.input, err)
+			continue
+		}
+		if got := hex.EncodeToString(output); got != tt.want {
+			t.Errorf("ToCBOR(%s): expected %s, got %s", tt.input, tt.want, got)
+		}
+	}
+}
+
+func TestToMsgPack(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`0`, "00"},
+		{`127`, "7f"},
+		{`128`, "cc80"},
+		{`65536`, "ce00010000"},
+		{`18446744073709551615`, "cfffffffffffffffff"},
+		{`-32`, "e0"},
+		{`-33`, "d0df"},
+		{`-40000`, "d2ffff63c0"},
+		{`1.5`, "cb3ff8000000000000"},
+		{`1e400`, "cb7ff0000000000000"},
+		{`"abc"`, "a3616263"},
+		{`[1, [2, 3]]`, "9201920203"},
+		{`{"a": 1, "b": [2, 3]}`, "82a16101a162920203"},
+		{`[false, true, null]`, "93c2c3c0"},
+	}
+	for _, tt := range tests {
+		output, err := ToMsgPack([]byte(tt.input))
+		if err != nil {
+			t.Errorf("ToMsgPack(%s): %v", tt.input, err)
+			continue
+		}
+		if got := hex.EncodeToString(output); got != tt.want {
+			t.Errorf("ToMsgPack(%s): expected %s, got %s", tt.input, tt.want, got)
+		}
+	}
+}
+
+func TestBinaryInvalid(t *testing.T) {
+	if _, err := ToCBOR([]byte(`{"a":`)); err == nil {
+		t.Error("Expected an error for truncated JSON")
+	}
+	if _, err := ToMsgPack([]byte(`{"a":1,"a":2}`)); err == nil {
+		t.Error("Expected an error for duplicate keys")
+	}
+}
@@ -0,0 +1,188 @@
+package zmin
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"unicode/utf16"
+)
+
+// Canonicalize produces the RFC 8785 (JCS) canonical form of JSON data: object
+// keys sorted by their UTF-16 code units, numbers in their shortest ECMAScript
+// representation and strings with minimal escaping. Equal documents always
+// canonicalize to identical bytes, which makes the output suitable for hashing
+// and signing. Duplicate keys are rejected with ErrDuplicateKey.
+func Canonicalize(input interface{}) (string, error) {
+	output, err := Minify(input)
+	if err != nil {
+		return "", err
+	}
+
+	s := newScanner([]byte(output))
+	tok, err := s.next()
+	if err != nil {
+		return "", err
+	}
+	out, err := canonicalValue(make([]byte, 0, len(output)), s, tok)
+	if err != nil {
+		return "", err
+	}
+	if _, err := s.next(); err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// canonicalMember is an object member awaiting sorting
+type canonicalMember struct {
+	key   []uint16
+	value []byte
+}
+
+// canonicalValue appends the canonical form of the value that tok begins
+func canonicalValue(dst []byte, s *scanner, tok token) ([]byte, error) {
+	switch tok.kind {
+	case tokObjectStart:
+		return canonicalObject(dst, s)
+	case tokArrayStart:
+		dst = append(dst, '[')
+		for i := 0; ; i++ {
+			tok, err := s.next()
+			if err != nil {
+				return nil, err
+			}
+			if tok.kind == tokArrayEnd {
+				break
+			}
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			if dst, err = canonicalValue(dst, s, tok); err != nil {
+				return nil, err
+			}
+		}
+		return append(dst, ']'), nil
+	case tokString:
+		str, err := decodeString(tok.raw)
+		if err != nil {
+			return nil, err
+		}
+		return appendCanonicalString(dst, str), nil
+	case tokNumber:
+		return appendCanonicalNumber(dst, tok.raw)
+	default:
+		return append(dst, tok.raw...), nil
+	}
+}
+
+func canonicalObject(dst []byte, s *scanner) ([]byte, error) {
+	var members []canonicalMember
+	seen := make(map[string]bool)
+	for {
+		tok, err := s.next()
+		if err != nil {
+			return nil, err
+		}
+		if tok.kind == tokObjectEnd {
+			break
+		}
+		key, err := decodeString(tok.raw)
+		if err != nil {
+			return nil, err
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("%w %q", ErrDuplicateKey, key)
+		}
+		seen[key] = true
+
+		valueTok, err := s.next()
+		if err != nil {
+			return nil, err
+		}
+		value := appendCanonicalString(nil, key)
+		value = append(value, ':')
+		if value, err = canonicalValue(value, s, valueTok); err != nil {
+			return nil, err
+		}
+		members = append(members, canonicalMember{key: utf16.Encode([]rune(key)), value: value})
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		return lessUTF16(members[i].key, members[j].key)
+	})
+	dst = append(dst, '{')
+	for i, m := range members {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = append(dst, m.value...)
+	}
+	return append(dst, '}'), nil
+}
+
+// lessUTF16 orders strings by their UTF-16 code units
+func lessUTF16(a, b []uint16) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// appendCanonicalString appends str as a JSON string, escaping only what JSON
+// requires: quotes, backslashes and control characters
+func appendCanonicalString(dst []byte, str string) []byte {
+	const hex = "0123456789abcdef"
+	dst = append(dst, '"')
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+		switch c {
+		case '"', '\\':
+			dst = append(dst, '\\', c)
+		case '\b':
+			dst = append(dst, '\\', 'b')
+		case '\f':
+			dst = append(dst, '\\', 'f')
+		case '\n':
+			dst = append(dst, '\\', 'n')
+		case '\r':
+			dst = append(dst, '\\', 'r')
+		case '\t':
+			dst = append(dst, '\\', 't')
+		default:
+			if c < 0x20 {
+				dst = append(dst, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			} else {
+				dst = append(dst, c)
+			}
+		}
+	}
+	return append(dst, '"')
+}
+
+// appendCanonicalNumber appends a number in the format of ECMAScript's
+// Number.prototype.toString, as required by RFC 8785
+func appendCanonicalNumber(dst []byte, raw []byte) ([]byte, error) {
+	f, err := strconv.ParseFloat(string(raw), 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w %s: out of range", ErrInvalidNumber, raw)
+	}
+	if f == 0 {
+		return append(dst, '0'), nil // also covers -0
+	}
+
+	abs := math.Abs(f)
+	if abs >= 1e-6 && abs < 1e21 {
+		return strconv.AppendFloat(dst, f, 'f', -1, 64), nil
+	}
+	start := len(dst)
+	dst = strconv.AppendFloat(dst, f, 'e', -1, 64)
+	// Go pads the exponent to two digits: 1e-07 becomes 1e-7
+	if n := len(dst); n-start >= 4 && dst[n-4] == 'e' && dst[n-2] == '0' {
+		dst[n-2] = dst[n-1]
+		dst = dst[:n-1]
+	}
+	return dst, nil
+}
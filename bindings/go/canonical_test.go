@@ -0,0 +1,42 @@
+package zmin
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{"b": 2, "a": 1, "c": {"z": true, "y": null}}`, `{"a":1,"b":2,"c":{"y":null,"z":true}}`},
+		{`[1.0, -0, 1e21, 1e-7, 0.000001, 123456789012345680000, 4.50, 1E2]`, `[1,0,1e+21,1e-7,0.000001,123456789012345680000,4.5,100]`},
+		{`"A\/é\u001f\n\""`, `"A/é\u001f\n\""`},
+		// Keys sort by UTF-16 code units, so U+1F600 sorts before U+FFFD
+		{`{"�": 1, "😀": 2, "é": 3, "a": 4}`, `{"a":4,"é":3,"😀":2,"�":1}`},
+	}
+
+	for _, test := range tests {
+		output, err := Canonicalize(test.input)
+		if err != nil {
+			t.Errorf("Canonicalize(%q) failed: %v", test.input, err)
+			continue
+		}
+		if output != test.expected {
+			t.Errorf("Expected %q, got %q", test.expected, output)
+		}
+	}
+}
+
+func TestCanonicalizeErrors(t *testing.T) {
+	if _, err := Canonicalize(`{"a": 1, "a": 2}`); !errors.Is(err, ErrDuplicateKey) {
+		t.Errorf("Expected ErrDuplicateKey, got %v", err)
+	}
+	if _, err := Canonicalize(`[1e400]`); !errors.Is(err, ErrInvalidNumber) {
+		t.Errorf("Expected ErrInvalidNumber, got %v", err)
+	}
+	if _, err := Canonicalize(`{"a": }`); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON, got %v", err)
+	}
+}
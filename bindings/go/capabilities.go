@@ -0,0 +1,69 @@
+package zmin
+
+// Capabilities describes the native library in use
+type Capabilities struct {
+	Version string // library version
+
+	// Backend names the backend the package was built with: "cgo", "dlopen"
+	// or "wasm" for the native library, or "go" for the pure-Go fallback
+	// used when none of them is available, which is slower.
+	Backend string
+
+	// Modes lists the processing modes the library supports on this CPU,
+	// including AUTO, which chooses among them. It is empty if the library
+	// cannot be used, in which case Init reports why.
+	Modes []ProcessingMode
+
+	// SIMD names the instruction sets the library uses, such as "AVX2",
+	// "AVX-512" or "NEON". It is empty for scalar code and for libraries too
+	// old to report them.
+	SIMD []string
+
+	// MaxInputSize is the largest input minified in a single native call.
+	// Larger inputs are minified in Go, like MinifyLarge does.
+	MaxInputSize int64
+}
+
+// simdNames names the bits of zmin_get_simd_features
+var simdNames = [...]string{"SSE2", "SSE4.1", "AVX", "AVX2", "AVX-512", "NEON", "SVE", "SVE2"}
+
+// Supports reports whether mode is one of c.Modes
+func (c Capabilities) Supports(mode ProcessingMode) bool {
+	for _, m := range c.Modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// LibraryCapabilities reports the version, supported modes and SIMD
+// features of the native library, so that programs deployed against older
+// or differently built libraries can fail fast or adapt. It initializes the
+// library if needed.
+func LibraryCapabilities() Capabilities {
+	caps := Capabilities{Version: Version(), Backend: backendName, MaxInputSize: int64(largeInputSize)}
+	if Init() != nil {
+		return caps
+	}
+	for _, mode := range []ProcessingMode{ECO, SPORT, TURBO} {
+		if nativeModeSupported(mode) {
+			caps.Modes = append(caps.Modes, mode)
+		}
+	}
+	if len(caps.Modes) > 0 {
+		caps.Modes = append(caps.Modes, AUTO)
+	}
+
+	bits := nativeSIMDFeatures()
+	for i, name := range simdNames {
+		if bits&(1<<i) != 0 {
+			caps.SIMD = append(caps.SIMD, name)
+		}
+	}
+
+	if limit := nativeMaxInputSize(); limit < uint64(caps.MaxInputSize) {
+		caps.MaxInputSize = int64(limit)
+	}
+	return caps
+}
@@ -0,0 +1,29 @@
+package zmin
+
+import "testing"
+
+func TestLibraryCapabilities(t *testing.T) {
+	caps := LibraryCapabilities()
+	if caps.Version != Version() {
+		t.Errorf("Expected version %q, got %q", Version(), caps.Version)
+	}
+	if caps.Backend != backendName {
+		t.Errorf("Expected backend %q, got %q", backendName, caps.Backend)
+	}
+	for _, mode := range []ProcessingMode{ECO, SPORT, AUTO} {
+		if !caps.Supports(mode) {
+			t.Errorf("Expected %v to be supported, got %v", mode, caps.Modes)
+		}
+	}
+	if caps.Supports(ProcessingMode(9)) {
+		t.Error("Expected an invalid mode to be unsupported")
+	}
+	if caps.MaxInputSize <= 0 || caps.MaxInputSize > int64(largeInputSize) {
+		t.Errorf("Unexpected MaxInputSize %d", caps.MaxInputSize)
+	}
+	for _, name := range caps.SIMD {
+		if name == "" {
+			t.Errorf("Unexpected SIMD features %q", caps.SIMD)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"text/tabwriter"
+	"time"
+
+	zmin "github.com/hydepwns/zmin/go"
+)
+
+// benchResult is the measured cost of minifying a document
+type benchResult struct {
+	name    string
+	n       int           // iterations
+	elapsed time.Duration // total time of the iterations
+	allocs  uint64        // total heap allocations of the iterations
+}
+
+// bench runs the bench subcommand: every mode, and encoding/json.Compact as a
+// baseline, minifies the file for a while and their throughput is compared
+func (c *cli) bench(args []string) int {
+	flags := flag.NewFlagSet("zmin-go bench", flag.ContinueOnError)
+	flags.SetOutput(c.stderr)
+	duration := flags.Duration("time", time.Second, "time spent on each mode")
+	flags.Usage = func() {
+		fmt.Fprintln(c.stderr, "usage: zmin-go bench [-time d] file")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitUsage
+	}
+	path := flags.Arg(0)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		c.fail("", err)
+		return exitError
+	}
+
+	var buf bytes.Buffer
+	baseline, err := measure("encoding/json.Compact", *duration, func() error {
+		buf.Reset()
+		return json.Compact(&buf, data)
+	})
+	if err != nil {
+		c.fail(path, err)
+		return exitError
+	}
+	var results []benchResult
+	for _, mode := range []zmin.ProcessingMode{zmin.ECO, zmin.SPORT, zmin.TURBO, zmin.AUTO} {
+		mode := mode
+		result, err := measure(mode.String(), *duration, func() error {
+			_, err := zmin.MinifyBytes(data, mode)
+			return err
+		})
+		if err != nil {
+			c.fail(path, fmt.Errorf("%s: %w", mode, err))
+			return exitError
+		}
+		results = append(results, result)
+	}
+	results = append(results, baseline)
+
+	fmt.Fprintf(c.stdout, "%s: %d bytes\n", path, len(data))
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "mode\tMB/s\tallocs/op\tspeedup")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%.1f\t%d\t%.2fx\n", r.name, r.throughput(len(data)),
+			r.allocs/uint64(r.n), baseline.perOp().Seconds()/r.perOp().Seconds())
+	}
+	w.Flush()
+	return exitOK
+}
+
+// measure runs fn repeatedly for at least d, after a first call that must
+// succeed
+func measure(name string, d time.Duration, fn func() error) (benchResult, error) {
+	if err := fn(); err != nil {
+		return benchResult{}, err
+	}
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	r := benchResult{name: name}
+	start := time.Now()
+	for r.n == 0 || time.Since(start) < d {
+		fn()
+		r.n++
+	}
+	r.elapsed = time.Since(start)
+	runtime.ReadMemStats(&after)
+	r.allocs = after.Mallocs - before.Mallocs
+	return r, nil
+}
+
+// perOp returns the average time of an iteration
+func (r benchResult) perOp() time.Duration {
+	return r.elapsed / time.Duration(r.n)
+}
+
+// throughput returns the MB/s achieved on a document of size bytes
+func (r benchResult) throughput(size int) float64 {
+	return float64(size) * float64(r.n) / r.elapsed.Seconds() / 1e6
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBench(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+	if err := os.WriteFile(path, []byte(`{ "items": [1, 2, 3], "name": "zmin" }`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	code, stdout, stderr := zminGo("", "bench", "-time", "5ms", path)
+	if code != exitOK {
+		t.Fatalf("Expected status 0, got %d: %s", code, stderr)
+	}
+	for _, want := range []string{"MB/s", "allocs/op", "eco", "sport", "turbo", "auto", "encoding/json.Compact", "1.00x"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("Expected %q in the report, got %q", want, stdout)
+		}
+	}
+
+	if code, _, _ := zminGo("", "bench"); code != exitUsage {
+		t.Errorf("Expected status 2 without a file, got %d", code)
+	}
+	invalid := filepath.Join(t.TempDir(), "invalid.json")
+	if err := os.WriteFile(invalid, []byte(`{`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if code, _, _ := zminGo("", "bench", "-time", "1ms", invalid); code != exitError {
+		t.Errorf("Expected status 1 for invalid JSON, got %d", code)
+	}
+}
@@ -0,0 +1,47 @@
+// Command zmin-go minifies, validates and formats JSON from shell pipelines:
+//
+//	cat data.json | zmin-go > data.min.json
+//	zmin-go -m turbo -o dist 'assets/*.json'
+//	zmin-go --validate --stats config/*.json
+//
+// Without file arguments, or with "-", the document is read from standard
+// input. Arguments are glob patterns, expanded by zmin-go itself so that they
+// also work when quoted. Output goes to standard output, one document per
+// line, or to -o: the output file for a single input, or a directory
+// receiving each output under the base name of its input.
+//
+// With --watch, zmin-go keeps running and re-minifies the inputs, which may
+// be directories, whenever they change, printing the size and timing of each
+// rebuild. Files are minified in place, or into the -o directory, which then
+// mirrors the layout of watched directories:
+//
+//	zmin-go --watch -o dist src/data
+//
+// zmin-go bench runs every mode, and encoding/json.Compact as a baseline, over
+// a file and reports their throughput, allocations per run and speedup over
+// the baseline, to help pick a mode for a workload:
+//
+//	zmin-go bench -time 2s testdata/large.json
+//
+// Defaults for the mode, thread count and input size limit come from a
+// .zmin.toml file in the working directory or its ancestors and from the
+// ZMIN_MODE, ZMIN_THREADS and ZMIN_MAX_INPUT environment variables, as read
+// by zmin.LoadConfig; -m overrides them.
+//
+// The exit status is 1 if any input is invalid or cannot be processed, and 2
+// for bad usage.
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	zmin "github.com/hydepwns/zmin/go"
+)
+
+// Exit statuses
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+// cli holds the options of a zmin-go run and where it writes
+type cli struct {
+	options  []zmin.Option // from the configuration and flags
+	minifier *zmin.Minifier
+	output   string
+	validate bool
+	format   bool
+	stats    bool
+	watch    bool
+
+	stdin          io.Reader
+	stdout, stderr io.Writer
+	failed         bool
+}
+
+// run executes zmin-go with args and returns the exit status
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	c := &cli{stdin: stdin, stdout: stdout, stderr: stderr}
+	if len(args) > 0 && args[0] == "bench" {
+		return c.bench(args[1:])
+	}
+	flags := flag.NewFlagSet("zmin-go", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	modeName := flags.String("m", "", "mode: eco, sport, turbo or auto (default sport, or as configured)")
+	flags.StringVar(&c.output, "o", "", "output file, or directory if it exists or ends in /")
+	flags.BoolVar(&c.validate, "validate", false, "only check that the inputs are valid JSON")
+	flags.BoolVar(&c.format, "format", false, "indent the output instead of minifying it")
+	flags.BoolVar(&c.stats, "stats", false, "report sizes and timing on standard error")
+	flags.BoolVar(&c.watch, "watch", false, "re-minify the inputs whenever they change")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: zmin-go [flags] [file or glob ...]")
+		fmt.Fprintln(stderr, "       zmin-go bench [-time d] file")
+		flags.PrintDefaults()
+	}
+	err := flags.Parse(args)
+	if err != nil {
+		return exitUsage
+	}
+	if c.options, err = zmin.LoadConfig("."); err != nil {
+		fmt.Fprintf(stderr, "zmin-go: %v\n", err)
+		return exitUsage
+	}
+	if *modeName != "" {
+		mode, err := zmin.ParseMode(*modeName)
+		if err != nil {
+			fmt.Fprintf(stderr, "zmin-go: %v\n", err)
+			return exitUsage
+		}
+		c.options = append(c.options, zmin.WithMode(mode))
+	}
+	if c.validate && c.format {
+		fmt.Fprintln(stderr, "zmin-go: --validate and --format are exclusive")
+		return exitUsage
+	}
+	if c.watch && (c.validate || c.format || flags.NArg() == 0) {
+		fmt.Fprintln(stderr, "zmin-go: --watch needs files and cannot validate or format")
+		return exitUsage
+	}
+
+	inputs, err := expand(flags.Args())
+	if err != nil {
+		fmt.Fprintf(stderr, "zmin-go: %v\n", err)
+		return exitError
+	}
+	if c.output != "" && !c.watch && !c.validate && len(inputs) > 1 && !isDir(c.output) {
+		fmt.Fprintln(stderr, "zmin-go: -o must be a directory, existing or ending in /, for several inputs")
+		return exitUsage
+	}
+	if c.watch {
+		return c.watchFiles(ctx, inputs)
+	}
+	c.minifier = zmin.NewMinifier(c.options...)
+	defer c.minifier.Close()
+	c.files(inputs)
+	if c.failed {
+		return exitError
+	}
+	return exitOK
+}
+
+// expand returns the files matching patterns, or "-" for standard input if
+// there are none. Patterns are matched by zmin.Glob, so "**" matches any
+// number of directories; paths without wildcards are taken as they are. A
+// pattern matching nothing is an error.
+func expand(patterns []string) ([]string, error) {
+	if len(patterns) == 0 {
+		return []string{"-"}, nil
+	}
+	var files []string
+	for _, pattern := range patterns {
+		if pattern == "-" {
+			files = append(files, pattern)
+			continue
+		}
+		if !strings.ContainsAny(pattern, `*?[`) {
+			files = append(files, pattern)
+			continue
+		}
+		matches, err := zmin.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("%s: no such file", pattern)
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+// files processes every input, reporting failures without stopping
+func (c *cli) files(inputs []string) {
+	outputDir := ""
+	if c.output != "" && isDir(c.output) {
+		outputDir = c.output
+		if err := os.MkdirAll(outputDir, 0755); err != nil {
+			c.fail("", err)
+			return
+		}
+	}
+	for _, input := range inputs {
+		target := c.output
+		if outputDir != "" {
+			if input == "-" {
+				c.fail("", fmt.Errorf("%s: standard input needs an output file", c.output))
+				continue
+			}
+			target = filepath.Join(outputDir, filepath.Base(input))
+		}
+		c.file(input, target)
+	}
+}
+
+// isDir reports whether the -o argument names a directory: one that exists,
+// or a path ending in a separator
+func isDir(path string) bool {
+	if strings.HasSuffix(path, "/") || strings.HasSuffix(path, string(filepath.Separator)) {
+		return true
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// file processes input, writing the result to target, or to standard output
+// followed by a newline if target is empty
+func (c *cli) file(input, target string) {
+	var data []byte
+	var err error
+	if input == "-" {
+		data, err = io.ReadAll(c.stdin)
+	} else {
+		data, err = os.ReadFile(input)
+	}
+	if err != nil {
+		c.fail(input, err)
+		return
+	}
+
+	start := time.Now()
+	var output []byte
+	switch {
+	case c.validate:
+		err = zmin.ValidateWithError(data)
+	case c.format:
+		var pretty string
+		pretty, err = zmin.Format(data, zmin.FormatOptions{TrailingNewline: true})
+		output = []byte(pretty)
+	default:
+		output, err = c.minifier.MinifyBytes(data)
+		if target == "" {
+			output = append(output, '\n')
+		}
+	}
+	elapsed := time.Since(start)
+	if err != nil {
+		c.fail(input, err)
+		return
+	}
+	if c.stats {
+		c.report(input, len(data), len(output), elapsed)
+	}
+	if c.validate {
+		return
+	}
+
+	if target == "" {
+		_, err = c.stdout.Write(output)
+	} else {
+		err = os.WriteFile(target, output, 0644)
+	}
+	if err != nil {
+		c.fail(input, err)
+	}
+}
+
+// report prints the sizes and timing of an input on standard error
+func (c *cli) report(input string, in, out int, elapsed time.Duration) {
+	if input == "-" {
+		input = "<stdin>"
+	}
+	if c.validate {
+		fmt.Fprintf(c.stderr, "%s: valid, %d bytes in %v\n", input, in, elapsed)
+		return
+	}
+	ratio := 100.0
+	if in > 0 {
+		ratio = float64(out) * 100 / float64(in)
+	}
+	fmt.Fprintf(c.stderr, "%s: %d -> %d bytes (%.1f%%) in %v\n", input, in, out, ratio, elapsed)
+}
+
+// fail reports an error for input
+func (c *cli) fail(input string, err error) {
+	c.failed = true
+	if input == "" || input == "-" {
+		fmt.Fprintf(c.stderr, "zmin-go: %v\n", err)
+		return
+	}
+	fmt.Fprintf(c.stderr, "zmin-go: %s: %v\n", input, err)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// zminGo runs zmin-go with args and stdin and returns its exit status and
+// output
+func zminGo(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRunStdin(t *testing.T) {
+	code, stdout, _ := zminGo(`{ "a": [1, 2] }`, "-m", "turbo")
+	if code != exitOK || stdout != "{\"a\":[1,2]}\n" {
+		t.Errorf("Expected status 0 and minified output, got %d, %q", code, stdout)
+	}
+	code, stdout, _ = zminGo(`{"a":1}`, "--format")
+	if code != exitOK || stdout != "{\n  \"a\": 1\n}\n" {
+		t.Errorf("Expected status 0 and formatted output, got %d, %q", code, stdout)
+	}
+	code, _, stderr := zminGo(`{"a":`, "--validate")
+	if code != exitError || !strings.Contains(stderr, "invalid JSON") {
+		t.Errorf("Expected status 1 and an error, got %d, %q", code, stderr)
+	}
+}
+
+func TestRunUsage(t *testing.T) {
+	if code, _, _ := zminGo("", "-m", "fast"); code != exitUsage {
+		t.Errorf("Expected status 2 for an unknown mode, got %d", code)
+	}
+	if code, _, _ := zminGo("", "--validate", "--format"); code != exitUsage {
+		t.Errorf("Expected status 2 for exclusive flags, got %d", code)
+	}
+	if code, _, _ := zminGo("", "-bogus"); code != exitUsage {
+		t.Errorf("Expected status 2 for an unknown flag, got %d", code)
+	}
+}
+
+func TestRunFiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"a.json": `{ "a": 1 }`, "b.json": `[ 2 ]`, "c.txt": `x`} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	code, stdout, _ := zminGo("", filepath.Join(dir, "*.json"))
+	if code != exitOK || stdout != "{\"a\":1}\n[2]\n" {
+		t.Errorf("Expected both documents, got %d, %q", code, stdout)
+	}
+
+	out := filepath.Join(dir, "out")
+	if code, _, _ := zminGo("", "-o", out, filepath.Join(dir, "*.json")); code != exitUsage {
+		t.Errorf("Expected status 2 for several inputs and an output file, got %d", code)
+	}
+	code, _, stderr := zminGo("", "-o", out+string(filepath.Separator), "--stats", filepath.Join(dir, "*.json"))
+	if code != exitOK {
+		t.Fatalf("Expected status 0, got %d: %s", code, stderr)
+	}
+	if data, err := os.ReadFile(filepath.Join(out, "b.json")); err != nil || string(data) != "[2]" {
+		t.Errorf("Expected %q, got %q, %v", "[2]", data, err)
+	}
+	if !strings.Contains(stderr, "a.json: 10 -> 7 bytes") {
+		t.Errorf("Expected stats for a.json, got %q", stderr)
+	}
+
+	single := filepath.Join(dir, "single.json")
+	if code, _, _ := zminGo("", "-o", single, filepath.Join(dir, "a.json")); code != exitOK {
+		t.Errorf("Expected status 0, got %d", code)
+	}
+	if data, err := os.ReadFile(single); err != nil || string(data) != `{"a":1}` {
+		t.Errorf("Expected %q, got %q, %v", `{"a":1}`, data, err)
+	}
+
+	// An existing directory receives a single input too
+	if err := os.Remove(filepath.Join(out, "a.json")); err != nil {
+		t.Fatal(err)
+	}
+	if code, _, _ := zminGo("", "-o", out, filepath.Join(dir, "a.json")); code != exitOK {
+		t.Errorf("Expected status 0, got %d", code)
+	}
+	if data, err := os.ReadFile(filepath.Join(out, "a.json")); err != nil || string(data) != `{"a":1}` {
+		t.Errorf("Expected %q, got %q, %v", `{"a":1}`, data, err)
+	}
+
+	if code, _, _ := zminGo("", filepath.Join(dir, "*.yaml")); code != exitError {
+		t.Errorf("Expected status 1 for a pattern matching nothing, got %d", code)
+	}
+}
+
+func TestRunRecursiveGlob(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "sub", "deep"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{"a.json": `[ 1 ]`, "sub/deep/b.json": `[ 2 ]`} {
+		if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	code, stdout, _ := zminGo("", filepath.ToSlash(dir)+"/**/*.json")
+	if code != exitOK || stdout != "[1]\n[2]\n" {
+		t.Errorf("Expected both documents, got %d, %q", code, stdout)
+	}
+}
+
+func TestRunConfig(t *testing.T) {
+	t.Setenv("ZMIN_MODE", "turbo")
+	t.Setenv("ZMIN_MAX_INPUT", "5")
+	if code, _, stderr := zminGo(`[1, 2, 3]`); code != exitError || !strings.Contains(stderr, "input too large") {
+		t.Errorf("Expected status 1 and input too large, got %d, %q", code, stderr)
+	}
+	t.Setenv("ZMIN_MODE", "fast")
+	if code, _, stderr := zminGo(`[1]`); code != exitUsage || !strings.Contains(stderr, "ZMIN_MODE") {
+		t.Errorf("Expected status 2 naming ZMIN_MODE, got %d, %q", code, stderr)
+	}
+	if code, stdout, _ := zminGo(`[ 1 ]`, "-m", "eco"); code != exitUsage {
+		t.Errorf("Expected the invalid environment to fail even with -m, got %d, %q", code, stdout)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	zmin "github.com/hydepwns/zmin/go"
+)
+
+// watchFiles re-minifies inputs as they change until ctx is done
+func (c *cli) watchFiles(ctx context.Context, inputs []string) int {
+	for _, input := range inputs {
+		if input == "-" {
+			fmt.Fprintln(c.stderr, "zmin-go: --watch cannot read standard input")
+			return exitUsage
+		}
+	}
+	w, err := zmin.NewWatcher(zmin.WatchOptions{
+		OutputDir: c.output,
+		Options:   c.options,
+	}, inputs...)
+	if err != nil {
+		c.fail("", err)
+		return exitError
+	}
+	defer w.Close()
+
+	fmt.Fprintf(c.stderr, "zmin-go: watching %d paths\n", len(inputs))
+	for {
+		select {
+		case <-ctx.Done():
+			return exitOK
+		case event := <-w.Events:
+			c.rebuilt(event)
+		}
+	}
+}
+
+// rebuilt reports a re-minified file
+func (c *cli) rebuilt(event zmin.WatchEvent) {
+	if event.Err != nil {
+		c.fail(event.Path, event.Err)
+		return
+	}
+	name := event.Path
+	if event.Output != event.Path {
+		name += " -> " + event.Output
+	}
+	fmt.Fprintf(c.stderr, "%s: %d -> %d bytes (%+d) in %v\n", name,
+		event.InputBytes, event.OutputBytes, event.OutputBytes-event.InputBytes, event.Duration.Round(time.Microsecond))
+}
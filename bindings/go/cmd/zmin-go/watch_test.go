@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestRunWatch(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	out := filepath.Join(dir, "out")
+	if err := os.MkdirAll(src, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var stderr syncBuffer
+	done := make(chan int, 1)
+	go func() {
+		done <- run(ctx, []string{"--watch", "-o", out, src}, strings.NewReader(""), &bytes.Buffer{}, &stderr)
+	}()
+
+	// Wait for the watcher to start before changing anything
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(stderr.String(), "watching") && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := os.WriteFile(filepath.Join(src, "a.json"), []byte(`{ "a": 1 }`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for !strings.Contains(stderr.String(), "bytes") && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	if code := <-done; code != exitOK {
+		t.Errorf("Expected status 0, got %d", code)
+	}
+
+	if !strings.Contains(stderr.String(), ": 10 -> 7 bytes (-3) in ") {
+		t.Errorf("Expected a rebuild report, got %q", stderr.String())
+	}
+	if data, err := os.ReadFile(filepath.Join(out, "a.json")); err != nil || string(data) != `{"a":1}` {
+		t.Errorf("Expected %q, got %q, %v", `{"a":1}`, data, err)
+	}
+}
+
+func TestRunWatchUsage(t *testing.T) {
+	if code, _, _ := zminGo("", "--watch"); code != exitUsage {
+		t.Errorf("Expected status 2 without files, got %d", code)
+	}
+	if code, _, _ := zminGo("", "--watch", "--format", "x.json"); code != exitUsage {
+		t.Errorf("Expected status 2 with --format, got %d", code)
+	}
+}
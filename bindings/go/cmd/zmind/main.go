@@ -0,0 +1,106 @@
+// Command zmind serves zmin over HTTP, so that services in other languages
+// can minify, validate and format JSON through localhost instead of shelling
+// out:
+//
+//	zmind -addr 127.0.0.1:7070
+//	curl --data-binary @data.json 'localhost:7070/minify?mode=turbo'
+//
+// Every endpoint takes the document as the POST body:
+//
+//	POST /minify    minified document
+//	POST /validate  {"valid":true} or {"valid":false,"error":"..."}
+//	POST /format    indented document; ?indent=N spaces, ?tabs=true
+//
+// The mode is selected per request with the mode query parameter or the
+// X-Zmin-Mode header, and defaults to -mode.
+//
+// For sidecar use, -socket also serves the length-prefixed protocol of the
+// zminclient package on a Unix domain socket; -addr "" disables HTTP:
+//
+//	zmind -addr '' -socket /run/zmind.sock
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	zmin "github.com/hydepwns/zmin/go"
+	"github.com/hydepwns/zmin/go/zminclient"
+)
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:7070", "address to listen on for HTTP, empty to disable")
+	socket := flag.String("socket", "", "Unix domain socket to serve the zminclient protocol on")
+	modeName := flag.String("mode", "sport", "default mode: eco, sport, turbo or auto")
+	maxBody := flag.Int("max-body", 64<<20, "largest accepted request body, in bytes")
+	flag.Parse()
+
+	mode, err := zmin.ParseMode(*modeName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *addr == "" && *socket == "" {
+		log.Fatal("nothing to serve: set -addr or -socket")
+	}
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           newServer(mode, *maxBody),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *socket != "" {
+		l, err := listenUnix(*socket)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer l.Close()
+		go func() {
+			<-ctx.Done()
+			l.Close()
+		}()
+		server := &zminclient.Server{Mode: mode, MaxSize: *maxBody}
+		go func() {
+			if err := server.Serve(l); err != nil {
+				log.Fatal(err)
+			}
+		}()
+		log.Printf("zmind %s listening on %s", zmin.Version(), *socket)
+	}
+	if *addr == "" {
+		<-ctx.Done()
+		return
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+	log.Printf("zmind %s listening on %s", zmin.Version(), *addr)
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
+
+// listenUnix listens on the Unix domain socket path, replacing a socket left
+// behind by an earlier run
+func listenUnix(path string) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	return net.Listen("unix", path)
+}
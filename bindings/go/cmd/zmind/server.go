@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	zmin "github.com/hydepwns/zmin/go"
+)
+
+// server handles the zmind endpoints
+type server struct {
+	mode      zmin.ProcessingMode
+	maxBody   int
+	minifiers map[zmin.ProcessingMode]*zmin.Minifier
+}
+
+// newServer returns the zmind handler, minifying with mode unless a request
+// selects another and rejecting bodies larger than maxBody bytes
+func newServer(mode zmin.ProcessingMode, maxBody int) http.Handler {
+	s := &server{mode: mode, maxBody: maxBody, minifiers: make(map[zmin.ProcessingMode]*zmin.Minifier)}
+	for _, m := range []zmin.ProcessingMode{zmin.ECO, zmin.SPORT, zmin.TURBO, zmin.AUTO} {
+		s.minifiers[m] = zmin.NewMinifier(zmin.WithMode(m))
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/minify", s.post(s.minify))
+	mux.HandleFunc("/validate", s.post(s.validate))
+	mux.HandleFunc("/format", s.post(s.format))
+	return mux
+}
+
+// post adapts an endpoint taking the request body, rejecting other methods
+// and oversized bodies
+func (s *server) post(handle func(w http.ResponseWriter, r *http.Request, body []byte)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, int64(s.maxBody)))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, zmin.ErrInputTooLarge.Error(), http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		handle(w, r, body)
+	}
+}
+
+// minifier returns the Minifier for the mode the request selects
+func (s *server) minifier(r *http.Request) (*zmin.Minifier, error) {
+	name := r.URL.Query().Get("mode")
+	if name == "" {
+		name = r.Header.Get("X-Zmin-Mode")
+	}
+	if name == "" {
+		return s.minifiers[s.mode], nil
+	}
+	mode, err := zmin.ParseMode(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.minifiers[mode], nil
+}
+
+func (s *server) minify(w http.ResponseWriter, r *http.Request, body []byte) {
+	m, err := s.minifier(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	output, err := m.MinifyBytes(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	writeJSON(w, output)
+}
+
+func (s *server) validate(w http.ResponseWriter, r *http.Request, body []byte) {
+	result := struct {
+		Valid bool   `json:"valid"`
+		Error string `json:"error,omitempty"`
+	}{Valid: true}
+	if err := zmin.ValidateWithError(body); err != nil {
+		result.Valid, result.Error = false, err.Error()
+	}
+	output, _ := json.Marshal(result)
+	writeJSON(w, output)
+}
+
+func (s *server) format(w http.ResponseWriter, r *http.Request, body []byte) {
+	query := r.URL.Query()
+	var opts zmin.FormatOptions
+	if indent := query.Get("indent"); indent != "" {
+		n, err := strconv.Atoi(indent)
+		if err != nil || n < 0 {
+			http.Error(w, "invalid indent "+strconv.Quote(indent), http.StatusBadRequest)
+			return
+		}
+		opts.IndentWidth = n
+	}
+	opts.UseTabs, _ = strconv.ParseBool(query.Get("tabs"))
+	output, err := zmin.Format(body, opts)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	writeJSON(w, []byte(output))
+}
+
+// writeJSON sends a JSON response body
+func writeJSON(w http.ResponseWriter, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.Write(body)
+}
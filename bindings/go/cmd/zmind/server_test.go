@@ -0,0 +1,56 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	zmin "github.com/hydepwns/zmin/go"
+)
+
+func request(h http.Handler, method, target, body string, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServer(t *testing.T) {
+	h := newServer(zmin.SPORT, 1024)
+	tests := []struct {
+		method, target, body string
+		header               []string
+		code                 int
+		want                 string
+	}{
+		{"POST", "/minify", `{ "a": [1, 2] }`, nil, 200, `{"a":[1,2]}`},
+		{"POST", "/minify?mode=turbo", `[ 1 ]`, nil, 200, `[1]`},
+		{"POST", "/minify", `[ 1 ]`, []string{"X-Zmin-Mode", "eco"}, 200, `[1]`},
+		{"POST", "/minify?mode=fast", `[ 1 ]`, nil, 400, ""},
+		{"POST", "/minify", `{ "a": `, nil, 422, ""},
+		{"POST", "/minify", strings.Repeat(" ", 2000) + "1", nil, 413, ""},
+		{"GET", "/minify", ``, nil, 405, ""},
+		{"POST", "/validate", `[1]`, nil, 200, `{"valid":true}`},
+		{"POST", "/format?indent=4", `{"a":1}`, nil, 200, "{\n    \"a\": 1\n}"},
+		{"POST", "/format?indent=x", `{"a":1}`, nil, 400, ""},
+	}
+	for _, tt := range tests {
+		rec := request(h, tt.method, tt.target, tt.body, tt.header...)
+		if rec.Code != tt.code {
+			t.Errorf("%s %s: expected status %d, got %d", tt.method, tt.target, tt.code, rec.Code)
+			continue
+		}
+		if tt.want != "" && rec.Body.String() != tt.want {
+			t.Errorf("%s %s: expected %q, got %q", tt.method, tt.target, tt.want, rec.Body.String())
+		}
+	}
+
+	rec := request(h, "POST", "/validate", `[1,]`)
+	if !strings.HasPrefix(rec.Body.String(), `{"valid":false,"error":`) {
+		t.Errorf("Expected an invalid result, got %q", rec.Body.String())
+	}
+}
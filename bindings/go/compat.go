@@ -0,0 +1,95 @@
+package zmin
+
+import (
+	"bytes"
+)
+
+// Compact appends to dst the JSON-encoded src with insignificant space
+// characters elided. It is a drop-in replacement for json.Compact backed by
+// the native minifier. On error dst is left unchanged.
+func Compact(dst *bytes.Buffer, src []byte) error {
+	output, err := MinifyBytes(src, SPORT)
+	if err != nil {
+		return err
+	}
+	dst.Write(output)
+	return nil
+}
+
+// Indent appends to dst an indented form of the JSON-encoded src, with the
+// same output as json.Indent: each element of an object or array begins on a
+// new line starting with prefix followed by one or more copies of indent
+// according to the nesting, leading space in src is dropped and trailing
+// space is preserved. On error dst is left unchanged.
+func Indent(dst *bytes.Buffer, src []byte, prefix, indent string) error {
+	output, err := appendIndent(nil, src, prefix, indent)
+	if err != nil {
+		return err
+	}
+	dst.Write(output)
+	return nil
+}
+
+// appendIndent appends the indented form of src to dst
+func appendIndent(dst, src []byte, prefix, indent string) ([]byte, error) {
+	var (
+		depth      int
+		needIndent bool // a container was just opened
+		afterKey   bool // the next value follows a key on the same line
+		end        int  // end of the last token
+	)
+	newline := func(depth int) {
+		dst = append(dst, '\n')
+		dst = append(dst, prefix...)
+		for i := 0; i < depth; i++ {
+			dst = append(dst, indent...)
+		}
+	}
+
+	s := newScanner(src)
+	for {
+		tok, err := s.next()
+		if err != nil {
+			return nil, err
+		}
+		if tok.kind == tokEOF {
+			break
+		}
+		end = tok.offset + len(tok.raw)
+
+		switch tok.kind {
+		case tokObjectEnd, tokArrayEnd:
+			depth--
+			if needIndent {
+				// Empty containers stay on one line
+				needIndent = false
+			} else {
+				newline(depth)
+			}
+			dst = append(dst, tok.raw...)
+			continue
+		}
+
+		switch {
+		case needIndent:
+			newline(depth)
+			needIndent = false
+		case afterKey:
+			afterKey = false
+		case depth > 0:
+			dst = append(dst, ',')
+			newline(depth)
+		}
+
+		dst = append(dst, tok.raw...)
+		switch tok.kind {
+		case tokKey:
+			dst = append(dst, ':', ' ')
+			afterKey = true
+		case tokObjectStart, tokArrayStart:
+			depth++
+			needIndent = true
+		}
+	}
+	return append(dst, src[end:]...), nil
+}
@@ -0,0 +1,64 @@
+package zmin
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+var compatInputs = []string{
+	`{"a": 1, "b": [true, false, null], "c": {"d": "e"}}`,
+	` [ ] `,
+	`{}`,
+	"\n\t{\"nested\": [[], {}, [1, [2, {\"x\": []}]]]}\n",
+	`"just a string"`,
+	`-1.5e10`,
+}
+
+func TestCompact(t *testing.T) {
+	for _, input := range compatInputs {
+		var expected, got bytes.Buffer
+		if err := json.Compact(&expected, []byte(input)); err != nil {
+			t.Fatalf("json.Compact failed: %v", err)
+		}
+		if err := Compact(&got, []byte(input)); err != nil {
+			t.Fatalf("Compact(%q) failed: %v", input, err)
+		}
+		if got.String() != expected.String() {
+			t.Errorf("Compact(%q): expected %q, got %q", input, expected.String(), got.String())
+		}
+	}
+
+	dst := bytes.NewBufferString("keep")
+	if err := Compact(dst, []byte(`{"a":}`)); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+	if dst.String() != "keep" {
+		t.Errorf("Expected dst to be unchanged on error, got %q", dst.String())
+	}
+}
+
+func TestIndent(t *testing.T) {
+	for _, input := range compatInputs {
+		for _, style := range [][2]string{{"", "  "}, {">", "\t"}} {
+			var expected, got bytes.Buffer
+			if err := json.Indent(&expected, []byte(input), style[0], style[1]); err != nil {
+				t.Fatalf("json.Indent failed: %v", err)
+			}
+			if err := Indent(&got, []byte(input), style[0], style[1]); err != nil {
+				t.Fatalf("Indent(%q) failed: %v", input, err)
+			}
+			if got.String() != expected.String() {
+				t.Errorf("Indent(%q, %q, %q): expected %q, got %q", input, style[0], style[1], expected.String(), got.String())
+			}
+		}
+	}
+
+	dst := bytes.NewBufferString("keep")
+	if err := Indent(dst, []byte(`[1, 2`), "", "  "); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+	if dst.String() != "keep" {
+		t.Errorf("Expected dst to be unchanged on error, got %q", dst.String())
+	}
+}
@@ -0,0 +1,164 @@
+package zmin
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// ErrZstdUnsupported is returned for zstd input or output when the package
+// was built without the zstd build tag
+var ErrZstdUnsupported = errors.New("zstd support not built in (build with -tags zstd)")
+
+// codec is a compression format recognized in input files and streams
+type codec struct {
+	name      string
+	magic     []byte // bytes every stream starts with
+	ext       string // file extension selecting the format for output
+	newReader func(r io.Reader) (io.ReadCloser, error)
+	newWriter func(w io.Writer, level int) (io.WriteCloser, error)
+}
+
+var gzipCodec = &codec{
+	name:  "gzip",
+	magic: []byte{0x1f, 0x8b},
+	ext:   ".gz",
+	newReader: func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	},
+	newWriter: func(w io.Writer, level int) (io.WriteCloser, error) {
+		if level == 0 {
+			level = gzip.DefaultCompression
+		}
+		return gzip.NewWriterLevel(w, level)
+	},
+}
+
+// zstdCodec has no reader or writer unless built with the zstd tag
+var zstdCodec = &codec{
+	name:  "zstd",
+	magic: []byte{0x28, 0xb5, 0x2f, 0xfd},
+	ext:   ".zst",
+}
+
+// codecs lists the recognized compression formats
+var codecs = []*codec{gzipCodec, zstdCodec}
+
+// compressOptions configures the compression of output files
+type compressOptions struct {
+	codec *codec
+	level int // codec-specific, zero for the default
+}
+
+// WithGzipOutput gzips the files written by MinifyFile at level, one of the
+// compress/gzip levels such as gzip.BestCompression. Output paths ending in
+// ".gz" are gzipped at the default level even without this option.
+func WithGzipOutput(level int) Option {
+	return func(m *Minifier) {
+		m.compress = compressOptions{codec: gzipCodec, level: level}
+	}
+}
+
+// WithZstdOutput compresses the files written by MinifyFile with zstd at
+// level, from 1 (fastest) to 22 (smallest), or 0 for the default. Output
+// paths ending in ".zst" are compressed even without this option. zstd
+// requires building with -tags zstd; otherwise writing fails with
+// ErrZstdUnsupported.
+func WithZstdOutput(level int) Option {
+	return func(m *Minifier) {
+		m.compress = compressOptions{codec: zstdCodec, level: level}
+	}
+}
+
+// detectCodec returns the codec whose magic bytes start data, if any
+func detectCodec(data []byte) *codec {
+	for _, c := range codecs {
+		if bytes.HasPrefix(data, c.magic) {
+			return c
+		}
+	}
+	return nil
+}
+
+// openCodec returns a decompressing reader for c
+func openCodec(c *codec, r io.Reader) (io.ReadCloser, error) {
+	if c.newReader == nil {
+		return nil, ErrZstdUnsupported
+	}
+	return c.newReader(r)
+}
+
+// decompressReader returns a reader for the content of r, decompressed if r
+// holds a gzip or zstd stream. The reader must be closed.
+func decompressReader(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(4)
+	if c := detectCodec(magic); c != nil {
+		return openCodec(c, br)
+	}
+	return io.NopCloser(br), nil
+}
+
+// decompressBytes returns data, decompressed if it is gzip or zstd. A
+// positive limit rejects decompressed output larger than limit bytes with
+// ErrInputTooLarge, guarding against decompression bombs.
+func decompressBytes(data []byte, limit int) ([]byte, error) {
+	c := detectCodec(data)
+	if c == nil {
+		return data, nil
+	}
+	zr, err := openCodec(c, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	var r io.Reader = zr
+	if limit > 0 {
+		r = io.LimitReader(zr, int64(limit)+1)
+	}
+	out, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(out) > limit {
+		return nil, ErrInputTooLarge
+	}
+	return out, nil
+}
+
+// writeOutputFile writes output to path, compressed as configured by opts or
+// implied by the extension of path
+func writeOutputFile(path string, output []byte, opts compressOptions) error {
+	if opts.codec == nil {
+		for _, c := range codecs {
+			if strings.HasSuffix(path, c.ext) {
+				opts.codec = c
+				break
+			}
+		}
+	}
+	if opts.codec == nil {
+		return os.WriteFile(path, output, 0644)
+	}
+	if opts.codec.newWriter == nil {
+		return ErrZstdUnsupported
+	}
+
+	var buf bytes.Buffer
+	zw, err := opts.codec.newWriter(&buf, opts.level)
+	if err != nil {
+		return err
+	}
+	if _, err := zw.Write(output); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
@@ -0,0 +1,121 @@
+package zmin
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// gzipBytes compresses data
+func gzipBytes(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// gunzipFile returns the decompressed content of a gzip file
+func gunzipFile(t *testing.T, path string) string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("Expected gzip output: %v", err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestGzipReader(t *testing.T) {
+	input := `{ "a" : [1, 2] }`
+	expected := `{"a":[1,2]}`
+
+	for _, r := range []io.Reader{strings.NewReader(input), bytes.NewReader(gzipBytes(t, input))} {
+		output, err := MinifyReader(r, SPORT)
+		if err != nil {
+			t.Fatalf("MinifyReader failed: %v", err)
+		}
+		if output != expected {
+			t.Errorf("Expected %q, got %q", expected, output)
+		}
+	}
+
+	// The size limit applies to the decompressed input
+	m := NewMinifier(WithMaxInputSize(8))
+	if _, err := m.MinifyReader(bytes.NewReader(gzipBytes(t, input))); err != ErrInputTooLarge {
+		t.Errorf("Expected ErrInputTooLarge, got %v", err)
+	}
+}
+
+func TestGzipFile(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "data.json.gz")
+	expected := `{"a":[1,2]}`
+	if err := os.WriteFile(input, gzipBytes(t, `{ "a" : [1, 2] }`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(dir, "data.min.json.gz")
+	if err := MinifyFile(input, output, SPORT); err != nil {
+		t.Fatalf("MinifyFile failed: %v", err)
+	}
+	if got := gunzipFile(t, output); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	plain := filepath.Join(dir, "data.min.json")
+	if err := MinifyFile(input, plain, SPORT); err != nil {
+		t.Fatalf("MinifyFile failed: %v", err)
+	}
+	if got := readFile(t, dir, "data.min.json"); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	m := NewMinifier(WithGzipOutput(gzip.BestCompression))
+	defer m.Close()
+	if err := m.MinifyFile(plain, plain+".out"); err != nil {
+		t.Fatalf("MinifyFile failed: %v", err)
+	}
+	if got := gunzipFile(t, plain+".out"); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestZstdWithoutTag(t *testing.T) {
+	if zstdCodec.newReader != nil {
+		t.Skip("built with zstd support")
+	}
+	dir := t.TempDir()
+	input := filepath.Join(dir, "data.json.zst")
+	if err := os.WriteFile(input, []byte{0x28, 0xb5, 0x2f, 0xfd, 0, 0}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := MinifyFile(input, filepath.Join(dir, "out.json"), SPORT); err != ErrZstdUnsupported {
+		t.Errorf("Expected ErrZstdUnsupported, got %v", err)
+	}
+
+	plain := filepath.Join(dir, "data.json")
+	if err := os.WriteFile(plain, []byte(`{"a":1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := MinifyFile(plain, filepath.Join(dir, "out.json.zst"), SPORT); err != ErrZstdUnsupported {
+		t.Errorf("Expected ErrZstdUnsupported, got %v", err)
+	}
+}
@@ -0,0 +1,27 @@
+//go:build zstd
+
+package zmin
+
+import (
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Building with -tags zstd links github.com/klauspost/compress
+func init() {
+	zstdCodec.newReader = func(r io.Reader) (io.ReadCloser, error) {
+		d, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	}
+	zstdCodec.newWriter = func(w io.Writer, level int) (io.WriteCloser, error) {
+		speed := zstd.SpeedDefault
+		if level != 0 {
+			speed = zstd.EncoderLevelFromZstd(level)
+		}
+		return zstd.NewWriter(w, zstd.WithEncoderLevel(speed))
+	}
+}
@@ -0,0 +1,29 @@
+//go:build zstd
+
+package zmin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestZstdFile(t *testing.T) {
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "data.json")
+	expected := `{"a":[1,2]}`
+	if err := os.WriteFile(plain, []byte(`{ "a" : [1, 2] }`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	compressed := filepath.Join(dir, "data.min.json.zst")
+	if err := MinifyFile(plain, compressed, SPORT); err != nil {
+		t.Fatalf("MinifyFile failed: %v", err)
+	}
+	if err := MinifyFile(compressed, filepath.Join(dir, "data.min.json"), SPORT); err != nil {
+		t.Fatalf("MinifyFile failed: %v", err)
+	}
+	if got := readFile(t, dir, "data.min.json"); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
@@ -0,0 +1,175 @@
+package zmin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ConfigFile is the name of the optional file holding the defaults read by
+// LoadConfig
+const ConfigFile = ".zmin.toml"
+
+// Environment variables overriding the configuration file
+const (
+	EnvMode     = "ZMIN_MODE"      // mode name, as accepted by ParseMode
+	EnvThreads  = "ZMIN_THREADS"   // as set by WithThreads
+	EnvMaxInput = "ZMIN_MAX_INPUT" // bytes, with an optional K, M or G suffix
+)
+
+// LoadConfig returns the defaults deployments configure without code changes,
+// as options for NewMinifier. They are read from the ConfigFile found in dir
+// or its closest ancestor, if any, then overridden by the EnvMode, EnvThreads
+// and EnvMaxInput environment variables. The file is a TOML document of
+// top-level keys:
+//
+//	mode = "turbo"
+//	threads = 4
+//	max_input = "64M"
+//
+// Options passed after the returned ones take precedence over both.
+func LoadConfig(dir string) ([]Option, error) {
+	settings, err := readConfigFile(dir)
+	if err != nil {
+		return nil, err
+	}
+	for key, name := range map[string]string{"mode": EnvMode, "threads": EnvThreads, "max_input": EnvMaxInput} {
+		if value, ok := os.LookupEnv(name); ok && value != "" {
+			settings[key] = configValue{value: value, source: name}
+		}
+	}
+	return configOptions(settings)
+}
+
+var (
+	defaultMinifierOnce sync.Once
+	defaultMinifier     *Minifier
+	defaultMinifierErr  error
+)
+
+// DefaultMinifier returns a Minifier shared by the process, configured by
+// LoadConfig for the working directory when it is first called. The
+// configuration error, if any, is returned by every call. The Minifier must
+// not be closed.
+func DefaultMinifier() (*Minifier, error) {
+	defaultMinifierOnce.Do(func() {
+		var opts []Option
+		if dir, err := os.Getwd(); err != nil {
+			defaultMinifierErr = err
+		} else if opts, err = LoadConfig(dir); err != nil {
+			defaultMinifierErr = err
+		}
+		if defaultMinifierErr == nil {
+			defaultMinifier = NewMinifier(opts...)
+		}
+	})
+	return defaultMinifier, defaultMinifierErr
+}
+
+// configValue is a setting and where it was read from, for error messages
+type configValue struct {
+	value  string
+	source string
+}
+
+// readConfigFile returns the settings of the ConfigFile in dir or its closest
+// ancestor, or none if there is no such file
+func readConfigFile(dir string) (map[string]configValue, error) {
+	settings := make(map[string]configValue)
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	for {
+		data, err = os.ReadFile(filepath.Join(dir, ConfigFile))
+		if err == nil {
+			break
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return settings, nil
+		}
+		dir = parent
+	}
+
+	path := filepath.Join(dir, ConfigFile)
+	var doc map[string]interface{}
+	if _, err := toml.Decode(string(data), &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	// Values of the wrong type are rejected by configOptions in their
+	// printed form
+	for key, value := range doc {
+		settings[key] = configValue{value: fmt.Sprint(value), source: path}
+	}
+	return settings, nil
+}
+
+// configOptions converts settings to options
+func configOptions(settings map[string]configValue) ([]Option, error) {
+	var opts []Option
+	for _, key := range []string{"mode", "threads", "max_input"} {
+		setting, ok := settings[key]
+		if !ok {
+			continue
+		}
+		switch key {
+		case "mode":
+			mode, err := ParseMode(setting.value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", setting.source, err)
+			}
+			opts = append(opts, WithMode(mode))
+		case "threads":
+			n, err := strconv.Atoi(setting.value)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("%s: invalid thread count %q", setting.source, setting.value)
+			}
+			opts = append(opts, WithThreads(n))
+		case "max_input":
+			n, err := parseSize(setting.value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid size %q", setting.source, setting.value)
+			}
+			opts = append(opts, WithMaxInputSize(n))
+		}
+	}
+	for key, setting := range settings {
+		if key != "mode" && key != "threads" && key != "max_input" {
+			return nil, fmt.Errorf("%s: unknown setting %q", setting.source, key)
+		}
+	}
+	return opts, nil
+}
+
+// parseSize parses a number of bytes with an optional K, M or G suffix, for
+// binary multiples
+func parseSize(s string) (int, error) {
+	shift := 0
+	if n := len(s); n > 0 {
+		switch s[n-1] {
+		case 'k', 'K':
+			shift = 10
+		case 'm', 'M':
+			shift = 20
+		case 'g', 'G':
+			shift = 30
+		}
+		if shift > 0 {
+			s = s[:n-1]
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n > int(^uint(0)>>1)>>shift {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return n << shift, nil
+}
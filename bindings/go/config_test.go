@@ -0,0 +1,95 @@
+package zmin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// configured returns the Minifier configured by LoadConfig for dir
+func configured(t *testing.T, dir string) *Minifier {
+	t.Helper()
+	opts, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	m := NewMinifier(opts...)
+	t.Cleanup(func() { m.Close() })
+	return m
+}
+
+func TestLoadConfig(t *testing.T) {
+	for _, name := range []string{EnvMode, EnvThreads, EnvMaxInput} {
+		t.Setenv(name, "")
+	}
+	root := t.TempDir()
+	sub := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if m := configured(t, sub); m.Mode() != SPORT || m.opts.MaxInputSize != 0 {
+		t.Errorf("Expected the defaults without configuration, got %v, %d", m.Mode(), m.opts.MaxInputSize)
+	}
+
+	config := "# defaults\nmode = 'turbo'  # fastest\nthreads = 4\nmax_input = \"2K\"\n"
+	if err := os.WriteFile(filepath.Join(root, ConfigFile), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := configured(t, sub)
+	if m.Mode() != TURBO || m.threads != 4 || m.opts.MaxInputSize != 2048 {
+		t.Errorf("Expected turbo, 4 threads and 2048 bytes, got %v, %d, %d", m.Mode(), m.threads, m.opts.MaxInputSize)
+	}
+
+	t.Setenv(EnvMode, "ECO")
+	t.Setenv(EnvMaxInput, "100")
+	m = configured(t, sub)
+	if m.Mode() != ECO || m.threads != 4 || m.opts.MaxInputSize != 100 {
+		t.Errorf("Expected the environment to override the file, got %v, %d, %d", m.Mode(), m.threads, m.opts.MaxInputSize)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	for _, name := range []string{EnvMode, EnvThreads, EnvMaxInput} {
+		t.Setenv(name, "")
+	}
+	tests := []struct {
+		config string
+		want   string
+	}{
+		{"mode = \"fast\"\n", ".zmin.toml: invalid mode"},
+		{"threads = -1\n", "invalid thread count"},
+		{"threads = 1.5\n", `invalid thread count "1.5"`},
+		{"max_input = \"1X\"\n", ".zmin.toml: invalid size"},
+		{"max_input = 1X\n", ".zmin.toml: toml: "},
+		{"colour = \"blue\"\n", `unknown setting "colour"`},
+		{"[table]\n", `unknown setting "table"`},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, ConfigFile), []byte(tt.config), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadConfig(dir); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: expected an error containing %q, got %v", tt.config, tt.want, err)
+		}
+	}
+
+	t.Setenv(EnvThreads, "many")
+	if _, err := LoadConfig(t.TempDir()); err == nil || !strings.Contains(err.Error(), EnvThreads) {
+		t.Errorf("Expected an error naming %s, got %v", EnvThreads, err)
+	}
+}
+
+func TestDefaultMinifier(t *testing.T) {
+	m, err := DefaultMinifier()
+	if err != nil {
+		t.Fatalf("DefaultMinifier failed: %v", err)
+	}
+	if again, _ := DefaultMinifier(); again != m {
+		t.Error("Expected DefaultMinifier to return a shared Minifier")
+	}
+	if output, err := m.Minify(`{ "a": 1 }`); err != nil || output != `{"a":1}` {
+		t.Errorf("Expected %q, got %q, %v", `{"a":1}`, output, err)
+	}
+}
@@ -0,0 +1,188 @@
+package zmin
+
+import (
+	"context"
+	"io"
+	"os"
+)
+
+// runContext runs fn and returns its result, or returns ctx.Err() as soon as
+// ctx is done. The native library cannot be interrupted, so an abandoned call
+// runs to completion in the background and its result is discarded.
+func runContext[T any](ctx context.Context, fn func() (T, error)) (T, error) {
+	var zero T
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+	if ctx.Done() == nil {
+		return fn()
+	}
+
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		value, err := fn()
+		done <- result{value, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+// detachInput copies input when ctx can abandon the call. An abandoned call
+// keeps reading its input, so it must not share a buffer that the caller will
+// reuse once the Context variant has returned.
+func detachInput(ctx context.Context, input []byte) []byte {
+	if ctx.Done() == nil {
+		return input
+	}
+	return append([]byte(nil), input...)
+}
+
+// contextReader stops reading once its context is done
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+// MinifyContext is like MinifyWithMode but returns early with ctx.Err() when
+// ctx is cancelled or its deadline passes
+func MinifyContext(ctx context.Context, input interface{}, mode ProcessingMode) (string, error) {
+	if r, ok := input.(io.Reader); ok {
+		return MinifyReaderContext(ctx, r, mode)
+	}
+	if b, ok := input.([]byte); ok {
+		input = detachInput(ctx, b)
+	}
+	return runContext(ctx, func() (string, error) {
+		return MinifyWithMode(input, mode)
+	})
+}
+
+// MinifyBytesContext is like MinifyBytes but honors cancellation of ctx. The
+// input may be reused as soon as it returns, even if ctx was cancelled.
+func MinifyBytesContext(ctx context.Context, input []byte, mode ProcessingMode) ([]byte, error) {
+	input = detachInput(ctx, input)
+	return runContext(ctx, func() ([]byte, error) {
+		return MinifyBytes(input, mode)
+	})
+}
+
+// MinifyReaderContext is like MinifyReader but honors cancellation of ctx,
+// both while reading and while minifying
+func MinifyReaderContext(ctx context.Context, r io.Reader, mode ProcessingMode) (string, error) {
+	data, err := io.ReadAll(contextReader{ctx, r})
+	if err != nil {
+		return "", err
+	}
+	return runContext(ctx, func() (string, error) {
+		return MinifyWithMode(string(data), mode)
+	})
+}
+
+// MinifyToWriterContext is like MinifyToWriter but honors cancellation of ctx.
+// Nothing is written to w if ctx is done before minification completes.
+func MinifyToWriterContext(ctx context.Context, r io.Reader, w io.Writer, mode ProcessingMode) (int64, error) {
+	data, err := io.ReadAll(contextReader{ctx, r})
+	if err != nil {
+		return 0, err
+	}
+	output, err := MinifyBytesContext(ctx, data, mode)
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(output)
+	return int64(n), err
+}
+
+// MinifyFileContext is like MinifyFile but honors cancellation of ctx. The
+// output file is not written if ctx is done before minification completes.
+func MinifyFileContext(ctx context.Context, inputPath, outputPath string, mode ProcessingMode) error {
+	input, err := os.ReadFile(inputPath)
+	if err != nil {
+		return err
+	}
+	if input, err = decompressBytes(input, 0); err != nil {
+		return err
+	}
+	output, err := MinifyBytesContext(ctx, input, mode)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return writeOutputFile(outputPath, output, compressOptions{})
+}
+
+// MinifyContext minifies JSON using the configured mode, honoring ctx
+func (m *Minifier) MinifyContext(ctx context.Context, input interface{}) (string, error) {
+	if r, ok := input.(io.Reader); ok {
+		return m.MinifyReaderContext(ctx, r)
+	}
+	if b, ok := input.([]byte); ok {
+		input = detachInput(ctx, b)
+	}
+	return runContext(ctx, func() (string, error) {
+		return m.Minify(input)
+	})
+}
+
+// MinifyBytesContext minifies JSON bytes using the configured mode, honoring ctx
+func (m *Minifier) MinifyBytesContext(ctx context.Context, input []byte) ([]byte, error) {
+	input = detachInput(ctx, input)
+	return runContext(ctx, func() ([]byte, error) {
+		return m.MinifyBytes(input)
+	})
+}
+
+// MinifyReaderContext minifies JSON from reader using the configured mode,
+// honoring ctx
+func (m *Minifier) MinifyReaderContext(ctx context.Context, r io.Reader) (string, error) {
+	data, err := io.ReadAll(contextReader{ctx, r})
+	if err != nil {
+		return "", err
+	}
+	return runContext(ctx, func() (string, error) {
+		return m.Minify(data)
+	})
+}
+
+// MinifyFileContext minifies a file using the configured mode, honoring ctx
+func (m *Minifier) MinifyFileContext(ctx context.Context, inputPath, outputPath string) error {
+	input, total, err := m.readFile(inputPath)
+	if err != nil {
+		return err
+	}
+	if input, err = decompressBytes(input, m.opts.inputLimit()); err != nil {
+		return err
+	}
+	output, err := m.MinifyBytesContext(ctx, input)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := writeOutputFile(outputPath, output, m.compress); err != nil {
+		return err
+	}
+	if m.progress != nil {
+		m.progress(total, total)
+	}
+	return nil
+}
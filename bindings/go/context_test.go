@@ -0,0 +1,76 @@
+package zmin
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMinifyContext(t *testing.T) {
+	input := `{ "key": "value" }`
+
+	output, err := MinifyContext(context.Background(), input, SPORT)
+	if err != nil {
+		t.Fatalf("MinifyContext failed: %v", err)
+	}
+	if output != `{"key":"value"}` {
+		t.Errorf("Unexpected output %q", output)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	output, err = NewMinifier(WithMode(TURBO)).MinifyContext(ctx, strings.NewReader(input))
+	if err != nil || output != `{"key":"value"}` {
+		t.Errorf("Minifier.MinifyContext: got %q, %v", output, err)
+	}
+
+	// A cancelled context is reported without minifying
+	cancel()
+	if _, err := MinifyContext(ctx, input, SPORT); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if _, err := MinifyBytesContext(ctx, []byte(input), SPORT); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if _, err := MinifyReaderContext(ctx, strings.NewReader(input), SPORT); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestRunContextAbandons(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	defer close(release)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	// A call that outlives the context is abandoned promptly
+	_, err := runContext(ctx, func() (string, error) {
+		<-release
+		return "late", nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestMinifyBytesContextDetachesInput(t *testing.T) {
+	release := make(chan struct{})
+	SetDefaultBackend(blockingBackend{release: release})
+	defer SetDefaultBackend(nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	input := []byte(`[ 1, 2, 3 ]`)
+	if _, err := MinifyBytesContext(ctx, input, ECO); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	// The abandoned call works on a copy of the input
+	copy(input, "xxxxxxxxxxx")
+	close(release)
+}
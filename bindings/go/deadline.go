@@ -0,0 +1,109 @@
+package zmin
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// calibrationTime is how long the calibration benchmark runs for each mode
+const calibrationTime = 20 * time.Millisecond
+
+var (
+	throughputMu  sync.Mutex
+	throughput    [3]float64 // bytes per second, indexed by ProcessingMode
+	calibrateOnce sync.Once
+)
+
+// SetThroughput records the expected throughput of a processing mode in input
+// bytes per second. Figures set this way take precedence over the built-in
+// calibration benchmark used by ModeForDeadline.
+func SetThroughput(mode ProcessingMode, bytesPerSecond float64) error {
+	if mode < ECO || mode > TURBO {
+		return ErrInvalidMode
+	}
+	if bytesPerSecond <= 0 {
+		return fmt.Errorf("invalid throughput %v", bytesPerSecond)
+	}
+	throughputMu.Lock()
+	throughput[mode] = bytesPerSecond
+	throughputMu.Unlock()
+	return nil
+}
+
+// ModeForDeadline picks a processing mode for an input of inputSize bytes that
+// is expected to finish within deadline. Modes are tried from the lightest to
+// the heaviest (ECO, SPORT, TURBO) and the first one whose expected duration
+// fits is returned; if none fits, the mode with the highest throughput is
+// returned.
+//
+// Expected durations come from SetThroughput or, for modes without a figure,
+// from a short calibration benchmark run on first use.
+func ModeForDeadline(inputSize int, deadline time.Duration) ProcessingMode {
+	calibrateOnce.Do(calibrate)
+
+	throughputMu.Lock()
+	rates := throughput
+	throughputMu.Unlock()
+
+	best, bestRate := SPORT, 0.0
+	for _, mode := range []ProcessingMode{ECO, SPORT, TURBO} {
+		rate := rates[mode]
+		if rate <= 0 {
+			continue
+		}
+		expected := time.Duration(float64(inputSize) / rate * float64(time.Second))
+		if expected <= deadline {
+			return mode
+		}
+		if rate > bestRate {
+			best, bestRate = mode, rate
+		}
+	}
+	return best
+}
+
+// calibrate measures the throughput of every mode without a configured figure
+func calibrate() {
+	sample := calibrationSample()
+	for _, mode := range []ProcessingMode{ECO, SPORT, TURBO} {
+		throughputMu.Lock()
+		known := throughput[mode] > 0
+		throughputMu.Unlock()
+		if known {
+			continue
+		}
+
+		var processed int
+		start := time.Now()
+		for time.Since(start) < calibrationTime {
+			if _, err := MinifyWithMode(sample, mode); err != nil {
+				break
+			}
+			processed += len(sample)
+		}
+		if elapsed := time.Since(start); processed > 0 && elapsed > 0 {
+			throughputMu.Lock()
+			if throughput[mode] <= 0 {
+				throughput[mode] = float64(processed) / elapsed.Seconds()
+			}
+			throughputMu.Unlock()
+		}
+	}
+}
+
+// calibrationSample builds a formatted document of typical shape, kept below
+// the ECO mode limit so that every mode can process it
+func calibrationSample() string {
+	var b strings.Builder
+	b.WriteString("[\n")
+	for i := 0; i < 400; i++ {
+		if i > 0 {
+			b.WriteString(",\n")
+		}
+		fmt.Fprintf(&b, "  {\n    \"id\": %d,\n    \"name\": \"item %d\",\n    \"active\": %t,\n    \"score\": %d.5,\n    \"tags\": [\"alpha\", \"beta\"]\n  }", i, i, i%2 == 0, i)
+	}
+	b.WriteString("\n]\n")
+	return b.String()
+}
@@ -0,0 +1,72 @@
+package zmin
+
+import (
+	"testing"
+	"time"
+)
+
+func TestModeForDeadline(t *testing.T) {
+	throughputMu.Lock()
+	saved := throughput
+	throughputMu.Unlock()
+	defer func() {
+		throughputMu.Lock()
+		throughput = saved
+		throughputMu.Unlock()
+	}()
+
+	const mb = 1 << 20
+	for mode, rate := range map[ProcessingMode]float64{ECO: 100 * mb, SPORT: 300 * mb, TURBO: 1000 * mb} {
+		if err := SetThroughput(mode, rate); err != nil {
+			t.Fatalf("SetThroughput failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		size     int
+		deadline time.Duration
+		expected ProcessingMode
+	}{
+		{mb, 100 * time.Millisecond, ECO},
+		{100 * mb, 500 * time.Millisecond, SPORT},
+		{500 * mb, time.Second, TURBO},
+		{10000 * mb, time.Second, TURBO}, // nothing fits, use the fastest
+	}
+	for _, tt := range tests {
+		if mode := ModeForDeadline(tt.size, tt.deadline); mode != tt.expected {
+			t.Errorf("ModeForDeadline(%d, %v) = %d, expected %d", tt.size, tt.deadline, mode, tt.expected)
+		}
+	}
+
+	if err := SetThroughput(ProcessingMode(7), mb); err != ErrInvalidMode {
+		t.Errorf("Expected ErrInvalidMode, got %v", err)
+	}
+	if err := SetThroughput(SPORT, 0); err == nil {
+		t.Error("Expected error for non-positive throughput")
+	}
+}
+
+func TestCalibrate(t *testing.T) {
+	throughputMu.Lock()
+	saved := throughput
+	throughput = [3]float64{}
+	throughputMu.Unlock()
+	defer func() {
+		throughputMu.Lock()
+		throughput = saved
+		throughputMu.Unlock()
+	}()
+
+	if !Validate(calibrationSample()) {
+		t.Fatal("Calibration sample is not valid JSON")
+	}
+
+	calibrate()
+	throughputMu.Lock()
+	defer throughputMu.Unlock()
+	for mode, rate := range throughput {
+		if rate <= 0 {
+			t.Errorf("Expected positive throughput for mode %d, got %v", mode, rate)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package zmin
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// Change is one difference between two documents, expressed as a JSON Patch
+// (RFC 6902) operation that moves the first document towards the second
+type Change struct {
+	Op    string          `json:"op"`              // "add", "remove" or "replace"
+	Path  string          `json:"path"`            // JSON Pointer to the changed value
+	Value json.RawMessage `json:"value,omitempty"` // new value, for add and replace
+	Old   json.RawMessage `json:"old,omitempty"`   // previous value, for remove and replace
+}
+
+// Diff compares two JSON documents and returns the changes that turn a into
+// b, in an order in which they can be applied one after another. Values are
+// compared as by Equal, so formatting and key order produce no changes.
+// Objects are compared member by member and arrays element by element, with
+// elements added or removed at the end; values of different types are
+// replaced whole. Values in the changes are minified.
+func Diff(a, b []byte) ([]Change, error) {
+	treeA, err := parseTree(a)
+	if err != nil {
+		return nil, err
+	}
+	treeB, err := parseTree(b)
+	if err != nil {
+		return nil, err
+	}
+	return diffNodes(nil, nil, treeA, treeB), nil
+}
+
+// diffNodes appends the changes between the values at path to changes
+func diffNodes(changes []Change, path []string, a, b *jsonNode) []Change {
+	if a.equal(b) {
+		return changes
+	}
+	switch {
+	case a.kind == tokObjectStart && b.kind == tokObjectStart:
+		for _, key := range a.keys {
+			if _, ok := b.members[key]; !ok {
+				changes = append(changes, Change{Op: "remove", Path: formatPointer(append(path, key)), Old: a.members[key].raw})
+			}
+		}
+		for _, key := range a.keys {
+			if member, ok := b.members[key]; ok {
+				changes = diffNodes(changes, append(path, key), a.members[key], member)
+			}
+		}
+		for _, key := range b.keys {
+			if _, ok := a.members[key]; !ok {
+				changes = append(changes, Change{Op: "add", Path: formatPointer(append(path, key)), Value: b.members[key].raw})
+			}
+		}
+	case a.kind == tokArrayStart && b.kind == tokArrayStart:
+		for i := 0; i < len(a.elems) && i < len(b.elems); i++ {
+			changes = diffNodes(changes, append(path, strconv.Itoa(i)), a.elems[i], b.elems[i])
+		}
+		// Remove from the end so that earlier indexes stay valid
+		for i := len(a.elems) - 1; i >= len(b.elems); i-- {
+			changes = append(changes, Change{Op: "remove", Path: formatPointer(append(path, strconv.Itoa(i))), Old: a.elems[i].raw})
+		}
+		for i := len(a.elems); i < len(b.elems); i++ {
+			changes = append(changes, Change{Op: "add", Path: formatPointer(append(path, strconv.Itoa(i))), Value: b.elems[i].raw})
+		}
+	default:
+		changes = append(changes, Change{Op: "replace", Path: formatPointer(path), Value: b.raw, Old: a.raw})
+	}
+	return changes
+}
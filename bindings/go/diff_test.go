@@ -0,0 +1,78 @@
+package zmin
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	a := `{
+		"name": "zmin",
+		"version": 1.0,
+		"tags": ["json", "fast", "old"],
+		"meta": {"a/b": 1, "gone": true},
+		"kind": [1]
+	}`
+	b := `{"kind":{"x":1},"tags":["json","faster"],"version":1,"name":"zmin","meta":{"a/b":2},"new":null}`
+
+	changes, err := Diff([]byte(a), []byte(b))
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	expected := []Change{
+		{Op: "replace", Path: "/tags/1", Value: json.RawMessage(`"faster"`), Old: json.RawMessage(`"fast"`)},
+		{Op: "remove", Path: "/tags/2", Old: json.RawMessage(`"old"`)},
+		{Op: "remove", Path: "/meta/gone", Old: json.RawMessage(`true`)},
+		{Op: "replace", Path: "/meta/a~1b", Value: json.RawMessage(`2`), Old: json.RawMessage(`1`)},
+		{Op: "replace", Path: "/kind", Value: json.RawMessage(`{"x":1}`), Old: json.RawMessage(`[1]`)},
+		{Op: "add", Path: "/new", Value: json.RawMessage(`null`)},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected %s, got %s", marshalChanges(t, expected), marshalChanges(t, changes))
+	}
+}
+
+func TestDiffEqual(t *testing.T) {
+	changes, err := Diff([]byte(`{"a":[1,2],"b":"x"}`), []byte(`{ "b" : "x", "a" : [ 1.0, 2e0 ] }`))
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("Expected no changes, got %s", marshalChanges(t, changes))
+	}
+
+	changes, err = Diff([]byte(`[1]`), []byte(`[1,2,3]`))
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	expected := []Change{
+		{Op: "add", Path: "/1", Value: json.RawMessage(`2`)},
+		{Op: "add", Path: "/2", Value: json.RawMessage(`3`)},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected %s, got %s", marshalChanges(t, expected), marshalChanges(t, changes))
+	}
+
+	changes, err = Diff([]byte(`1`), []byte(`"1"`))
+	if err != nil || len(changes) != 1 || changes[0].Path != "" || changes[0].Op != "replace" {
+		t.Errorf("Expected a replacement of the whole document, got %v, %v", changes, err)
+	}
+}
+
+func TestDiffInvalid(t *testing.T) {
+	if _, err := Diff([]byte(`{}`), []byte(`{"a":}`)); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON, got %v", err)
+	}
+}
+
+// marshalChanges formats changes for test failure messages
+func marshalChanges(t *testing.T, changes []Change) string {
+	t.Helper()
+	data, err := json.Marshal(changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
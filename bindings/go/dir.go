@@ -0,0 +1,287 @@
+package zmin
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DirOptions configures MinifyDir
+type DirOptions struct {
+	// Patterns selects the files to minify by base name, using the syntax of
+	// filepath.Match. Defaults to "*.json".
+	Patterns []string
+
+	// Workers is the number of files minified concurrently. Defaults to
+	// GOMAXPROCS.
+	Workers int
+
+	// Exclude lists further paths to skip, in the gitignore syntax of
+	// .zminignore files. They are applied after the rules of the .zminignore
+	// file in root, if there is one, so they take precedence.
+	Exclude []string
+
+	// OutputDir receives the minified files, mirroring the layout under root.
+	// If empty, files are minified in place.
+	OutputDir string
+
+	// Options configures the Minifier used by each worker
+	Options []Option
+}
+
+// DirStats summarizes a MinifyDir run
+type DirStats struct {
+	Files       int            // files minified
+	InputBytes  int64          // total size of the minified files before
+	OutputBytes int64          // total size of the minified files after
+	Ratio       float64        // OutputBytes / InputBytes, 1 if nothing was minified
+	Duration    time.Duration  // wall-clock time taken
+	Mode        ProcessingMode // mode configured for the files
+	Errors      []*FileError
+}
+
+// Saved returns the number of bytes minification removed
+func (s DirStats) Saved() int64 {
+	return s.InputBytes - s.OutputBytes
+}
+
+// FileError reports a file that could not be minified
+type FileError struct {
+	Path string
+	Err  error
+}
+
+func (e *FileError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// MinifyDir walks the tree under root and minifies every regular file matching
+// opts.Patterns on a bounded pool of workers. Each file is replaced
+// atomically, as by MinifyFileAtomic. Paths listed in a .zminignore file in
+// root, in gitignore syntax, or in opts.Exclude are skipped; an ignored
+// directory is not descended into. Files that cannot be read or minified
+// are reported in DirStats.Errors, sorted by path, without stopping the walk;
+// the returned error is reserved for an unreadable root or a done ctx.
+func MinifyDir(ctx context.Context, root string, opts DirOptions) (DirStats, error) {
+	patterns := opts.Patterns
+	if len(patterns) == 0 {
+		patterns = []string{"*.json"}
+	}
+	for _, pattern := range patterns {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return DirStats{}, err
+		}
+	}
+	ignore, err := readIgnoreFile(root)
+	if err != nil {
+		return DirStats{}, err
+	}
+	exclude, err := parseIgnore(opts.Exclude)
+	if err != nil {
+		return DirStats{}, err
+	}
+	ignore = append(ignore, exclude...)
+
+	// Skip the output directory if it lies within root
+	var outputDir string
+	if opts.OutputDir != "" {
+		if outputDir, err = filepath.Abs(opts.OutputDir); err != nil {
+			return DirStats{}, err
+		}
+	}
+
+	return minifyFiles(ctx, opts.Workers, opts.Options, true, func(send func(fileJob) error, fail func(string, error)) error {
+		return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if path == root {
+					return err
+				}
+				fail(path, err)
+				return nil
+			}
+			if path != root && len(ignore) > 0 {
+				if rel, err := filepath.Rel(root, path); err == nil && ignore.ignored(filepath.ToSlash(rel), d.IsDir()) {
+					if d.IsDir() {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+			}
+			if d.IsDir() {
+				if outputDir != "" && path != root {
+					if abs, err := filepath.Abs(path); err == nil && abs == outputDir {
+						return filepath.SkipDir
+					}
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() || !matchAny(patterns, d.Name()) {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				fail(path, err)
+				return nil
+			}
+			job := fileJob{path: path, target: path, size: info.Size()}
+			if opts.OutputDir != "" {
+				rel, err := filepath.Rel(root, path)
+				if err != nil {
+					fail(path, err)
+					return nil
+				}
+				job.target = filepath.Join(opts.OutputDir, rel)
+			}
+			return send(job)
+		})
+	})
+}
+
+// fileJob is a file to minify and where to write the result, which is the
+// file itself for in-place minification
+type fileJob struct {
+	path, target string
+	size         int64
+}
+
+// minifyFiles minifies the files that walk sends on a pool of workers, each
+// with its own Minifier configured by opts. walk reports files it cannot
+// process with fail; send fails once ctx is done. The whole walk completes
+// before minification starts, so that progress can be reported against the
+// total size. Existing targets other than the file itself are only replaced
+// if overwrite is set.
+func minifyFiles(ctx context.Context, workers int, opts []Option, overwrite bool,
+	walk func(send func(fileJob) error, fail func(string, error)) error) (DirStats, error) {
+	var stats DirStats
+	start := time.Now()
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	cfg := configOf(opts)
+	progress := cfg.progress
+
+	var mu sync.Mutex
+	fail := func(path string, err error) {
+		mu.Lock()
+		stats.Errors = append(stats.Errors, &FileError{Path: path, Err: err})
+		mu.Unlock()
+	}
+
+	var queue []fileJob
+	var total, processed int64
+	err := walk(func(job fileJob) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		queue = append(queue, job)
+		total += job.size
+		return nil
+	}, fail)
+	if err != nil {
+		return stats, err
+	}
+
+	jobs := make(chan fileJob)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			m := NewMinifier(opts...)
+			defer m.Close()
+			for job := range jobs {
+				in, out, err := minifyFileJob(m, job, overwrite)
+				mu.Lock()
+				if err != nil {
+					stats.Errors = append(stats.Errors, &FileError{Path: job.path, Err: err})
+				} else {
+					stats.Files++
+					stats.InputBytes += in
+					stats.OutputBytes += out
+				}
+				processed += job.size
+				if progress != nil {
+					progress(processed, total)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+dispatch:
+	for _, job := range queue {
+		select {
+		case jobs <- job:
+		case <-ctx.Done():
+			err = ctx.Err()
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if progress != nil && err == nil && len(queue) == 0 {
+		progress(0, 0)
+	}
+
+	sort.Slice(stats.Errors, func(i, j int) bool {
+		return stats.Errors[i].Path < stats.Errors[j].Path
+	})
+	stats.Mode = cfg.mode
+	stats.Duration = time.Since(start)
+	stats.Ratio = ratio(stats.InputBytes, stats.OutputBytes)
+	return stats, err
+}
+
+// matchAny reports whether name matches one of patterns, which are known to
+// be well-formed
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// minifyFileJob minifies a file to its target and returns its sizes
+func minifyFileJob(m *Minifier, job fileJob, overwrite bool) (in, out int64, err error) {
+	info, err := os.Stat(job.path)
+	if err != nil {
+		return 0, 0, err
+	}
+	if job.target != job.path && !overwrite {
+		if _, err := os.Lstat(job.target); err == nil {
+			return 0, 0, &fs.PathError{Op: "write", Path: job.target, Err: fs.ErrExist}
+		}
+	}
+	input, err := os.ReadFile(job.path)
+	if err != nil {
+		return 0, 0, err
+	}
+	output, err := m.MinifyBytes(input)
+	if err != nil {
+		return 0, 0, err
+	}
+	in, out = int64(len(input)), int64(len(output))
+
+	if job.target == job.path {
+		if bytes.Equal(input, output) {
+			return in, out, nil
+		}
+	} else if err := os.MkdirAll(filepath.Dir(job.target), 0755); err != nil {
+		return 0, 0, err
+	}
+	return in, out, writeFileAtomic(job.target, output, info)
+}
@@ -0,0 +1,112 @@
+package zmin
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTree creates files under dir from a map of relative paths to contents
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// readFile returns the contents of a file under dir
+func readFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestMinifyDir(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"a.json":          `{ "a" : 1 }`,
+		"sub/b.json":      `[ 1, 2 ]`,
+		"sub/deep/c.json": `{ "c" : [ ] }`,
+		"bad.json":        `{"a": }`,
+		"notes.txt":       `{ "untouched" : true }`,
+	})
+
+	stats, err := MinifyDir(context.Background(), root, DirOptions{Workers: 2})
+	if err != nil {
+		t.Fatalf("MinifyDir failed: %v", err)
+	}
+	if stats.Files != 3 {
+		t.Errorf("Expected 3 files, got %d", stats.Files)
+	}
+	if len(stats.Errors) != 1 || stats.Errors[0].Path != filepath.Join(root, "bad.json") ||
+		!errors.Is(stats.Errors[0], ErrInvalidJSON) {
+		t.Errorf("Expected one ErrInvalidJSON for bad.json, got %v", stats.Errors)
+	}
+	for name, expected := range map[string]string{
+		"a.json":          `{"a":1}`,
+		"sub/b.json":      `[1,2]`,
+		"sub/deep/c.json": `{"c":[]}`,
+		"notes.txt":       `{ "untouched" : true }`,
+	} {
+		if got := readFile(t, root, name); got != expected {
+			t.Errorf("%s: expected %q, got %q", name, expected, got)
+		}
+	}
+}
+
+func TestMinifyDirOutputDir(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"a.json":     `{ "a" : 1 }`,
+		"sub/b.json": `[ 1, 2 ]`,
+	})
+	out := filepath.Join(root, "min")
+
+	stats, err := MinifyDir(context.Background(), root, DirOptions{OutputDir: out})
+	if err != nil {
+		t.Fatalf("MinifyDir failed: %v", err)
+	}
+	if stats.Files != 2 || len(stats.Errors) != 0 {
+		t.Errorf("Expected 2 files and no errors, got %d files and %v", stats.Files, stats.Errors)
+	}
+	if got := readFile(t, out, "sub/b.json"); got != `[1,2]` {
+		t.Errorf("Expected %q, got %q", `[1,2]`, got)
+	}
+	if got := readFile(t, root, "a.json"); got != `{ "a" : 1 }` {
+		t.Errorf("Expected the source to be untouched, got %q", got)
+	}
+
+	// A second run must not descend into the output
+	stats, err = MinifyDir(context.Background(), root, DirOptions{OutputDir: out})
+	if err != nil {
+		t.Fatalf("MinifyDir failed: %v", err)
+	}
+	if stats.Files != 2 {
+		t.Errorf("Expected 2 files, got %d", stats.Files)
+	}
+}
+
+func TestMinifyDirCanceled(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{"a.json": `{ "a" : 1 }`})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := MinifyDir(ctx, root, DirOptions{}); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if _, err := MinifyDir(context.Background(), filepath.Join(root, "missing"), DirOptions{}); !os.IsNotExist(err) {
+		t.Errorf("Expected a not-exist error, got %v", err)
+	}
+}
@@ -0,0 +1,28 @@
+//go:build (zmin_dlopen || zmin_embed) && unix
+
+package zmin
+
+import (
+	"runtime"
+
+	"github.com/ebitengine/purego"
+)
+
+// libraryName returns the file name libzmin is loaded by, from the dynamic
+// linker's search path
+func libraryName() string {
+	if runtime.GOOS == "darwin" {
+		return "libzmin.dylib"
+	}
+	return "libzmin.so"
+}
+
+// openLibrary loads the shared library name with dlopen
+func openLibrary(name string) (uintptr, error) {
+	return purego.Dlopen(name, purego.RTLD_NOW|purego.RTLD_GLOBAL)
+}
+
+// librarySymbol returns the address of the function name in lib
+func librarySymbol(lib uintptr, name string) (uintptr, error) {
+	return purego.Dlsym(lib, name)
+}
@@ -0,0 +1,22 @@
+//go:build zmin_dlopen || zmin_embed
+
+package zmin
+
+import "syscall"
+
+// libraryName returns the file name libzmin is loaded by, from the DLL
+// search path, which includes the directory of the executable and PATH
+func libraryName() string {
+	return "zmin.dll"
+}
+
+// openLibrary loads the DLL name with LoadLibrary
+func openLibrary(name string) (uintptr, error) {
+	lib, err := syscall.LoadLibrary(name)
+	return uintptr(lib), err
+}
+
+// librarySymbol returns the address of the function name in lib
+func librarySymbol(lib uintptr, name string) (uintptr, error) {
+	return syscall.GetProcAddress(syscall.Handle(lib), name)
+}
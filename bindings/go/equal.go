@@ -0,0 +1,20 @@
+package zmin
+
+// Equal reports whether two JSON documents are semantically equal, ignoring
+// whitespace, the order of object keys, string escaping and the formatting of
+// numbers. Both documents are reduced to their RFC 8785 canonical form, so
+// numbers compare as float64 values: 1, 1.0 and 1e0 are equal, as are
+// integers beyond 2^53 that round to the same value. Array order is
+// significant. Invalid documents, including objects with duplicate keys,
+// return an error.
+func Equal(a, b []byte) (bool, error) {
+	canonicalA, err := Canonicalize(a)
+	if err != nil {
+		return false, err
+	}
+	canonicalB, err := Canonicalize(b)
+	if err != nil {
+		return false, err
+	}
+	return canonicalA == canonicalB, nil
+}
@@ -0,0 +1,39 @@
+package zmin
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	cases := []struct {
+		a, b  string
+		equal bool
+	}{
+		{`{"a":1,"b":[true,null]}`, "{\n  \"b\": [ true, null ],\n  \"a\": 1\n}", true},
+		{`{"n":1}`, `{"n":1.0}`, true},
+		{`{"n":100}`, `{"n":1e2}`, true},
+		{`"A"`, `"\u0041"`, true},
+		{`{"a":{"x":1,"y":2}}`, `{"a":{"y":2,"x":1}}`, true},
+		{`[1,2]`, `[2,1]`, false},
+		{`{"a":1}`, `{"a":1,"b":2}`, false},
+		{`{"a":"1"}`, `{"a":1}`, false},
+		{`null`, `false`, false},
+	}
+	for _, c := range cases {
+		equal, err := Equal([]byte(c.a), []byte(c.b))
+		if err != nil {
+			t.Fatalf("Equal(%q, %q) failed: %v", c.a, c.b, err)
+		}
+		if equal != c.equal {
+			t.Errorf("Equal(%q, %q): expected %v, got %v", c.a, c.b, c.equal, equal)
+		}
+	}
+
+	if _, err := Equal([]byte(`{"a":}`), []byte(`{}`)); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON, got %v", err)
+	}
+	if _, err := Equal([]byte(`{}`), []byte(`{"a":1,"a":2}`)); !errors.Is(err, ErrDuplicateKey) {
+		t.Errorf("Expected ErrDuplicateKey, got %v", err)
+	}
+}
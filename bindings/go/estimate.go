@@ -0,0 +1,14 @@
+package zmin
+
+// EstimateMinifiedSize returns the size that minifying input would produce,
+// without producing any output, so that callers can decide whether
+// minification is worthwhile or allocate an exact buffer for MinifyInto.
+// Invalid JSON is reported as a *SyntaxError. The size is exact for
+// the plain minifiers; options such as WithOptions may change it.
+func EstimateMinifiedSize(input []byte) (int, error) {
+	n, err := minifyLarge(nil, input)
+	if err == ErrBufferTooSmall {
+		return n, nil
+	}
+	return n, err
+}
@@ -0,0 +1,36 @@
+package zmin
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEstimateMinifiedSize(t *testing.T) {
+	for _, input := range []string{
+		`{ "a" : [ 1, 2, { "b" : "x y" } ], "c" : null }`,
+		` 42 `,
+		`[]`,
+	} {
+		n, err := EstimateMinifiedSize([]byte(input))
+		if err != nil {
+			t.Fatalf("EstimateMinifiedSize(%q) failed: %v", input, err)
+		}
+		output, err := MinifyBytes([]byte(input), SPORT)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != len(output) {
+			t.Errorf("Expected %d for %q, got %d", len(output), input, n)
+		}
+
+		dst := make([]byte, n)
+		if written, err := MinifyInto(dst, []byte(input), SPORT); err != nil || written != n {
+			t.Errorf("Expected MinifyInto to fill the estimated buffer, got %d, %v", written, err)
+		}
+	}
+
+	var syntaxErr *SyntaxError
+	if _, err := EstimateMinifiedSize([]byte(`{"a": }`)); !errors.As(err, &syntaxErr) {
+		t.Errorf("Expected a *SyntaxError, got %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+package zmin
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Extract returns the minified value that an RFC 6901 JSON Pointer refers to
+// in input, such as "/items/0/id", without decoding the document. Members and
+// elements off the path are skipped unparsed, and scanning stops at the end
+// of the value, so grabbing one field near the start of a large payload is
+// cheap; syntax errors after the value are not reported. A pointer to a
+// missing value returns ErrPointerNotFound.
+func Extract(input []byte, pointer string) ([]byte, error) {
+	path, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := locateValue(input, path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", pointer, err)
+	}
+	return MinifyBytes(input[start:end], SPORT)
+}
+
+// MinifyAndExtract minifies input like MinifyBytes and also returns the value
+// that pointer refers to, as a slice of the minified output
+func MinifyAndExtract(input []byte, pointer string, mode ProcessingMode) (output, value []byte, err error) {
+	path, err := parsePointer(pointer)
+	if err != nil {
+		return nil, nil, err
+	}
+	output, err = MinifyBytes(input, mode)
+	if err != nil {
+		return nil, nil, err
+	}
+	start, end, err := locateValue(output, path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", pointer, err)
+	}
+	return output, output[start:end:end], nil
+}
+
+// locateValue returns the span of the value at path in data
+func locateValue(data []byte, path []string) (start, end int, err error) {
+	s := newScanner(data)
+	tok, err := s.next()
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, part := range path {
+		switch tok.kind {
+		case tokObjectStart:
+			tok, err = findMember(s, part)
+		case tokArrayStart:
+			tok, err = findElement(s, part)
+		default:
+			err = ErrPointerNotFound
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	end, err = s.finishValue(tok)
+	if err != nil {
+		return 0, 0, err
+	}
+	return tok.offset, end, nil
+}
+
+// findMember returns the first token of the member named key of an object
+// whose '{' has been consumed
+func findMember(s *scanner, key string) (token, error) {
+	for {
+		keyTok, err := s.next()
+		if err != nil {
+			return token{}, err
+		}
+		if keyTok.kind == tokObjectEnd {
+			return token{}, ErrPointerNotFound
+		}
+		name, err := decodeString(keyTok.raw)
+		if err != nil {
+			return token{}, err
+		}
+		tok, err := s.next()
+		if err != nil {
+			return token{}, err
+		}
+		if name == key {
+			return tok, nil
+		}
+		if _, err := s.finishValue(tok); err != nil {
+			return token{}, err
+		}
+	}
+}
+
+// findElement returns the first token of the element at the decimal index
+// of an array whose '[' has been consumed
+func findElement(s *scanner, index string) (token, error) {
+	n, ok := parseIndex(index)
+	if !ok {
+		return token{}, ErrPointerNotFound
+	}
+	for i := 0; ; i++ {
+		tok, err := s.next()
+		if err != nil {
+			return token{}, err
+		}
+		if tok.kind == tokArrayEnd {
+			return token{}, ErrPointerNotFound
+		}
+		if i == n {
+			return tok, nil
+		}
+		if _, err := s.finishValue(tok); err != nil {
+			return token{}, err
+		}
+	}
+}
+
+// parseIndex parses an RFC 6901 array index, which has no sign or leading
+// zeros
+func parseIndex(index string) (int, bool) {
+	n, err := strconv.Atoi(index)
+	if err != nil || n < 0 || index[0] == '+' || (len(index) > 1 && index[0] == '0') {
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,65 @@
+package zmin
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	input := []byte(`{
+		"id": 7,
+		"items": [ {"name": "a"}, {"name": "b", "tags": [ 1, 2 ]} ],
+		"a/b": {"~": true},
+		"broken": [
+	`)
+	cases := map[string]string{
+		"/id":             `7`,
+		"/items/1":        `{"name":"b","tags":[1,2]}`,
+		"/items/1/tags/0": `1`,
+		"/a~1b/~0":        `true`,
+	}
+	for pointer, expected := range cases {
+		value, err := Extract(input, pointer)
+		if err != nil {
+			t.Fatalf("Extract(%q) failed: %v", pointer, err)
+		}
+		if string(value) != expected {
+			t.Errorf("Extract(%q): expected %q, got %q", pointer, expected, value)
+		}
+	}
+
+	valid := []byte(`{"id": 7, "items": [{}, {}]}`)
+	for _, pointer := range []string{"/missing", "/items/2", "/items/-", "/items/01", "/id/x"} {
+		if _, err := Extract(valid, pointer); !errors.Is(err, ErrPointerNotFound) {
+			t.Errorf("Extract(%q): expected ErrPointerNotFound, got %v", pointer, err)
+		}
+	}
+	if _, err := Extract(input, "/broken/0"); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON, got %v", err)
+	}
+	if _, err := Extract(input, "id"); err == nil {
+		t.Error("Expected an error for an invalid pointer")
+	}
+
+	whole, err := Extract([]byte(` [ 1 ] `), "")
+	if err != nil || string(whole) != `[1]` {
+		t.Errorf("Expected %q, got %q, %v", `[1]`, whole, err)
+	}
+}
+
+func TestMinifyAndExtract(t *testing.T) {
+	output, value, err := MinifyAndExtract([]byte(`{ "a" : { "b" : [ 1, 2 ] } }`), "/a/b", TURBO)
+	if err != nil {
+		t.Fatalf("MinifyAndExtract failed: %v", err)
+	}
+	if string(output) != `{"a":{"b":[1,2]}}` {
+		t.Errorf("Expected %q, got %q", `{"a":{"b":[1,2]}}`, output)
+	}
+	if string(value) != `[1,2]` {
+		t.Errorf("Expected %q, got %q", `[1,2]`, value)
+	}
+
+	if _, _, err := MinifyAndExtract([]byte(`{"a":1}`), "/b", SPORT); !errors.Is(err, ErrPointerNotFound) {
+		t.Errorf("Expected ErrPointerNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+package zmin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Flatten turns a JSON document into a minified single-level object whose
+// keys are the paths of its leaves: object keys joined by sep, with array
+// indexes appended in brackets, so {"a":{"b":[1]}} becomes {"a.b[0]":1} with
+// sep ".". Empty objects and arrays are kept as leaves, and a scalar document
+// becomes a member with the empty key, so that Unflatten restores the input.
+// Keys that contain sep or end in a bracketed number cannot be restored.
+func Flatten(input []byte, sep string) ([]byte, error) {
+	root, err := parseTree(input)
+	if err != nil {
+		return nil, err
+	}
+	if root.kind == tokObjectStart && len(root.keys) == 0 {
+		return []byte("{}"), nil
+	}
+	out := append(make([]byte, 0, len(input)+len(input)/2), '{')
+	out = flattenNode(out, root, "", sep, true)
+	return append(out, '}'), nil
+}
+
+// flattenNode appends the leaves under n, found at prefix, as members
+func flattenNode(out []byte, n *jsonNode, prefix, sep string, first bool) []byte {
+	switch {
+	case n.kind == tokObjectStart && len(n.keys) > 0:
+		for _, key := range n.keys {
+			path := key
+			if prefix != "" {
+				path = prefix + sep + key
+			}
+			out = flattenNode(out, n.members[key], path, sep, first)
+			first = false
+		}
+		return out
+	case n.kind == tokArrayStart && len(n.elems) > 0:
+		for i, elem := range n.elems {
+			out = flattenNode(out, elem, prefix+"["+strconv.Itoa(i)+"]", sep, first)
+			first = false
+		}
+		return out
+	}
+	if !first {
+		out = append(out, ',')
+	}
+	out = appendCanonicalString(out, prefix)
+	out = append(out, ':')
+	return n.appendTo(out)
+}
+
+// Unflatten reverses Flatten: it expands the members of a single-level
+// object, whose keys are paths in the syntax Flatten produces, into a
+// minified nested document. Missing array elements become null. Keys that
+// disagree about the type of a value, such as "a" and "a.b", return an error.
+func Unflatten(input []byte, sep string) ([]byte, error) {
+	flat, err := parseTree(input)
+	if err != nil {
+		return nil, err
+	}
+	if flat.kind != tokObjectStart {
+		return nil, fmt.Errorf("flattened document must be an object")
+	}
+	var root *jsonNode
+	for _, key := range flat.keys {
+		if root, err = unflattenInsert(root, splitFlatKey(key, sep), flat.members[key]); err != nil {
+			return nil, fmt.Errorf("key %q: %w", key, err)
+		}
+	}
+	if root == nil {
+		return []byte("{}"), nil
+	}
+	return root.appendTo(make([]byte, 0, len(input))), nil
+}
+
+// missingElement fills the array elements that Unflatten finds no key for
+var missingElement = &jsonNode{kind: tokNull, raw: []byte("null")}
+
+// flatSegment is an object key or array index in a flattened key
+type flatSegment struct {
+	key   string
+	index int // array index, if array is set
+	array bool
+}
+
+// splitFlatKey splits a flattened key into its segments
+func splitFlatKey(key, sep string) []flatSegment {
+	if key == "" {
+		return nil
+	}
+	parts := []string{key}
+	if sep != "" {
+		parts = strings.Split(key, sep)
+	}
+	var segments []flatSegment
+	for i, part := range parts {
+		var indexes []flatSegment
+		for strings.HasSuffix(part, "]") {
+			open := strings.LastIndexByte(part, '[')
+			if open < 0 {
+				break
+			}
+			n, ok := parseIndex(part[open+1 : len(part)-1])
+			if !ok {
+				break
+			}
+			indexes = append([]flatSegment{{index: n, array: true}}, indexes...)
+			part = part[:open]
+		}
+		if part != "" || i > 0 || len(indexes) == 0 {
+			segments = append(segments, flatSegment{key: part})
+		}
+		segments = append(segments, indexes...)
+	}
+	return segments
+}
+
+// unflattenInsert stores value at the path given by segments under n, which
+// is nil if nothing is stored there yet, and returns the updated node
+func unflattenInsert(n *jsonNode, segments []flatSegment, value *jsonNode) (*jsonNode, error) {
+	if len(segments) == 0 {
+		if n != nil {
+			return nil, fmt.Errorf("value already set")
+		}
+		return value, nil
+	}
+	segment := segments[0]
+	if !segment.array {
+		if n == nil {
+			n = &jsonNode{kind: tokObjectStart, members: make(map[string]*jsonNode)}
+		}
+		if n.kind != tokObjectStart {
+			return nil, fmt.Errorf("%q is not an object", segment.key)
+		}
+		child, err := unflattenInsert(n.members[segment.key], segments[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		n.set(segment.key, child)
+		return n, nil
+	}
+
+	if n == nil {
+		n = &jsonNode{kind: tokArrayStart}
+	}
+	if n.kind != tokArrayStart {
+		return nil, fmt.Errorf("index %d is not in an array", segment.index)
+	}
+	for len(n.elems) <= segment.index {
+		n.elems = append(n.elems, missingElement)
+	}
+	child := n.elems[segment.index]
+	if child == missingElement {
+		child = nil
+	}
+	child, err := unflattenInsert(child, segments[1:], value)
+	if err != nil {
+		return nil, err
+	}
+	n.elems[segment.index] = child
+	return n, nil
+}
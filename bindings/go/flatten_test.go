@@ -0,0 +1,71 @@
+package zmin
+
+import (
+	"testing"
+)
+
+func TestFlatten(t *testing.T) {
+	input := `{
+		"user": {"name": "a", "tags": ["x", "y"], "address": {"zip": "123"}},
+		"items": [{"id": 1}, [true, null]],
+		"empty": {},
+		"none": []
+	}`
+	output, err := Flatten([]byte(input), ".")
+	if err != nil {
+		t.Fatalf("Flatten failed: %v", err)
+	}
+	expected := `{"user.name":"a","user.tags[0]":"x","user.tags[1]":"y","user.address.zip":"123",` +
+		`"items[0].id":1,"items[1][0]":true,"items[1][1]":null,"empty":{},"none":[]}`
+	if string(output) != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+
+	restored, err := Unflatten(output, ".")
+	if err != nil {
+		t.Fatalf("Unflatten failed: %v", err)
+	}
+	if equal, err := Equal(restored, []byte(input)); err != nil || !equal {
+		t.Errorf("Expected the input back, got %s", restored)
+	}
+
+	cases := map[string]string{
+		`[1, {"a": 2}]`: `{"[0]":1,"[1]/a":2}`,
+		`"scalar"`:      `{"":"scalar"}`,
+		`{}`:            `{}`,
+		`[]`:            `{"":[]}`,
+	}
+	for input, expected := range cases {
+		output, err := Flatten([]byte(input), "/")
+		if err != nil {
+			t.Fatalf("Flatten(%s) failed: %v", input, err)
+		}
+		if string(output) != expected {
+			t.Errorf("Flatten(%s): expected %q, got %q", input, expected, output)
+		}
+		restored, err := Unflatten(output, "/")
+		if err != nil {
+			t.Fatalf("Unflatten(%s) failed: %v", output, err)
+		}
+		if equal, _ := Equal(restored, []byte(input)); !equal {
+			t.Errorf("Unflatten(%s): expected %s, got %s", output, input, restored)
+		}
+	}
+}
+
+func TestUnflatten(t *testing.T) {
+	output, err := Unflatten([]byte(`{"a_b":1,"a_c[2]":"x","d":"y"}`), "_")
+	if err != nil {
+		t.Fatalf("Unflatten failed: %v", err)
+	}
+	expected := `{"a":{"b":1,"c":[null,null,"x"]},"d":"y"}`
+	if string(output) != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+
+	for _, input := range []string{`{"a":1,"a.b":2}`, `{"a.b":1,"a":2}`, `{"a[0]":1,"a.b":2}`, `[1]`} {
+		if _, err := Unflatten([]byte(input), "."); err == nil {
+			t.Errorf("Unflatten(%s): expected an error", input)
+		}
+	}
+}
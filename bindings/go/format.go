@@ -0,0 +1,168 @@
+package zmin
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// FormatOptions controls the layout produced by Format
+type FormatOptions struct {
+	// UseTabs indents with tabs instead of spaces
+	UseTabs bool
+
+	// IndentWidth is the number of spaces, or tabs, per nesting level.
+	// Defaults to 2 for spaces and 1 for tabs.
+	IndentWidth int
+
+	// TrailingNewline terminates the output with a newline
+	TrailingNewline bool
+
+	// AlignKeys pads the keys of each object so that its values line up
+	AlignKeys bool
+}
+
+// Format pretty-prints JSON data. Each element of an object or array is
+// placed on its own line, and empty objects and arrays stay on one line.
+func Format(input interface{}, opts FormatOptions) (string, error) {
+	jsonStr, err := toJSONString(input)
+	if err != nil {
+		return "", err
+	}
+
+	f := &formatter{s: newScanner([]byte(jsonStr)), opts: opts}
+	width := opts.IndentWidth
+	if width <= 0 {
+		width = 2
+		if opts.UseTabs {
+			width = 1
+		}
+	}
+	if opts.UseTabs {
+		f.indent = strings.Repeat("\t", width)
+	} else {
+		f.indent = strings.Repeat(" ", width)
+	}
+
+	tok, err := f.s.next()
+	if err != nil {
+		return "", err
+	}
+	out, err := f.value(nil, tok, 0)
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.s.next(); err != nil {
+		return "", err
+	}
+	if opts.TrailingNewline {
+		out = append(out, '\n')
+	}
+	return string(out), nil
+}
+
+// formatter pretty-prints the token stream of a scanner
+type formatter struct {
+	s      *scanner
+	opts   FormatOptions
+	indent string
+}
+
+// member is a formatted object member
+type member struct {
+	key   []byte
+	value []byte
+}
+
+// value appends the formatted value that tok begins to dst
+func (f *formatter) value(dst []byte, tok token, depth int) ([]byte, error) {
+	switch tok.kind {
+	case tokObjectStart:
+		return f.object(dst, depth)
+	case tokArrayStart:
+		return f.array(dst, depth)
+	default:
+		return append(dst, tok.raw...), nil
+	}
+}
+
+func (f *formatter) object(dst []byte, depth int) ([]byte, error) {
+	// Members are collected first so that keys can be aligned
+	var members []member
+	keyWidth := 0
+	for {
+		tok, err := f.s.next()
+		if err != nil {
+			return nil, err
+		}
+		if tok.kind == tokObjectEnd {
+			break
+		}
+		valueTok, err := f.s.next()
+		if err != nil {
+			return nil, err
+		}
+		value, err := f.value(nil, valueTok, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member{key: tok.raw, value: value})
+		if w := utf8.RuneCount(tok.raw); w > keyWidth {
+			keyWidth = w
+		}
+	}
+
+	dst = append(dst, '{')
+	for i, m := range members {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = f.newline(dst, depth+1)
+		dst = append(dst, m.key...)
+		dst = append(dst, ':', ' ')
+		if f.opts.AlignKeys {
+			for pad := keyWidth - utf8.RuneCount(m.key); pad > 0; pad-- {
+				dst = append(dst, ' ')
+			}
+		}
+		dst = append(dst, m.value...)
+	}
+	if len(members) > 0 {
+		dst = f.newline(dst, depth)
+	}
+	return append(dst, '}'), nil
+}
+
+func (f *formatter) array(dst []byte, depth int) ([]byte, error) {
+	dst = append(dst, '[')
+	empty := true
+	for {
+		tok, err := f.s.next()
+		if err != nil {
+			return nil, err
+		}
+		if tok.kind == tokArrayEnd {
+			break
+		}
+		if !empty {
+			dst = append(dst, ',')
+		}
+		empty = false
+		dst = f.newline(dst, depth+1)
+		if dst, err = f.value(dst, tok, depth+1); err != nil {
+			return nil, err
+		}
+	}
+	if !empty {
+		dst = f.newline(dst, depth)
+	}
+	return append(dst, ']'), nil
+}
+
+// newline starts a new line indented for depth
+func (f *formatter) newline(dst []byte, depth int) []byte {
+	dst = append(dst, '\n')
+	for i := 0; i < depth; i++ {
+		dst = append(dst, f.indent...)
+	}
+	return dst
+}
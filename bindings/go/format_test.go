@@ -0,0 +1,40 @@
+package zmin
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	input := `{"name":"John","age":30,"tags":["a",{}],"empty":[]}`
+
+	output, err := Format(input, FormatOptions{})
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	expected := "{\n  \"name\": \"John\",\n  \"age\": 30,\n  \"tags\": [\n    \"a\",\n    {}\n  ],\n  \"empty\": []\n}"
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+
+	output, err = Format(input, FormatOptions{UseTabs: true, TrailingNewline: true, AlignKeys: true})
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	expected = "{\n\t\"name\":  \"John\",\n\t\"age\":   30,\n\t\"tags\":  [\n\t\t\"a\",\n\t\t{}\n\t],\n\t\"empty\": []\n}\n"
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+
+	output, err = Format([]int{1, 2}, FormatOptions{IndentWidth: 4})
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	if output != "[\n    1,\n    2\n]" {
+		t.Errorf("Unexpected output %q", output)
+	}
+
+	if _, err := Format(`{"a": [}`, FormatOptions{}); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON, got %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package zmin
+
+import (
+	"io/fs"
+	"path"
+)
+
+// MinifyFS minifies the JSON file name read from fsys, such as an embed.FS,
+// a zip.Reader or an fstest.MapFS, without touching the OS filesystem
+func MinifyFS(fsys fs.FS, name string, mode ProcessingMode) ([]byte, error) {
+	input, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return MinifyBytes(input, mode)
+}
+
+// MinifyFSDir minifies every regular file under root in fsys whose base name
+// matches one of patterns, which use the syntax of path.Match and default to
+// "*.json". It returns the outputs keyed by their path in fsys. The first
+// file that fails stops the walk and is reported as a *FileError.
+func MinifyFSDir(fsys fs.FS, root string, mode ProcessingMode, patterns ...string) (map[string][]byte, error) {
+	if len(patterns) == 0 {
+		patterns = []string{"*.json"}
+	}
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	outputs := make(map[string][]byte)
+	err := fs.WalkDir(fsys, root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() || !matchAnyPath(patterns, d.Name()) {
+			return nil
+		}
+		output, err := MinifyFS(fsys, name, mode)
+		if err != nil {
+			return &FileError{Path: name, Err: err}
+		}
+		outputs[name] = output
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return outputs, nil
+}
+
+// matchAnyPath is matchAny for slash-separated names
+func matchAnyPath(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
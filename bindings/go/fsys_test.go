@@ -0,0 +1,53 @@
+package zmin
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMinifyFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"fixtures/a.json":     {Data: []byte(`{ "a" : 1 }`)},
+		"fixtures/sub/b.json": {Data: []byte(`[ 1, 2 ]`)},
+		"fixtures/c.txt":      {Data: []byte(`{ "c" : 3 }`)},
+		"broken/bad.json":     {Data: []byte(`{"a": }`)},
+	}
+
+	output, err := MinifyFS(fsys, "fixtures/a.json", SPORT)
+	if err != nil {
+		t.Fatalf("MinifyFS failed: %v", err)
+	}
+	if string(output) != `{"a":1}` {
+		t.Errorf("Expected %q, got %q", `{"a":1}`, output)
+	}
+	if _, err := MinifyFS(fsys, "missing.json", SPORT); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected fs.ErrNotExist, got %v", err)
+	}
+
+	outputs, err := MinifyFSDir(fsys, "fixtures", SPORT)
+	if err != nil {
+		t.Fatalf("MinifyFSDir failed: %v", err)
+	}
+	expected := map[string]string{"fixtures/a.json": `{"a":1}`, "fixtures/sub/b.json": `[1,2]`}
+	if len(outputs) != len(expected) {
+		t.Errorf("Expected %d outputs, got %d", len(expected), len(outputs))
+	}
+	for name, want := range expected {
+		if string(outputs[name]) != want {
+			t.Errorf("%s: expected %q, got %q", name, want, outputs[name])
+		}
+	}
+
+	outputs, err = MinifyFSDir(fsys, ".", SPORT, "*.txt")
+	if err != nil || string(outputs["fixtures/c.txt"]) != `{"c":3}` || len(outputs) != 1 {
+		t.Errorf("Expected only fixtures/c.txt, got %q, %v", outputs, err)
+	}
+
+	var fileErr *FileError
+	if _, err := MinifyFSDir(fsys, "broken", SPORT); !errors.As(err, &fileErr) || fileErr.Path != "broken/bad.json" ||
+		!errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected a FileError for broken/bad.json, got %v", err)
+	}
+}
@@ -0,0 +1,217 @@
+package zmin
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// fileOptions configures where MinifyGlob writes its output
+type fileOptions struct {
+	outputDir string
+	suffix    string
+	overwrite bool
+}
+
+// WithOutputDir makes MinifyGlob write its output under dir, mirroring the
+// layout below the static part of the pattern, instead of next to the input
+func WithOutputDir(dir string) Option {
+	return func(m *Minifier) {
+		m.files.outputDir = dir
+	}
+}
+
+// WithSuffix makes MinifyGlob write the output of "name.json" to
+// "name"+suffix, for example "name.min.json", instead of replacing the input.
+// Inputs that already end in suffix are skipped.
+func WithSuffix(suffix string) Option {
+	return func(m *Minifier) {
+		m.files.suffix = suffix
+	}
+}
+
+// WithOverwrite lets MinifyGlob replace existing output files, which it
+// otherwise reports as errors wrapping fs.ErrExist
+func WithOverwrite() Option {
+	return func(m *Minifier) {
+		m.files.overwrite = true
+	}
+}
+
+// MinifyGlob minifies every regular file matching pattern, which uses the
+// syntax of path.Match extended with "**" segments that match any number of
+// directories, as in "dist/**/*.json". Patterns use forward slashes on every
+// platform; on Windows, backslashes also separate segments and a pattern may
+// start with a drive or UNC share.
+//
+// Without WithOutputDir or WithSuffix, files are minified in place. Files are
+// processed concurrently and written atomically; failures are reported per
+// file in DirStats.Errors, as with MinifyDir.
+func MinifyGlob(pattern string, opts ...Option) (DirStats, error) {
+	files := configOf(opts).files
+	var outputDir string
+	if files.outputDir != "" {
+		var err error
+		if outputDir, err = filepath.Abs(files.outputDir); err != nil {
+			return DirStats{}, err
+		}
+	}
+	g, err := parseGlob(pattern)
+	if err != nil {
+		return DirStats{}, err
+	}
+
+	return minifyFiles(context.Background(), 0, opts, files.overwrite, func(send func(fileJob) error, fail func(string, error)) error {
+		return g.walk(outputDir, fail, func(p, rel string, d fs.DirEntry) error {
+			if files.suffix != "" && strings.HasSuffix(p, files.suffix) {
+				return nil
+			}
+
+			target := p
+			if files.outputDir != "" {
+				target = filepath.Join(files.outputDir, filepath.FromSlash(rel))
+			}
+			if files.suffix != "" {
+				target = strings.TrimSuffix(target, filepath.Ext(target)) + files.suffix
+			}
+			info, err := d.Info()
+			if err != nil {
+				fail(p, err)
+				return nil
+			}
+			return send(fileJob{path: p, target: target, size: info.Size()})
+		})
+	})
+}
+
+// Glob returns the regular files matching pattern, in lexical order, with the
+// syntax of MinifyGlob. Unlike filepath.Glob, "**" matches any number of
+// directories. Directories that cannot be read are skipped, as filepath.Glob
+// skips them.
+func Glob(pattern string) ([]string, error) {
+	g, err := parseGlob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	err = g.walk("", func(string, error) {}, func(p, _ string, _ fs.DirEntry) error {
+		files = append(files, p)
+		return nil
+	})
+	return files, err
+}
+
+// glob is a parsed pattern: the directory it starts from and the segments
+// below it
+type glob struct {
+	base      string
+	segments  []string
+	recursive bool // a segment is "**"
+}
+
+// parseGlob parses and validates pattern
+func parseGlob(pattern string) (glob, error) {
+	base, rest := splitGlob(pattern)
+	g := glob{base: base, segments: strings.Split(rest, "/")}
+	for _, segment := range g.segments {
+		if segment == "**" {
+			g.recursive = true
+			continue
+		}
+		if _, err := path.Match(segment, ""); err != nil {
+			return glob{}, err
+		}
+	}
+	return g, nil
+}
+
+// walk calls fn for every regular file matching g, with its slash-separated
+// path relative to g.base, skipping the directory skip, which is absolute.
+// Errors below g.base are passed to fail.
+func (g glob) walk(skip string, fail func(string, error), fn func(p, rel string, d fs.DirEntry) error) error {
+	err := filepath.WalkDir(g.base, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == g.base {
+				return err
+			}
+			fail(p, err)
+			return nil
+		}
+		rel, err := filepath.Rel(g.base, p)
+		if err != nil {
+			fail(p, err)
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if rel == "." {
+				return nil
+			}
+			// Without "**", nothing deeper than the pattern can match
+			if !g.recursive && strings.Count(rel, "/")+1 >= len(g.segments) {
+				return filepath.SkipDir
+			}
+			if skip != "" {
+				if abs, err := filepath.Abs(p); err == nil && abs == skip {
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !matchGlob(g.segments, strings.Split(rel, "/")) {
+			return nil
+		}
+		return fn(p, rel, d)
+	})
+	// A pattern whose static part does not exist matches nothing
+	if p, ok := err.(*fs.PathError); ok && p.Path == g.base && errors.Is(p.Err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// splitGlob splits pattern into the directory before its first segment with
+// wildcards and the slash-separated remainder. A Windows volume, such as "C:"
+// or a UNC share, stays part of the directory.
+func splitGlob(pattern string) (base, rest string) {
+	volume := filepath.VolumeName(pattern)
+	segments := strings.Split(filepath.ToSlash(pattern[len(volume):]), "/")
+	i := 0
+	for i < len(segments)-1 && !strings.ContainsAny(segments[i], `*?[\`) {
+		i++
+	}
+	base = strings.Join(segments[:i], "/")
+	switch {
+	case i == 0:
+		base = "."
+	case base == "":
+		base = "/"
+	}
+	return volume + filepath.FromSlash(base), strings.Join(segments[i:], "/")
+}
+
+// matchGlob reports whether the segments of a path match those of a pattern,
+// where a "**" segment matches zero or more path segments
+func matchGlob(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchGlob(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
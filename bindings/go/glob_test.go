@@ -0,0 +1,143 @@
+package zmin
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		expected      bool
+	}{
+		{"*.json", "a.json", true},
+		{"*.json", "sub/a.json", false},
+		{"**/*.json", "a.json", true},
+		{"**/*.json", "sub/deep/a.json", true},
+		{"sub/**/*.json", "sub/a.json", true},
+		{"sub/**/*.json", "other/a.json", false},
+		{"**/data/*.json", "x/y/data/a.json", true},
+		{"**/data/*.json", "x/y/data/z/a.json", false},
+		{"a/**", "a/b/c", true},
+		{"?.json", "ab.json", false},
+	}
+	for _, tt := range tests {
+		if got := matchGlob(strings.Split(tt.pattern, "/"), strings.Split(tt.name, "/")); got != tt.expected {
+			t.Errorf("matchGlob(%q, %q) = %v, expected %v", tt.pattern, tt.name, got, tt.expected)
+		}
+	}
+
+	base, rest := splitGlob("dist/assets/**/*.json")
+	if base != filepath.FromSlash("dist/assets") || rest != "**/*.json" {
+		t.Errorf("Expected dist/assets and **/*.json, got %q and %q", base, rest)
+	}
+	if runtime.GOOS == "windows" {
+		// The root of a drive is not its current directory
+		if base, rest := splitGlob(`C:\*.json`); base != `C:\` || rest != "*.json" {
+			t.Errorf(`Expected C:\ and *.json, got %q and %q`, base, rest)
+		}
+		if base, _ := splitGlob(`\\server\share\data\*.json`); base != `\\server\share\data` {
+			t.Errorf("Unexpected base %q for a UNC path", base)
+		}
+	}
+}
+
+func TestMinifyGlob(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"dist/a.json":         `{ "a" : 1 }`,
+		"dist/sub/b.json":     `[ 1, 2 ]`,
+		"dist/sub/c.txt":      `{ "c" : 3 }`,
+		"other/d.json":        `{ "d" : 4 }`,
+		"dist/sub/e.min.json": `[1]`,
+	})
+	pattern := filepath.ToSlash(root) + "/dist/**/*.json"
+
+	stats, err := MinifyGlob(pattern, WithSuffix(".min.json"))
+	if err != nil {
+		t.Fatalf("MinifyGlob failed: %v", err)
+	}
+	if stats.Files != 2 || len(stats.Errors) != 0 {
+		t.Errorf("Expected 2 files and no errors, got %d files and %v", stats.Files, stats.Errors)
+	}
+	if got := readFile(t, root, "dist/sub/b.min.json"); got != `[1,2]` {
+		t.Errorf("Expected %q, got %q", `[1,2]`, got)
+	}
+	if got := readFile(t, root, "dist/a.json"); got != `{ "a" : 1 }` {
+		t.Errorf("Expected the input to be untouched, got %q", got)
+	}
+
+	// Existing outputs are only replaced with WithOverwrite
+	stats, err = MinifyGlob(pattern, WithSuffix(".min.json"))
+	if err != nil {
+		t.Fatalf("MinifyGlob failed: %v", err)
+	}
+	if len(stats.Errors) != 2 || !errors.Is(stats.Errors[0], fs.ErrExist) {
+		t.Errorf("Expected 2 fs.ErrExist errors, got %v", stats.Errors)
+	}
+	stats, err = MinifyGlob(pattern, WithSuffix(".min.json"), WithOverwrite())
+	if err != nil || stats.Files != 2 || len(stats.Errors) != 0 {
+		t.Errorf("Expected 2 files replaced, got %d files, %v, %v", stats.Files, stats.Errors, err)
+	}
+
+	out := filepath.Join(root, "out")
+	if _, err := MinifyGlob(pattern, WithOutputDir(out)); err != nil {
+		t.Fatalf("MinifyGlob failed: %v", err)
+	}
+	if got := readFile(t, out, "sub/b.json"); got != `[1,2]` {
+		t.Errorf("Expected %q, got %q", `[1,2]`, got)
+	}
+
+	// In place, without "**"
+	stats, err = MinifyGlob(filepath.ToSlash(root) + "/other/*.json")
+	if err != nil || stats.Files != 1 {
+		t.Fatalf("Expected 1 file, got %d, %v", stats.Files, err)
+	}
+	if got := readFile(t, root, "other/d.json"); got != `{"d":4}` {
+		t.Errorf("Expected %q, got %q", `{"d":4}`, got)
+	}
+
+	if stats, err := MinifyGlob(filepath.ToSlash(root) + "/missing/*.json"); err != nil || stats.Files != 0 {
+		t.Errorf("Expected no matches, got %d files, %v", stats.Files, err)
+	}
+	if _, err := MinifyGlob(filepath.ToSlash(root) + "/[.json"); err == nil {
+		t.Errorf("Expected a bad pattern error")
+	}
+}
+
+func TestGlob(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"a.json":          `1`,
+		"sub/b.json":      `2`,
+		"sub/deep/c.json": `3`,
+		"sub/d.txt":       `4`,
+	})
+	files, err := Glob(filepath.ToSlash(root) + "/**/*.json")
+	if err != nil {
+		t.Fatalf("Glob failed: %v", err)
+	}
+	expected := []string{"a.json", "sub/b.json", "sub/deep/c.json"}
+	if len(files) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, files)
+	}
+	for i, file := range files {
+		if file != filepath.Join(root, filepath.FromSlash(expected[i])) {
+			t.Errorf("Expected %s, got %s", expected[i], file)
+		}
+	}
+
+	if files, err := Glob(filepath.ToSlash(root) + "/sub/*.json"); err != nil || len(files) != 1 {
+		t.Errorf("Expected 1 file without recursion, got %v, %v", files, err)
+	}
+	if files, err := Glob(filepath.ToSlash(root) + "/missing/*.json"); err != nil || len(files) != 0 {
+		t.Errorf("Expected no matches, got %v, %v", files, err)
+	}
+	if _, err := Glob(filepath.ToSlash(root) + "/[.json"); err == nil {
+		t.Error("Expected a bad pattern error")
+	}
+}
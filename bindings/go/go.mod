@@ -1,3 +1,14 @@
 module github.com/hydepwns/zmin/go
 
-go 1.19
\ No newline at end of file
+go 1.21
+
+require (
+	github.com/BurntSushi/toml v1.4.0
+	github.com/ebitengine/purego v0.8.1
+	github.com/fsnotify/fsnotify v1.7.0
+	github.com/klauspost/compress v1.17.9
+	github.com/tetratelabs/wazero v1.8.1
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require golang.org/x/sys v0.13.0 // indirect
@@ -0,0 +1,97 @@
+package zmin
+
+import "bytes"
+
+// goBackend minifies in pure Go, with the scanner used to locate syntax
+// errors and a compactor that copies strings whole
+type goBackend struct{}
+
+func (goBackend) Minify(input []byte, mode ProcessingMode) ([]byte, error) {
+	if mode < ECO || mode > AUTO {
+		return nil, ErrInvalidMode
+	}
+	if err := scanDocument(input); err != nil {
+		return nil, err
+	}
+	output := make([]byte, len(input))
+	return output[:compact(output, input)], nil
+}
+
+func (goBackend) Validate(input []byte) error {
+	return scanDocument(input)
+}
+
+func (goBackend) Version() string {
+	return BindingVersion
+}
+
+func (goBackend) Capabilities() Capabilities {
+	return Capabilities{
+		Version:      BindingVersion,
+		Backend:      "go",
+		Modes:        []ProcessingMode{ECO, SPORT, TURBO, AUTO},
+		MaxInputSize: int64(largeInputSize),
+	}
+}
+
+// goMinifyInto minifies src into dst in pure Go and returns the output size
+// and error code, as zmin_minify_into does
+func goMinifyInto(dst, src []byte) (int, int) {
+	if scanDocument(src) != nil {
+		return 0, -1
+	}
+	n := compact(dst, src)
+	if n > len(dst) {
+		return n, -4
+	}
+	return n, 0
+}
+
+// compact writes src, which must be valid JSON, to dst without insignificant
+// whitespace and returns the size of the output, which may exceed len(dst).
+// Strings are copied whole, locating their closing quote with
+// bytes.IndexByte.
+func compact(dst, src []byte) int {
+	n := 0
+	emit := func(b []byte) {
+		if n < len(dst) {
+			copy(dst[n:], b)
+		}
+		n += len(b)
+	}
+	for i := 0; i < len(src); {
+		switch c := src[i]; {
+		case isSpace(c):
+			i++
+		case c == '"':
+			end := i + 1
+			for {
+				end += bytes.IndexByte(src[end:], '"')
+				if !escaped(src, end) {
+					break
+				}
+				end++
+			}
+			emit(src[i : end+1])
+			i = end + 1
+		default:
+			// Copy the run of structural characters and literals
+			start := i
+			for i < len(src) && !isSpace(src[i]) && src[i] != '"' {
+				i++
+			}
+			emit(src[start:i])
+		}
+	}
+	return n
+}
+
+// escaped reports whether the quote at src[i] is preceded by an odd number of
+// backslashes
+func escaped(src []byte, i int) bool {
+	backslashes := 0
+	for i--; i >= 0 && src[i] == '\\'; i-- {
+		backslashes++
+	}
+	return backslashes%2 == 1
+}
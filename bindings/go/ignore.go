@@ -0,0 +1,105 @@
+package zmin
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreFile is the name of the file listing paths MinifyDir skips
+const ignoreFile = ".zminignore"
+
+// ignoreRule is one line of an ignore file in gitignore syntax
+type ignoreRule struct {
+	segments []string // pattern split at '/'
+	negate   bool     // "!pattern" re-includes a path
+	dirOnly  bool     // "pattern/" matches directories only
+	anchored bool     // matched against the whole path rather than the base name
+}
+
+// ignoreList is an ordered list of rules; the last matching rule wins
+type ignoreList []ignoreRule
+
+// parseIgnore parses lines in gitignore syntax, skipping blank lines and
+// comments
+func parseIgnore(lines []string) (ignoreList, error) {
+	var list ignoreList
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		var r ignoreRule
+		if line[0] == '!' {
+			r.negate = true
+			line = line[1:]
+		} else if line[0] == '\\' && len(line) > 1 && (line[1] == '#' || line[1] == '!') {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			r.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			r.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		r.segments = strings.Split(line, "/")
+		for _, segment := range r.segments {
+			if _, err := path.Match(segment, ""); err != nil {
+				return nil, err
+			}
+		}
+		list = append(list, r)
+	}
+	return list, nil
+}
+
+// readIgnoreFile parses the ignore file in dir, if there is one
+func readIgnoreFile(dir string) (ignoreList, error) {
+	f, err := os.Open(filepath.Join(dir, ignoreFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return parseIgnore(lines)
+}
+
+// ignored reports whether the slash-separated path rel, relative to the
+// directory of the rules, is ignored
+func (l ignoreList) ignored(rel string, dir bool) bool {
+	names := strings.Split(rel, "/")
+	ignored := false
+	for _, r := range l {
+		if r.dirOnly && !dir {
+			continue
+		}
+		matched := false
+		if r.anchored {
+			matched = matchGlob(r.segments, names)
+		} else {
+			matched = matchGlob(r.segments, names[len(names)-1:])
+		}
+		if matched {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
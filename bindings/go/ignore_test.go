@@ -0,0 +1,81 @@
+package zmin
+
+import (
+	"context"
+	"testing"
+)
+
+func TestIgnoreList(t *testing.T) {
+	list, err := parseIgnore([]string{
+		"# generated files",
+		"",
+		"*.gen.json",
+		"vendor/",
+		"/fixtures/*.json",
+		"!fixtures/keep.json",
+		"**/cache/**",
+		`\#literal.json`,
+	})
+	if err != nil {
+		t.Fatalf("parseIgnore failed: %v", err)
+	}
+
+	tests := []struct {
+		path     string
+		dir      bool
+		expected bool
+	}{
+		{"a.gen.json", false, true},
+		{"deep/b.gen.json", false, true},
+		{"a.json", false, false},
+		{"vendor", true, true},
+		{"src/vendor", true, true},
+		{"vendor", false, false}, // a file, not a directory
+		{"fixtures/a.json", false, true},
+		{"src/fixtures/a.json", false, false}, // anchored to the root
+		{"fixtures/keep.json", false, false},
+		{"x/cache/y.json", false, true},
+		{"#literal.json", false, true},
+	}
+	for _, tt := range tests {
+		if got := list.ignored(tt.path, tt.dir); got != tt.expected {
+			t.Errorf("ignored(%q, %v) = %v, expected %v", tt.path, tt.dir, got, tt.expected)
+		}
+	}
+
+	if _, err := parseIgnore([]string{"[abc"}); err == nil {
+		t.Errorf("Expected a bad pattern error")
+	}
+}
+
+func TestMinifyDirIgnore(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		".zminignore":       "# skip generated output\nvendor/\n*.gen.json\n",
+		"a.json":            `{ "a" : 1 }`,
+		"b.gen.json":        `{ "b" : 2 }`,
+		"vendor/lib/c.json": `{ "c" : 3 }`,
+		"testdata/d.json":   `{ "d" : 4 }`,
+		"testdata/e.json":   `{ "e" : 5 }`,
+	})
+
+	stats, err := MinifyDir(context.Background(), root, DirOptions{
+		Exclude: []string{"testdata/", "!testdata/e.json"},
+	})
+	if err != nil {
+		t.Fatalf("MinifyDir failed: %v", err)
+	}
+	if stats.Files != 1 {
+		t.Errorf("Expected 1 file, got %d", stats.Files)
+	}
+	for name, expected := range map[string]string{
+		"a.json":            `{"a":1}`,
+		"b.gen.json":        `{ "b" : 2 }`,
+		"vendor/lib/c.json": `{ "c" : 3 }`,
+		"testdata/e.json":   `{ "e" : 5 }`, // its directory is excluded
+	} {
+		if got := readFile(t, root, name); got != expected {
+			t.Errorf("%s: expected %q, got %q", name, expected, got)
+		}
+	}
+}
@@ -0,0 +1,355 @@
+package zmin
+
+import (
+	"fmt"
+)
+
+// scanOp is the classification of a byte by the incremental scanner
+type scanOp int
+
+const (
+	scanEmit     scanOp = iota // significant byte, part of the minified output
+	scanSkip                   // insignificant whitespace
+	scanError                  // syntax error, see incrementalScanner.err
+	scanNewValue               // significant byte starting a further top-level value
+)
+
+// parse stack entries of the incremental scanner
+const (
+	parseObjectKey   = iota // parsing an object key, before ':'
+	parseObjectValue        // parsing an object value, after ':'
+	parseArrayValue         // parsing an array element
+)
+
+// incrementalScanner validates JSON one byte at a time, so that documents can
+// be processed in chunks of any size without holding them in memory. Each
+// byte is classified as significant or insignificant whitespace, which is all
+// a streaming minifier needs.
+type incrementalScanner struct {
+	step   func(*incrementalScanner, byte) scanOp
+	parse  []int
+	offset int64 // offset of the byte being stepped
+	line   int   // line of the byte being stepped
+	column int   // column of the byte being stepped
+	err    error
+	done   bool // a complete top-level value has been scanned
+	multi  bool // accept a sequence of top-level values
+	values int  // number of complete top-level values
+
+	literal string // remaining bytes of true, false or null
+	hex     int    // remaining hex digits of a \u escape
+}
+
+// reset prepares the scanner for a new document
+func (s *incrementalScanner) reset() {
+	s.step = (*incrementalScanner).stateBeginValue
+	s.parse = s.parse[:0]
+	s.offset = 0
+	s.line = 1
+	s.column = 1
+	s.err = nil
+	s.done = false
+	s.multi = false
+	s.values = 0
+}
+
+// resetMulti prepares the scanner for a stream of whitespace-separated
+// top-level values. An empty stream is valid.
+func (s *incrementalScanner) resetMulti() {
+	s.reset()
+	s.multi = true
+	s.done = true
+	s.step = (*incrementalScanner).stateEndTop
+}
+
+// feed steps c through the scanner and advances the offset
+func (s *incrementalScanner) feed(c byte) scanOp {
+	if s.err != nil {
+		return scanError
+	}
+	op := s.step(s, c)
+	s.offset++
+	s.column++
+	if c == '\n' {
+		s.line++
+		s.column = 1
+	}
+	return op
+}
+
+// eof reports whether the input seen so far is a complete document
+func (s *incrementalScanner) eof() error {
+	if s.err != nil {
+		return s.err
+	}
+	// A trailing space terminates a pending number without changing the
+	// meaning of anything else
+	if s.step(s, ' ') == scanError {
+		return s.err
+	}
+	if !s.done {
+		s.err = &SyntaxError{Offset: s.offset, Line: s.line, Column: s.column, Message: "unexpected end of input"}
+		return s.err
+	}
+	return nil
+}
+
+// errorf records a syntax error at the current offset
+func (s *incrementalScanner) errorf(format string, args ...interface{}) scanOp {
+	s.step = (*incrementalScanner).stateError
+	s.err = &SyntaxError{Offset: s.offset, Line: s.line, Column: s.column, Message: fmt.Sprintf(format, args...)}
+	return scanError
+}
+
+func (s *incrementalScanner) stateError(c byte) scanOp {
+	return scanError
+}
+
+func (s *incrementalScanner) stateBeginValue(c byte) scanOp {
+	switch {
+	case isSpace(c):
+		return scanSkip
+	case c == '{':
+		s.parse = append(s.parse, parseObjectKey)
+		s.step = (*incrementalScanner).stateBeginKeyOrEmpty
+	case c == '[':
+		s.parse = append(s.parse, parseArrayValue)
+		s.step = (*incrementalScanner).stateBeginValueOrEmpty
+	case c == '"':
+		s.step = (*incrementalScanner).stateInString
+	case c == '-':
+		s.step = (*incrementalScanner).stateNeg
+	case c == '0':
+		s.step = (*incrementalScanner).state0
+	case c >= '1' && c <= '9':
+		s.step = (*incrementalScanner).state1
+	case c == 't':
+		s.literal = "rue"
+		s.step = (*incrementalScanner).stateLiteral
+	case c == 'f':
+		s.literal = "alse"
+		s.step = (*incrementalScanner).stateLiteral
+	case c == 'n':
+		s.literal = "ull"
+		s.step = (*incrementalScanner).stateLiteral
+	default:
+		return s.errorf("unexpected %s looking for beginning of value", quoteChar(c))
+	}
+	return scanEmit
+}
+
+func (s *incrementalScanner) stateBeginValueOrEmpty(c byte) scanOp {
+	if isSpace(c) {
+		return scanSkip
+	}
+	if c == ']' {
+		return s.stateEndValue(c)
+	}
+	return s.stateBeginValue(c)
+}
+
+func (s *incrementalScanner) stateBeginKeyOrEmpty(c byte) scanOp {
+	if isSpace(c) {
+		return scanSkip
+	}
+	if c == '}' {
+		s.parse[len(s.parse)-1] = parseObjectValue
+		return s.stateEndValue(c)
+	}
+	return s.stateBeginKey(c)
+}
+
+func (s *incrementalScanner) stateBeginKey(c byte) scanOp {
+	if isSpace(c) {
+		return scanSkip
+	}
+	if c != '"' {
+		return s.errorf("expected object key but found %s", quoteChar(c))
+	}
+	s.step = (*incrementalScanner).stateInString
+	return scanEmit
+}
+
+// stateEndValue runs after a value (or an object key) is complete
+func (s *incrementalScanner) stateEndValue(c byte) scanOp {
+	n := len(s.parse)
+	if n == 0 {
+		s.done = true
+		s.values++
+		s.step = (*incrementalScanner).stateEndTop
+		return s.stateEndTop(c)
+	}
+	if isSpace(c) {
+		s.step = (*incrementalScanner).stateEndValue
+		return scanSkip
+	}
+	switch s.parse[n-1] {
+	case parseObjectKey:
+		if c != ':' {
+			return s.errorf("expected ':' after object key but found %s", quoteChar(c))
+		}
+		s.parse[n-1] = parseObjectValue
+		s.step = (*incrementalScanner).stateBeginValue
+		return scanEmit
+	case parseObjectValue:
+		switch c {
+		case ',':
+			s.parse[n-1] = parseObjectKey
+			s.step = (*incrementalScanner).stateBeginKey
+			return scanEmit
+		case '}':
+			s.parse = s.parse[:n-1]
+			s.step = (*incrementalScanner).stateEndValue
+			return scanEmit
+		}
+		return s.errorf("expected ',' or '}' but found %s", quoteChar(c))
+	default:
+		switch c {
+		case ',':
+			s.step = (*incrementalScanner).stateBeginValue
+			return scanEmit
+		case ']':
+			s.parse = s.parse[:n-1]
+			s.step = (*incrementalScanner).stateEndValue
+			return scanEmit
+		}
+		return s.errorf("expected ',' or ']' but found %s", quoteChar(c))
+	}
+}
+
+func (s *incrementalScanner) stateEndTop(c byte) scanOp {
+	if isSpace(c) {
+		return scanSkip
+	}
+	if !s.multi {
+		return s.errorf("unexpected %s after top-level value", quoteChar(c))
+	}
+	s.done = false
+	op := s.stateBeginValue(c)
+	if op == scanEmit && s.values > 0 {
+		op = scanNewValue
+	}
+	return op
+}
+
+func (s *incrementalScanner) stateInString(c byte) scanOp {
+	switch {
+	case c == '"':
+		s.step = (*incrementalScanner).stateEndValue
+	case c == '\\':
+		s.step = (*incrementalScanner).stateInStringEsc
+	case c < 0x20:
+		return s.errorf("invalid control character %s in string", quoteChar(c))
+	}
+	return scanEmit
+}
+
+func (s *incrementalScanner) stateInStringEsc(c byte) scanOp {
+	switch c {
+	case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+		s.step = (*incrementalScanner).stateInString
+	case 'u':
+		s.hex = 4
+		s.step = (*incrementalScanner).stateInStringEscU
+	default:
+		return s.errorf("invalid escape character %s in string", quoteChar(c))
+	}
+	return scanEmit
+}
+
+func (s *incrementalScanner) stateInStringEscU(c byte) scanOp {
+	if !isHex(c) {
+		return s.errorf("invalid character %s in \\u escape", quoteChar(c))
+	}
+	if s.hex--; s.hex == 0 {
+		s.step = (*incrementalScanner).stateInString
+	}
+	return scanEmit
+}
+
+func (s *incrementalScanner) stateNeg(c byte) scanOp {
+	switch {
+	case c == '0':
+		s.step = (*incrementalScanner).state0
+	case c >= '1' && c <= '9':
+		s.step = (*incrementalScanner).state1
+	default:
+		return s.errorf("invalid number, expected digit")
+	}
+	return scanEmit
+}
+
+// state1 is in the integer part of a number that does not start with 0
+func (s *incrementalScanner) state1(c byte) scanOp {
+	if isDigit(c) {
+		return scanEmit
+	}
+	return s.state0(c)
+}
+
+// state0 is after the integer part of a number
+func (s *incrementalScanner) state0(c byte) scanOp {
+	switch c {
+	case '.':
+		s.step = (*incrementalScanner).stateDot
+		return scanEmit
+	case 'e', 'E':
+		s.step = (*incrementalScanner).stateE
+		return scanEmit
+	}
+	return s.stateEndValue(c)
+}
+
+func (s *incrementalScanner) stateDot(c byte) scanOp {
+	if !isDigit(c) {
+		return s.errorf("invalid number, expected digit after decimal point")
+	}
+	s.step = (*incrementalScanner).stateDot0
+	return scanEmit
+}
+
+// stateDot0 is in the fraction of a number
+func (s *incrementalScanner) stateDot0(c byte) scanOp {
+	switch {
+	case isDigit(c):
+		return scanEmit
+	case c == 'e' || c == 'E':
+		s.step = (*incrementalScanner).stateE
+		return scanEmit
+	}
+	return s.stateEndValue(c)
+}
+
+func (s *incrementalScanner) stateE(c byte) scanOp {
+	if c == '+' || c == '-' {
+		s.step = (*incrementalScanner).stateESign
+		return scanEmit
+	}
+	return s.stateESign(c)
+}
+
+func (s *incrementalScanner) stateESign(c byte) scanOp {
+	if !isDigit(c) {
+		return s.errorf("invalid number, expected digit in exponent")
+	}
+	s.step = (*incrementalScanner).stateE0
+	return scanEmit
+}
+
+// stateE0 is in the exponent of a number
+func (s *incrementalScanner) stateE0(c byte) scanOp {
+	if isDigit(c) {
+		return scanEmit
+	}
+	return s.stateEndValue(c)
+}
+
+func (s *incrementalScanner) stateLiteral(c byte) scanOp {
+	if c != s.literal[0] {
+		return s.errorf("invalid character %s in literal", quoteChar(c))
+	}
+	if s.literal = s.literal[1:]; s.literal == "" {
+		s.step = (*incrementalScanner).stateEndValue
+	}
+	return scanEmit
+}
@@ -0,0 +1,60 @@
+package zmin
+
+import (
+	"bytes"
+)
+
+// stripComments blanks out // and /* */ comments outside of strings. Comments
+// are replaced by spaces, keeping newlines, so that offsets, lines and columns
+// in later syntax errors still refer to the original input.
+func stripComments(input []byte) ([]byte, error) {
+	if bytes.IndexByte(input, '/') < 0 {
+		return input, nil
+	}
+
+	out := make([]byte, 0, len(input))
+	for i := 0; i < len(input); {
+		c := input[i]
+		switch {
+		case c == '"':
+			end := skipString(input, i)
+			out = append(out, input[i:end]...)
+			i = end
+		case c == '/' && i+1 < len(input) && input[i+1] == '/':
+			for ; i < len(input) && input[i] != '\n'; i++ {
+				out = append(out, ' ')
+			}
+		case c == '/' && i+1 < len(input) && input[i+1] == '*':
+			end := bytes.Index(input[i+2:], []byte("*/"))
+			if end < 0 {
+				return nil, newSyntaxError(input, i, "unterminated comment")
+			}
+			for end += i + 4; i < end; i++ {
+				if input[i] == '\n' {
+					out = append(out, '\n')
+				} else {
+					out = append(out, ' ')
+				}
+			}
+		default:
+			out = append(out, c)
+			i++
+		}
+	}
+	return out, nil
+}
+
+// skipString returns the offset just past the string that starts at start,
+// or the end of the input if the string is unterminated
+func skipString(input []byte, start int) int {
+	quote := input[start]
+	for i := start + 1; i < len(input); i++ {
+		switch input[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		}
+	}
+	return len(input)
+}
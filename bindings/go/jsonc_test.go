@@ -0,0 +1,41 @@
+package zmin
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMinifyWithOptionsAllowComments(t *testing.T) {
+	input := `{
+		// compiler settings
+		"target": "es2020", /* inline */ "url": "http://example.com/*not a comment*/",
+		"paths": [1/**/, 2] // trailing
+	}`
+
+	if _, err := MinifyWithOptions(input, SPORT, Options{}); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected comments to be rejected by default, got %v", err)
+	}
+
+	output, err := NewMinifier(WithAllowComments()).Minify(input)
+	if err != nil {
+		t.Fatalf("Minify failed: %v", err)
+	}
+	expected := `{"target":"es2020","url":"http://example.com/*not a comment*/","paths":[1,2]}`
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+
+	// A comment separates tokens rather than joining them
+	if _, err := MinifyWithOptions(`[1/**/2]`, SPORT, Options{AllowComments: true}); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON, got %v", err)
+	}
+
+	_, err = MinifyWithOptions("{\n/* open", SPORT, Options{AllowComments: true})
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("Expected *SyntaxError, got %v", err)
+	}
+	if syntaxErr.Line != 2 || syntaxErr.Message != "unterminated comment" {
+		t.Errorf("Unexpected error details: %+v", syntaxErr)
+	}
+}
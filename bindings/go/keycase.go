@@ -0,0 +1,53 @@
+package zmin
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SnakeCase converts a camelCase or PascalCase key to snake_case, keeping runs
+// of capitals together, so "userID" and "HTTPServer" become "user_id" and
+// "http_server". It ignores path and can be used as Options.KeyTransform.
+func SnakeCase(path, key string) string {
+	runes := []rune(key)
+	var b strings.Builder
+	b.Grow(len(key) + 4)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && runes[i-1] != '_' {
+				prev := runes[i-1]
+				nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+				if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+					b.WriteByte('_')
+				}
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// CamelCase converts a snake_case or kebab-case key to camelCase, so
+// "user_id" becomes "userId". Leading underscores are kept. It ignores path
+// and can be used as Options.KeyTransform.
+func CamelCase(path, key string) string {
+	var b strings.Builder
+	b.Grow(len(key))
+	word, upper := false, false
+	for _, r := range key {
+		if (r == '_' || r == '-') && word {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		if r != '_' && r != '-' {
+			word = true
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,81 @@
+package zmin
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKeyCase(t *testing.T) {
+	snake := map[string]string{
+		"userName":   "user_name",
+		"userID":     "user_id",
+		"HTTPServer": "http_server",
+		"already_ok": "already_ok",
+		"v2Api":      "v2_api",
+		"_private":   "_private",
+	}
+	for key, expected := range snake {
+		if got := SnakeCase("", key); got != expected {
+			t.Errorf("SnakeCase(%q): expected %q, got %q", key, expected, got)
+		}
+	}
+
+	camel := map[string]string{
+		"user_name":  "userName",
+		"user-id":    "userId",
+		"_private":   "_private",
+		"__a_b":      "__aB",
+		"alreadyOk":  "alreadyOk",
+		"trailing_":  "trailing",
+		"many__gaps": "manyGaps",
+	}
+	for key, expected := range camel {
+		if got := CamelCase("", key); got != expected {
+			t.Errorf("CamelCase(%q): expected %q, got %q", key, expected, got)
+		}
+	}
+}
+
+func TestMinifyWithOptionsKeyTransform(t *testing.T) {
+	input := `{"user_name": "a", "profile": {"created_at": 1, "tags": [{"tag_id": 2}]}}`
+	output, err := MinifyWithOptions(input, SPORT, Options{KeyTransform: CamelCase})
+	if err != nil {
+		t.Fatalf("MinifyWithOptions failed: %v", err)
+	}
+	expected := `{"userName":"a","profile":{"createdAt":1,"tags":[{"tagId":2}]}}`
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+
+	// The callback sees the path of the object, and other options use the
+	// original keys
+	var paths []string
+	m := NewMinifier(WithKeyTransform(func(path, key string) string {
+		paths = append(paths, path+"|"+key)
+		if path == "/profile" {
+			return strings.ToUpper(key)
+		}
+		return key
+	}), WithExcludePaths("profile.created_at"), WithSortKeys())
+	output, err = m.Minify(input)
+	if err != nil {
+		t.Fatalf("Minify failed: %v", err)
+	}
+	expected = `{"profile":{"TAGS":[{"tag_id":2}]},"user_name":"a"}`
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+	expectedPaths := "|user_name,|profile,/profile|tags,/profile/tags/0|tag_id"
+	if got := strings.Join(paths, ","); got != expectedPaths {
+		t.Errorf("Expected calls %q, got %q", expectedPaths, got)
+	}
+
+	// Keys that need escaping are escaped
+	output, err = MinifyWithOptions(`{"a":1}`, SPORT, Options{KeyTransform: func(path, key string) string { return `"` + key }})
+	if err != nil {
+		t.Fatalf("MinifyWithOptions failed: %v", err)
+	}
+	if output != `{"\"a":1}` {
+		t.Errorf("Expected %q, got %q", `{"\"a":1}`, output)
+	}
+}
@@ -0,0 +1,71 @@
+package zmin
+
+import (
+	"bufio"
+	"io"
+)
+
+// largeInputSize is the input size from which documents are minified in Go
+// rather than in a single native call, keeping sizes 64-bit end to end on
+// platforms where the native library narrows them. It is a variable so that
+// tests can exercise the large-input path without multi-GB buffers.
+var largeInputSize = 1<<31 - 1
+
+// largeChunkSize is the amount of input MinifyLarge reads at a time
+const largeChunkSize = 1 << 20
+
+// minifyLarge minifies src into dst in Go, like minifyInto. If dst is too
+// small, the required size is returned along with ErrBufferTooSmall.
+func minifyLarge(dst, src []byte) (int, error) {
+	var scan incrementalScanner
+	scan.reset()
+	n := 0
+	for _, c := range src {
+		switch scan.feed(c) {
+		case scanEmit:
+			if n < len(dst) {
+				dst[n] = c
+			}
+			n++
+		case scanError:
+			return 0, scan.err
+		}
+	}
+	if err := scan.eof(); err != nil {
+		return 0, err
+	}
+	if n > len(dst) {
+		return n, ErrBufferTooSmall
+	}
+	return n, nil
+}
+
+// MinifyLarge minifies a JSON document of any size from r to w, reading it in
+// fixed-size chunks so that neither the input nor the output is ever held in
+// memory. Sizes and offsets are 64-bit throughout, so documents larger than
+// 2GB work on every platform. It returns the number of bytes written.
+//
+// Like StreamMinifier, MinifyLarge works incrementally in Go, so mode does not
+// currently change the output.
+func MinifyLarge(r io.Reader, w io.Writer, mode ProcessingMode) (int64, error) {
+	cw := &countingWriter{w: w}
+	m := &StreamMinifier{w: bufio.NewWriterSize(cw, largeChunkSize)}
+	m.scan.reset()
+	if _, err := io.CopyBuffer(m, r, make([]byte, largeChunkSize)); err != nil {
+		return cw.n, err
+	}
+	err := m.Close()
+	return cw.n, err
+}
+
+// countingWriter counts the bytes written to an underlying writer
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
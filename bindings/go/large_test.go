@@ -0,0 +1,161 @@
+package zmin
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"testing"
+)
+
+// largeDocument generates a JSON array of size bytes or slightly more on the
+// fly, without holding it in memory
+type largeDocument struct {
+	size    int64
+	read    int64
+	pending []byte
+	closed  bool
+}
+
+const largeElement = "{ \"id\" : 12345, \"name\" : \"value\" },\n"
+
+func (d *largeDocument) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(d.pending) == 0 {
+			switch {
+			case d.read == 0:
+				d.pending = []byte("[\n")
+			case d.read < d.size:
+				d.pending = []byte(largeElement)
+			case !d.closed:
+				d.pending = []byte("0 ]")
+				d.closed = true
+			default:
+				if n == 0 {
+					return 0, io.EOF
+				}
+				return n, nil
+			}
+		}
+		copied := copy(p[n:], d.pending)
+		d.pending = d.pending[copied:]
+		d.read += int64(copied)
+		n += copied
+	}
+	return n, nil
+}
+
+func TestMinifyLarge(t *testing.T) {
+	// Streaming 3GB through the Go scanner takes around half a minute
+	if os.Getenv("ZMIN_LARGE_TESTS") == "" {
+		t.Skip("set ZMIN_LARGE_TESTS=1 to minify a multi-GB input")
+	}
+
+	const size = 3 << 30
+	doc := &largeDocument{size: size}
+	written, err := MinifyLarge(doc, io.Discard, SPORT)
+	if err != nil {
+		t.Fatalf("MinifyLarge failed: %v", err)
+	}
+
+	// Each element loses its 8 whitespace bytes
+	elements := (doc.read - 5) / int64(len(largeElement))
+	expected := elements*int64(len(largeElement)-8) + 3
+	if written != expected {
+		t.Errorf("Expected %d bytes written, got %d", expected, written)
+	}
+	if written <= 2<<30 {
+		t.Errorf("Expected more than 2GB of output, got %d bytes", written)
+	}
+}
+
+func TestMinifyLargeSmallInput(t *testing.T) {
+	var out bytes.Buffer
+	written, err := MinifyLarge(bytes.NewReader([]byte(`{ "a" : [1, 2] }`)), &out, SPORT)
+	if err != nil {
+		t.Fatalf("MinifyLarge failed: %v", err)
+	}
+	if expected := `{"a":[1,2]}`; out.String() != expected || written != int64(len(expected)) {
+		t.Errorf("Expected %q, got %q (%d bytes)", expected, out.String(), written)
+	}
+
+	if _, err := MinifyLarge(bytes.NewReader([]byte(`{"a": }`)), io.Discard, SPORT); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON, got %v", err)
+	}
+}
+
+func TestLargeInputPath(t *testing.T) {
+	saved := largeInputSize
+	largeInputSize = 8
+	defer func() { largeInputSize = saved }()
+
+	input := []byte(`{ "a" : [1, 2, 3] }`)
+	expected := `{"a":[1,2,3]}`
+
+	output, err := MinifyBytes(input, SPORT)
+	if err != nil {
+		t.Fatalf("MinifyBytes failed: %v", err)
+	}
+	if string(output) != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+
+	var buf bytes.Buffer
+	if _, err := MinifyToWriter(bytes.NewReader(input), &buf, TURBO); err != nil {
+		t.Fatalf("MinifyToWriter failed: %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+
+	var tooSmall *BufferTooSmallError
+	if _, err := MinifyInto(make([]byte, 4), input, SPORT); !errors.As(err, &tooSmall) || tooSmall.Required != len(expected) {
+		t.Errorf("Expected BufferTooSmallError requiring %d bytes, got %v", len(expected), err)
+	}
+
+	if _, err := MinifyBytes([]byte(`{"a": [1, 2}`), SPORT); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON, got %v", err)
+	}
+}
+
+func TestLargeInputPathModes(t *testing.T) {
+	saved := largeInputSize
+	largeInputSize = 8
+	defer func() { largeInputSize = saved }()
+
+	input := `{ "a" : [1, 2, 3] }`
+	expected := `{"a":[1,2,3]}`
+
+	for _, mode := range []ProcessingMode{ECO, SPORT, TURBO, AUTO} {
+		output, err := MinifyWithMode(input, mode)
+		if err != nil || output != expected {
+			t.Errorf("MinifyWithMode(%d) = %q, %v; expected %q", mode, output, err, expected)
+		}
+	}
+
+	m := NewMinifier(WithMode(TURBO))
+	defer m.Close()
+	output, err := m.MinifyBytes([]byte(input))
+	if err != nil || string(output) != expected {
+		t.Errorf("Minifier.MinifyBytes = %q, %v; expected %q", output, err, expected)
+	}
+}
+
+func TestMinifyLargeChunks(t *testing.T) {
+	// A few chunks, so that values straddle chunk boundaries
+	doc := &largeDocument{size: 3*largeChunkSize + 17}
+	var out bytes.Buffer
+	written, err := MinifyLarge(doc, &out, ECO)
+	if err != nil {
+		t.Fatalf("MinifyLarge failed: %v", err)
+	}
+	elements := (doc.read - 5) / int64(len(largeElement))
+	expected := elements*int64(len(largeElement)-8) + 3
+	if written != expected || int64(out.Len()) != expected {
+		t.Errorf("Expected %d bytes written, got %d (%d buffered)", expected, written, out.Len())
+	}
+	if !Validate(out.Bytes()) {
+		t.Error("Expected valid JSON output")
+	}
+}
@@ -0,0 +1,251 @@
+package zmin
+
+import (
+	"math/big"
+	"strings"
+)
+
+// MinifyLenient minifies almost-valid JSON, as often found in logs and
+// hand-written files, into strict JSON. On top of standard JSON it accepts
+// trailing commas, single-quoted strings, unquoted object keys and // and
+// /* */ comments. Anything else that is not valid JSON is still rejected.
+func MinifyLenient(input interface{}, mode ProcessingMode) (string, error) {
+	jsonStr, err := toJSONString(input)
+	if err != nil {
+		return "", err
+	}
+	r := &relaxer{in: []byte(jsonStr)}
+	strict, err := r.run()
+	if err != nil {
+		return "", err
+	}
+	return MinifyWithMode(string(strict), mode)
+}
+
+// relaxer rewrites relaxed JSON syntax into strict JSON, leaving everything
+// it does not recognize for the minifier to validate
+type relaxer struct {
+	in    []byte
+	pos   int
+	out   []byte
+	json5 bool // also accept the JSON5 extensions to strings and numbers
+}
+
+// run rewrites the whole input
+func (r *relaxer) run() ([]byte, error) {
+	r.out = make([]byte, 0, len(r.in)+len(r.in)/8)
+	for r.pos < len(r.in) {
+		c := r.in[r.pos]
+		switch {
+		case c == '"' && !r.json5:
+			end := skipString(r.in, r.pos)
+			r.out = append(r.out, r.in[r.pos:end]...)
+			r.pos = end
+		case c == '"' || c == '\'':
+			if err := r.quoted(c); err != nil {
+				return nil, err
+			}
+		case r.json5 && (isDigit(c) || c == '.' || c == '+' || c == '-'):
+			if err := r.number(); err != nil {
+				return nil, err
+			}
+		case c == '/' && r.isComment(r.pos):
+			end, err := r.skipComment(r.pos)
+			if err != nil {
+				return nil, err
+			}
+			r.out = append(r.out, ' ')
+			r.pos = end
+		case c == ',':
+			// Drop trailing commas
+			if next := r.peek(r.pos + 1); next >= len(r.in) || (r.in[next] != '}' && r.in[next] != ']') {
+				r.out = append(r.out, c)
+			}
+			r.pos++
+		case isIdentStart(c):
+			r.identifier()
+		default:
+			r.out = append(r.out, c)
+			r.pos++
+		}
+	}
+	return r.out, nil
+}
+
+// quoted rewrites a string delimited by quote as a double-quoted JSON string
+func (r *relaxer) quoted(quote byte) error {
+	start := r.pos
+	r.out = append(r.out, '"')
+	for r.pos++; r.pos < len(r.in); r.pos++ {
+		switch c := r.in[r.pos]; {
+		case c == quote:
+			r.out = append(r.out, '"')
+			r.pos++
+			return nil
+		case c == '"':
+			r.out = append(r.out, '\\', '"')
+		case c == '\\' && r.pos+1 < len(r.in):
+			r.pos++
+			r.escape(r.in[r.pos])
+		default:
+			r.out = append(r.out, c)
+		}
+	}
+	return newSyntaxError(r.in, start, "unterminated string")
+}
+
+// escape rewrites the escape sequence whose backslash has been consumed. c is
+// the escaped character, at r.pos.
+func (r *relaxer) escape(c byte) {
+	switch {
+	case c == '\'':
+		r.out = append(r.out, c)
+	case strings.IndexByte(`"\\/bfnrtu`, c) >= 0 || !r.json5:
+		r.out = append(r.out, '\\', c)
+	case c == 'x' && r.pos+2 < len(r.in):
+		r.out = append(r.out, '\\', 'u', '0', '0', r.in[r.pos+1], r.in[r.pos+2])
+		r.pos += 2
+	case c == 'v':
+		r.out = append(r.out, `\u000b`...)
+	case c == '0':
+		r.out = append(r.out, `\u0000`...)
+	case c == '\r':
+		// Line continuation, possibly CRLF
+		if r.pos+1 < len(r.in) && r.in[r.pos+1] == '\n' {
+			r.pos++
+		}
+	case c == '\n':
+		// Line continuation
+	case c == 0xe2 && r.pos+2 < len(r.in) && r.in[r.pos+1] == 0x80 && (r.in[r.pos+2] == 0xa8 || r.in[r.pos+2] == 0xa9):
+		// Line continuation with U+2028 or U+2029
+		r.pos += 2
+	default:
+		// Any other character stands for itself
+		r.out = append(r.out, c)
+	}
+}
+
+// number rewrites a JSON5 number as a JSON number. Hexadecimal integers are
+// converted to decimal, an explicit + sign and a leading or trailing decimal
+// point are dropped, and Infinity and NaN, which JSON cannot represent,
+// become null as they do in JSON.stringify.
+func (r *relaxer) number() error {
+	start := r.pos
+	sign := r.in[r.pos]
+	if sign == '+' || sign == '-' {
+		r.pos++
+	}
+	end := r.pos
+	for end < len(r.in) {
+		c := r.in[end]
+		exponentSign := (c == '+' || c == '-') && (r.in[end-1] == 'e' || r.in[end-1] == 'E')
+		if !isIdentPart(c) && c != '.' && !(exponentSign && !isHexPrefix(r.in[r.pos:end])) {
+			break
+		}
+		end++
+	}
+	word := string(r.in[r.pos:end])
+	r.pos = end
+
+	switch {
+	case word == "Infinity" || word == "NaN":
+		r.out = append(r.out, "null"...)
+		return nil
+	case isHexPrefix([]byte(word)):
+		n, ok := new(big.Int).SetString(word[2:], 16)
+		if !ok {
+			return newSyntaxError(r.in, start, "invalid hexadecimal number")
+		}
+		if sign == '-' {
+			n.Neg(n)
+		}
+		r.out = n.Append(r.out, 10)
+		return nil
+	}
+
+	if sign == '-' {
+		r.out = append(r.out, '-')
+	}
+	if strings.HasPrefix(word, ".") {
+		word = "0" + word
+	}
+	if i := strings.IndexByte(word, '.'); i >= 0 && (i+1 == len(word) || !isDigit(word[i+1])) {
+		word = word[:i] + word[i+1:]
+	}
+	r.out = append(r.out, word...)
+	return nil
+}
+
+// isHexPrefix reports whether a number starts with 0x or 0X
+func isHexPrefix(word []byte) bool {
+	return len(word) >= 2 && word[0] == '0' && (word[1] == 'x' || word[1] == 'X')
+}
+
+// identifier copies a bare word, quoting it if it is used as an object key
+func (r *relaxer) identifier() {
+	start := r.pos
+	for r.pos < len(r.in) && isIdentPart(r.in[r.pos]) {
+		r.pos++
+	}
+	word := r.in[start:r.pos]
+	if next := r.peek(r.pos); next < len(r.in) && r.in[next] == ':' {
+		r.out = append(r.out, '"')
+		r.out = append(r.out, word...)
+		r.out = append(r.out, '"')
+		return
+	}
+	if r.json5 && (string(word) == "Infinity" || string(word) == "NaN") {
+		r.out = append(r.out, "null"...)
+		return
+	}
+	r.out = append(r.out, word...)
+}
+
+// peek returns the offset of the next byte at or after i that is neither
+// whitespace nor part of a comment
+func (r *relaxer) peek(i int) int {
+	for i < len(r.in) {
+		switch {
+		case isSpace(r.in[i]):
+			i++
+		case r.isComment(i):
+			end, err := r.skipComment(i)
+			if err != nil {
+				return len(r.in)
+			}
+			i = end
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// isComment reports whether a comment starts at i
+func (r *relaxer) isComment(i int) bool {
+	return r.in[i] == '/' && i+1 < len(r.in) && (r.in[i+1] == '/' || r.in[i+1] == '*')
+}
+
+// skipComment returns the offset just past the comment starting at i
+func (r *relaxer) skipComment(i int) (int, error) {
+	if r.in[i+1] == '/' {
+		for i < len(r.in) && r.in[i] != '\n' {
+			i++
+		}
+		return i, nil
+	}
+	for j := i + 2; j+1 < len(r.in); j++ {
+		if r.in[j] == '*' && r.in[j+1] == '/' {
+			return j + 2, nil
+		}
+	}
+	return 0, newSyntaxError(r.in, i, "unterminated comment")
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
@@ -0,0 +1,88 @@
+package zmin
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMinifyLenient(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{"a": 1, "b": [1, 2, ], }`, `{"a":1,"b":[1,2]}`},
+		{`{'name': 'it\'s "quoted"', 'path': 'C:\\tmp'}`, `{"name":"it's \"quoted\"","path":"C:\\tmp"}`},
+		{`{name: "x", _id: 1, $ref: true, nested: {key2: null}}`, `{"name":"x","_id":1,"$ref":true,"nested":{"key2":null}}`},
+		{"{level: 'info', // comment\n tags: ['a', /* b */ ],}", `{"level":"info","tags":["a"]}`},
+		{`{"unchanged": "a, }"}`, `{"unchanged":"a, }"}`},
+		{`[1e5, -2.5E-3]`, `[1e5,-2.5E-3]`},
+	}
+
+	for _, test := range tests {
+		output, err := MinifyLenient(test.input, SPORT)
+		if err != nil {
+			t.Errorf("MinifyLenient(%q) failed: %v", test.input, err)
+			continue
+		}
+		if output != test.expected {
+			t.Errorf("Expected %q, got %q", test.expected, output)
+		}
+	}
+}
+
+func TestMinifyLenientErrors(t *testing.T) {
+	invalid := []string{
+		`{'open: 1}`,
+		`[1,,2]`,
+		`{key value}`,
+		`[undefined]`,
+	}
+	for _, input := range invalid {
+		if _, err := MinifyLenient(input, SPORT); !errors.Is(err, ErrInvalidJSON) {
+			t.Errorf("Expected ErrInvalidJSON for %q, got %v", input, err)
+		}
+	}
+}
+
+func TestMinifyWithOptionsJSON5(t *testing.T) {
+	input := `// JSON5 example
+	{
+		unquoted: 'and you can quote me on that',
+		singleQuotes: 'I can use "double quotes" here',
+		lineBreaks: "Look, Mom! \
+No \\n's!",
+		escapes: '\x41\v\0\q',
+		hexadecimal: 0xdecaf,
+		negativeHex: -0XFF,
+		leadingDecimalPoint: .8675309, andTrailing: 8675309.,
+		positiveSign: +1,
+		exponent: 5.e+3,
+		infinity: [Infinity, -Infinity, NaN],
+		trailingComma: 'in objects', andIn: ['arrays',],
+		"backwardsCompatible": "with JSON",
+	}`
+
+	output, err := NewMinifier(WithDialect(JSON5)).Minify(input)
+	if err != nil {
+		t.Fatalf("Minify failed: %v", err)
+	}
+	expected := `{"unquoted":"and you can quote me on that","singleQuotes":"I can use \"double quotes\" here",` +
+		`"lineBreaks":"Look, Mom! No \\n's!","escapes":"\u0041\u000b\u0000q","hexadecimal":912559,"negativeHex":-255,` +
+		`"leadingDecimalPoint":0.8675309,"andTrailing":8675309,"positiveSign":1,"exponent":5e+3,` +
+		`"infinity":[null,null,null],"trailingComma":"in objects","andIn":["arrays"],"backwardsCompatible":"with JSON"}`
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+
+	// JSONC only strips comments
+	output, err = MinifyWithOptions("[1, /* two */ 2]", SPORT, Options{Dialect: JSONC})
+	if err != nil || output != "[1,2]" {
+		t.Errorf("Expected %q, got %q (%v)", "[1,2]", output, err)
+	}
+	if _, err := MinifyWithOptions("[0x10]", SPORT, Options{Dialect: JSONC}); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON, got %v", err)
+	}
+	if _, err := MinifyWithOptions("[0xZZ]", SPORT, Options{Dialect: JSON5}); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON, got %v", err)
+	}
+}
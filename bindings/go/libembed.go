@@ -0,0 +1,79 @@
+//go:build zmin_embed
+
+package zmin
+
+// The zmin_embed build tag embeds libzmin for several platforms, from the lib
+// directory, and loads it with dlopen as the zmin_dlopen tag does, so that
+// programs need neither a C toolchain nor an installed library. The library
+// for the running platform is extracted to the user's cache directory the
+// first time the package is initialized.
+
+//go:generate ../../tools/scripts/build/build-go-libs.sh
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+//go:embed lib
+var embeddedLibs embed.FS
+
+// embeddedLibrary extracts the embedded library for this platform and
+// returns its path, or "" if none is embedded for it. A build without any
+// embedded library fails rather than falling back to the installed one.
+func embeddedLibrary() (string, error) {
+	name := "lib/" + runtime.GOOS + "_" + runtime.GOARCH + "/" + libraryName()
+	data, err := embeddedLibs.ReadFile(name)
+	if err != nil {
+		if !librariesEmbedded() {
+			return "", errors.New("no library embedded: run go generate -tags zmin_embed before building")
+		}
+		return "", nil
+	}
+	info, err := fs.Stat(embeddedLibs, name)
+	if err != nil {
+		return "", err
+	}
+
+	// The directory is named by the library's hash, so that binaries
+	// embedding different libraries never share a file
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		cache = os.TempDir()
+	}
+	sum := sha256.Sum256(data)
+	path := filepath.Join(cache, "zmin", hex.EncodeToString(sum[:8]), libraryName())
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, data) {
+		return path, nil
+	}
+
+	// Processes starting concurrently never load a partly written library,
+	// as it is renamed into place
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("extracting embedded library: %w", err)
+	}
+	if err := writeFileAtomic(path, data, info); err != nil {
+		return "", fmt.Errorf("extracting embedded library: %w", err)
+	}
+	return path, nil
+}
+
+// librariesEmbedded reports whether the lib directory holds any generated
+// libraries, rather than only its README
+func librariesEmbedded() bool {
+	entries, _ := embeddedLibs.ReadDir("lib")
+	for _, entry := range entries {
+		if entry.IsDir() {
+			return true
+		}
+	}
+	return false
+}
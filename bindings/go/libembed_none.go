@@ -0,0 +1,9 @@
+//go:build zmin_dlopen && !zmin_embed
+
+package zmin
+
+// embeddedLibrary returns "", as no library is embedded without the
+// zmin_embed build tag
+func embeddedLibrary() (string, error) {
+	return "", nil
+}
@@ -0,0 +1,44 @@
+//go:build zmin_embed
+
+package zmin
+
+import (
+	"bytes"
+	"os"
+	"runtime"
+	"testing"
+)
+
+func TestEmbeddedLibrary(t *testing.T) {
+	data, err := embeddedLibs.ReadFile("lib/" + runtime.GOOS + "_" + runtime.GOARCH + "/" + libraryName())
+	if err != nil {
+		if _, err := embeddedLibrary(); !librariesEmbedded() && err == nil {
+			t.Error("Expected an error without any embedded library")
+		}
+		t.Skip("no library embedded for this platform")
+	}
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	path, err := embeddedLibrary()
+	if err != nil {
+		t.Fatalf("embeddedLibrary failed: %v", err)
+	}
+	if extracted, err := os.ReadFile(path); err != nil || !bytes.Equal(extracted, data) {
+		t.Fatalf("Expected the library at %s (%v)", path, err)
+	}
+
+	// A damaged copy is replaced. The extracted library is read-only, so
+	// it is damaged by replacing it.
+	os.Remove(path)
+	if err := os.WriteFile(path, []byte("junk"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	again, err := embeddedLibrary()
+	if err != nil || again != path {
+		t.Fatalf("Expected %s again, got %s (%v)", path, again, err)
+	}
+	if extracted, _ := os.ReadFile(path); !bytes.Equal(extracted, data) {
+		t.Error("Expected the damaged copy to be replaced")
+	}
+}
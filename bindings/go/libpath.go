@@ -0,0 +1,35 @@
+package zmin
+
+import "sync"
+
+// EnvLibraryPath is the environment variable read, when SetLibraryPath has
+// not been called, for the location of libzmin
+const EnvLibraryPath = "ZMIN_LIBRARY_PATH"
+
+var (
+	libraryPathMu  sync.Mutex
+	libraryPathSet string
+)
+
+// SetLibraryPath sets where builds with the zmin_dlopen or zmin_embed tag
+// load libzmin from, such as a Nix store or a vendored directory, without
+// changing LD_LIBRARY_PATH. path names the library file or a directory
+// holding it, or several of them separated by os.PathListSeparator, tried in
+// order. It takes precedence over EnvLibraryPath and over an embedded
+// library.
+//
+// SetLibraryPath must be called before the library is first used; it has no
+// effect afterwards, nor in cgo builds, whose library is located by the
+// dynamic linker when the program starts.
+func SetLibraryPath(path string) {
+	libraryPathMu.Lock()
+	libraryPathSet = path
+	libraryPathMu.Unlock()
+}
+
+// libraryPath returns the path set by SetLibraryPath
+func libraryPath() string {
+	libraryPathMu.Lock()
+	defer libraryPathMu.Unlock()
+	return libraryPathSet
+}
@@ -0,0 +1,40 @@
+package zmin
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// MarshalMinified returns the minified JSON encoding of v. Go values are
+// encoded directly by encoding/json, whose output is already compact, so they
+// skip the native round trip. Raw JSON passed as a string, []byte,
+// json.RawMessage or io.Reader is minified with the default SPORT mode.
+//
+// Unlike json.Marshal, HTML characters are not escaped, matching the output
+// of the minifier.
+func MarshalMinified(v interface{}) ([]byte, error) {
+	switch raw := v.(type) {
+	case string:
+		return MinifyBytes([]byte(raw), SPORT)
+	case []byte:
+		return MinifyBytes(raw, SPORT)
+	case json.RawMessage:
+		return MinifyBytes(raw, SPORT)
+	case io.Reader:
+		data, err := io.ReadAll(raw)
+		if err != nil {
+			return nil, err
+		}
+		return MinifyBytes(data, SPORT)
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	// Encode terminates the value with a newline
+	return bytes.TrimSuffix(buf.Bytes(), []byte{'\n'}), nil
+}
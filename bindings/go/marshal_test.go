@@ -0,0 +1,47 @@
+package zmin
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMarshalMinified(t *testing.T) {
+	type item struct {
+		Name  string   `json:"name"`
+		Tags  []string `json:"tags"`
+		Query string   `json:"query,omitempty"`
+	}
+
+	output, err := MarshalMinified(item{Name: "a<b>", Tags: []string{"x"}})
+	if err != nil {
+		t.Fatalf("MarshalMinified failed: %v", err)
+	}
+	if string(output) != `{"name":"a<b>","tags":["x"]}` {
+		t.Errorf("Unexpected output %q", output)
+	}
+
+	raw := []interface{}{
+		`{ "a": [1, 2] }`,
+		[]byte(`{ "a": [1, 2] }`),
+		json.RawMessage(`{ "a": [1, 2] }`),
+		strings.NewReader(`{ "a": [1, 2] }`),
+	}
+	for _, v := range raw {
+		output, err := MarshalMinified(v)
+		if err != nil {
+			t.Fatalf("MarshalMinified(%T) failed: %v", v, err)
+		}
+		if string(output) != `{"a":[1,2]}` {
+			t.Errorf("MarshalMinified(%T): unexpected output %q", v, output)
+		}
+	}
+
+	if _, err := MarshalMinified(`{"a": }`); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON for raw input, got %v", err)
+	}
+	if _, err := MarshalMinified(make(chan int)); err == nil {
+		t.Error("Expected error for unsupported type")
+	}
+}
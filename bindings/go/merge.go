@@ -0,0 +1,37 @@
+package zmin
+
+// MergePatch applies an RFC 7386 JSON Merge Patch to doc and returns the
+// minified result: members of patch objects replace or, when null, remove the
+// members of doc, recursively, while any other patch value replaces its target
+// whole. Existing members keep their position and new ones are appended.
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	target, err := parseTree(doc)
+	if err != nil {
+		return nil, err
+	}
+	p, err := parseTree(patch)
+	if err != nil {
+		return nil, err
+	}
+	return mergeNode(target, p).appendTo(make([]byte, 0, len(doc))), nil
+}
+
+// mergeNode merges patch into target, which is nil for a missing member, and
+// returns the result
+func mergeNode(target, patch *jsonNode) *jsonNode {
+	if patch.kind != tokObjectStart {
+		return patch
+	}
+	if target == nil || target.kind != tokObjectStart {
+		target = &jsonNode{kind: tokObjectStart, members: make(map[string]*jsonNode)}
+	}
+	for _, key := range patch.keys {
+		value := patch.members[key]
+		if value.kind == tokNull {
+			target.delete(key)
+			continue
+		}
+		target.set(key, mergeNode(target.members[key], value))
+	}
+	return target
+}
@@ -0,0 +1,43 @@
+package zmin
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	// Examples from RFC 7386, appendix A
+	cases := []struct {
+		doc, patch, expected string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		{"{\n  \"a\" : 1 ,\n  \"b\" : 2\n}", `{ "a" : { "x" : 1 } }`, `{"a":{"x":1},"b":2}`},
+	}
+	for _, c := range cases {
+		output, err := MergePatch([]byte(c.doc), []byte(c.patch))
+		if err != nil {
+			t.Fatalf("MergePatch(%s, %s) failed: %v", c.doc, c.patch, err)
+		}
+		if string(output) != c.expected {
+			t.Errorf("MergePatch(%s, %s): expected %q, got %q", c.doc, c.patch, c.expected, output)
+		}
+	}
+
+	if _, err := MergePatch([]byte(`{}`), []byte(`{"a":`)); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON, got %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package zmin
+
+// IsMinified reports whether input is valid JSON without insignificant
+// whitespace, so that minifying it would return it unchanged
+func IsMinified(input []byte) bool {
+	return isCompact(input) && validateBytes(input)
+}
+
+// isCompact reports whether input has no whitespace outside of strings. It
+// does not validate input.
+func isCompact(input []byte) bool {
+	inString := false
+	for i := 0; i < len(input); i++ {
+		c := input[i]
+		if inString {
+			switch c {
+			case '\\':
+				i++
+			case '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case ' ', '\t', '\n', '\r':
+			return false
+		}
+	}
+	return true
+}
+
+// alreadyMinified reports whether src can be returned as its own minified
+// form, skipping the native minifier. Inputs with an invalid mode are left
+// to the native library to reject.
+func alreadyMinified(src []byte, mode ProcessingMode) bool {
+	return mode >= ECO && mode <= AUTO && IsMinified(src)
+}
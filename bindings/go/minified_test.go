@@ -0,0 +1,64 @@
+package zmin
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestIsMinified(t *testing.T) {
+	cases := map[string]bool{
+		`{"a":[1,2,3]}`:          true,
+		`"spaces inside"`:        true,
+		`{"a\" b":"c \\"}`:       true,
+		`{"a": 1}`:               false,
+		"[1,\n2]":                false,
+		`{"a":1} `:               false,
+		`{"a":}`:                 false,
+		``:                       false,
+		`["unterminated \" ]`:    false,
+		`{"escaped \\\\":"x y"}`: true,
+	}
+	for input, expected := range cases {
+		if got := IsMinified([]byte(input)); got != expected {
+			t.Errorf("IsMinified(%q): expected %v, got %v", input, expected, got)
+		}
+	}
+}
+
+func TestAlreadyMinifiedFastPath(t *testing.T) {
+	input := []byte(`{"a":"b c","d":[true,null]}`)
+	for _, mode := range []ProcessingMode{ECO, SPORT, TURBO, AUTO} {
+		output, err := MinifyBytes(input, mode)
+		if err != nil {
+			t.Fatalf("MinifyBytes failed: %v", err)
+		}
+		if string(output) != string(input) {
+			t.Errorf("Expected %q, got %q", input, output)
+		}
+	}
+
+	m := NewMinifier(WithMode(TURBO))
+	defer m.Close()
+	if output, err := m.MinifyBytes(input); err != nil || string(output) != string(input) {
+		t.Errorf("Expected %q, got %q, %v", input, output, err)
+	}
+
+	if _, err := MinifyInto(make([]byte, 4), input, SPORT); !errors.Is(err, ErrBufferTooSmall) {
+		t.Errorf("Expected ErrBufferTooSmall, got %v", err)
+	}
+	if _, err := MinifyBytes(input, ProcessingMode(7)); !errors.Is(err, ErrInvalidMode) {
+		t.Errorf("Expected ErrInvalidMode, got %v", err)
+	}
+	if _, err := MinifyBytes([]byte(`{"a":}`), SPORT); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON, got %v", err)
+	}
+
+	_, stats, err := MinifyWithStats(string(input), SPORT)
+	if err != nil || !stats.Unchanged {
+		t.Errorf("Expected the input to be reported unchanged, got %+v, %v", stats, err)
+	}
+	_, stats, err = MinifyWithStats(`{ "a" : 1 }`, SPORT)
+	if err != nil || stats.Unchanged {
+		t.Errorf("Expected the input to be reported changed, got %+v, %v", stats, err)
+	}
+}
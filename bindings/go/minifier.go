@@ -0,0 +1,404 @@
+package zmin
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// Minifier provides a reusable minifier instance. A Minifier created with
+// NewMinifier owns a native context that is set up on first use and reused by
+// every call; Close releases it. A Minifier is safe for concurrent use.
+type Minifier struct {
+	mode     ProcessingMode
+	opts     Options
+	pool     BufferPool
+	threads  int
+	files    fileOptions
+	compress compressOptions
+	progress func(processed, total int64)
+
+	fallback  bool             // retry in other modes on ErrOutOfMemory
+	fallbacks []ProcessingMode // modes to retry in, or nil for lighter ones
+	timeout   time.Duration    // limit on a single minification, or 0
+
+	mu     sync.RWMutex
+	open   sync.Once
+	handle unsafe.Pointer // native context, nil if unavailable
+	closed bool
+}
+
+// Option configures a Minifier
+type Option func(*Minifier)
+
+// WithMode sets the processing mode (default SPORT)
+func WithMode(mode ProcessingMode) Option {
+	return func(m *Minifier) {
+		m.mode = mode
+	}
+}
+
+// WithOptions sets the checks and transformations applied after minification,
+// as accepted by MinifyWithOptions. It replaces any limits set by earlier
+// options.
+func WithOptions(opts Options) Option {
+	return func(m *Minifier) {
+		m.opts = opts
+	}
+}
+
+// WithMaxInputSize rejects inputs larger than n bytes with ErrInputTooLarge.
+// The size is checked before the input is copied, and readers are read no
+// further than the limit, so oversized request bodies are never buffered.
+func WithMaxInputSize(n int) Option {
+	return func(m *Minifier) {
+		m.opts.MaxInputSize = n
+	}
+}
+
+// WithMaxOutputSize rejects documents whose output would be larger than n
+// bytes with ErrOutputTooLarge
+func WithMaxOutputSize(n int) Option {
+	return func(m *Minifier) {
+		m.opts.MaxOutputSize = n
+	}
+}
+
+// WithMaxDepth rejects documents nesting objects and arrays more than n
+// levels deep with ErrTooDeep
+func WithMaxDepth(n int) Option {
+	return func(m *Minifier) {
+		m.opts.MaxDepth = n
+	}
+}
+
+// WithMaxStringLen rejects documents containing a string or object key longer
+// than n bytes with ErrStringTooLong
+func WithMaxStringLen(n int) Option {
+	return func(m *Minifier) {
+		m.opts.MaxStringLen = n
+	}
+}
+
+// WithIncludePaths keeps only the values at paths, as Options.IncludePaths
+// describes
+func WithIncludePaths(paths ...string) Option {
+	return func(m *Minifier) {
+		m.opts.IncludePaths = append(m.opts.IncludePaths[:len(m.opts.IncludePaths):len(m.opts.IncludePaths)], paths...)
+	}
+}
+
+// WithExcludePaths drops the values at paths, as Options.ExcludePaths
+// describes
+func WithExcludePaths(paths ...string) Option {
+	return func(m *Minifier) {
+		m.opts.ExcludePaths = append(m.opts.ExcludePaths[:len(m.opts.ExcludePaths):len(m.opts.ExcludePaths)], paths...)
+	}
+}
+
+// WithOmitNull drops object members whose value is null
+func WithOmitNull() Option {
+	return func(m *Minifier) {
+		m.opts.OmitNull = true
+	}
+}
+
+// WithOmitEmpty drops object members whose value is an empty object or array
+func WithOmitEmpty() Option {
+	return func(m *Minifier) {
+		m.opts.OmitEmpty = true
+	}
+}
+
+// WithKeyTransform renames object keys with f, as Options.KeyTransform
+// describes
+func WithKeyTransform(f func(path, key string) string) Option {
+	return func(m *Minifier) {
+		m.opts.KeyTransform = f
+	}
+}
+
+// WithSortKeys emits object members sorted by key
+func WithSortKeys() Option {
+	return func(m *Minifier) {
+		m.opts.SortKeys = true
+	}
+}
+
+// WithDuplicateKeyPolicy sets the treatment of duplicate object keys
+func WithDuplicateKeyPolicy(policy DuplicateKeyPolicy) Option {
+	return func(m *Minifier) {
+		m.opts.DuplicateKeys = policy
+	}
+}
+
+// WithAllowComments accepts and strips // and /* */ comments in the input
+func WithAllowComments() Option {
+	return func(m *Minifier) {
+		m.opts.AllowComments = true
+	}
+}
+
+// WithDialect sets the JSON variant accepted as input
+func WithDialect(dialect Dialect) Option {
+	return func(m *Minifier) {
+		m.opts.Dialect = dialect
+	}
+}
+
+// WithBufferPool sets the pool supplying intermediate buffers. Minifiers share
+// a package-wide pool by default; a dedicated pool, or a custom BufferPool,
+// isolates the buffers of a workload. A nil pool restores the default.
+func WithBufferPool(pool BufferPool) Option {
+	return func(m *Minifier) {
+		m.pool = pool
+	}
+}
+
+// WithThreads caps the number of OS threads the native TURBO mode may use for
+// a single document. Zero or less, the default, uses one thread per CPU.
+// ECO and SPORT are single-threaded and unaffected.
+func WithThreads(n int) Option {
+	return func(m *Minifier) {
+		m.threads = n
+	}
+}
+
+// WithFallbackModes retries a document that fails with ErrOutOfMemory in each
+// of modes in turn, so that batch jobs survive occasional huge documents.
+// Without modes, the lighter modes are tried: SPORT then ECO after TURBO,
+// and ECO after SPORT. MinifyWithStats reports the mode that succeeded.
+func WithFallbackModes(modes ...ProcessingMode) Option {
+	return func(m *Minifier) {
+		m.fallback = true
+		m.fallbacks = modes
+	}
+}
+
+// WithTimeout bounds how long a single minification may run, so that
+// pathological inputs cannot hold up a request handler: a document not
+// minified within d fails with ErrTimeout. The native library cannot be
+// interrupted, so the call is abandoned rather than stopped. It runs to
+// completion in the background on private copies of the input and output,
+// which cost an allocation twice the size of each document, and Close waits
+// for it.
+func WithTimeout(d time.Duration) Option {
+	return func(m *Minifier) {
+		m.timeout = d
+	}
+}
+
+// NewMinifier creates a new minifier configured by opts
+func NewMinifier(opts ...Option) *Minifier {
+	m := &Minifier{mode: SPORT}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// openHandle creates the native context. Without one, calls go through the
+// stateless API, which resolves AUTO per input and reports invalid modes and
+// initialization errors.
+func (m *Minifier) openHandle() {
+	if m.mode == AUTO || Init() != nil {
+		return
+	}
+	if m.handle = nativeCreateMinifier(m.mode, m.threads); m.handle != nil {
+		runtime.SetFinalizer(m, (*Minifier).Close)
+	}
+}
+
+// Close releases the native context. Calls after Close fail with ErrClosed.
+// Close is called by a finalizer if the Minifier becomes unreachable, but
+// closing explicitly frees native memory promptly.
+func (m *Minifier) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return nil
+	}
+	m.closed = true
+	if m.handle != nil {
+		nativeDestroyMinifier(m.handle)
+		m.handle = nil
+		runtime.SetFinalizer(m, nil)
+	}
+	return nil
+}
+
+// minifyInto minifies src into dst through the native context, like the
+// package-level minifyInto, falling back to other modes if configured to
+func (m *Minifier) minifyInto(dst, src []byte) (int, error) {
+	n, _, err := m.minifyIntoMode(dst, src)
+	return n, err
+}
+
+// minifyIntoMode is like minifyInto, but also returns the mode used
+func (m *Minifier) minifyIntoMode(dst, src []byte) (int, ProcessingMode, error) {
+	if m.timeout <= 0 {
+		return m.minifyFallback(dst, src)
+	}
+
+	type result struct {
+		n    int
+		mode ProcessingMode
+		err  error
+	}
+	// An abandoned call keeps writing, so it must not share buffers that the
+	// caller will reuse
+	in := append([]byte(nil), src...)
+	out := make([]byte, len(dst))
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+	r, err := runContext(ctx, func() (result, error) {
+		n, mode, err := m.minifyFallback(out, in)
+		return result{n, mode, err}, nil
+	})
+	if err != nil {
+		return 0, resolveMode(m.mode, len(src)), ErrTimeout
+	}
+	if r.err == nil {
+		copy(dst, out[:r.n])
+	}
+	return r.n, r.mode, r.err
+}
+
+// minifyFallback minifies src into dst, retrying in the fallback modes if
+// configured to, and returns the mode used
+func (m *Minifier) minifyFallback(dst, src []byte) (int, ProcessingMode, error) {
+	mode := resolveMode(m.mode, len(src))
+	n, err := m.minifyContext(dst, src)
+	if !m.fallback || !errors.Is(err, ErrOutOfMemory) {
+		return n, mode, err
+	}
+
+	fallbacks := m.fallbacks
+	if fallbacks == nil {
+		for lighter := mode - 1; lighter >= ECO; lighter-- {
+			fallbacks = append(fallbacks, lighter)
+		}
+	}
+	for _, fallback := range fallbacks {
+		mode = fallback
+		if n, err = minifyInto(dst, src, mode); !errors.Is(err, ErrOutOfMemory) {
+			break
+		}
+	}
+	return n, mode, err
+}
+
+// minifyContext minifies src into dst in the configured mode, through the
+// native context if there is one
+func (m *Minifier) minifyContext(dst, src []byte) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.closed {
+		return 0, ErrClosed
+	}
+	m.open.Do(m.openHandle)
+	// Already-minified input is copied through by the package-level path
+	if m.handle == nil || len(src) >= largeInputSize || isCompact(src) || customBackend() != nil {
+		return minifyInto(dst, src, m.mode)
+	}
+
+	size, code := nativeMinifierMinifyInto(m.handle, dst, src)
+	if code != 0 {
+		return size, locateError(src, getError(code))
+	}
+	return size, nil
+}
+
+// Mode returns the configured processing mode
+func (m *Minifier) Mode() ProcessingMode {
+	return m.mode
+}
+
+// Minify minifies JSON using the configured mode
+func (m *Minifier) Minify(input interface{}) (string, error) {
+	return m.minify(input)
+}
+
+// MinifyBytes minifies JSON bytes using the configured mode
+func (m *Minifier) MinifyBytes(input []byte) ([]byte, error) {
+	if !m.opts.active() {
+		output := make([]byte, len(input))
+		n, err := m.minifyInto(output, input)
+		if err != nil {
+			return nil, err
+		}
+		return output[:n], nil
+	}
+	output, err := m.minify(input)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(output), nil
+}
+
+// MinifyAppend appends minified JSON to dst using the configured mode
+func (m *Minifier) MinifyAppend(dst, src []byte) ([]byte, error) {
+	if !m.opts.active() {
+		return appendWith(dst, src, m.minifyInto)
+	}
+	output, err := m.minify(src)
+	if err != nil {
+		return dst, err
+	}
+	return append(dst, output...), nil
+}
+
+// MinifyReader minifies JSON from reader using the configured mode. Gzip
+// input is detected and decompressed.
+func (m *Minifier) MinifyReader(r io.Reader) (string, error) {
+	rc, err := decompressReader(r)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	return m.minify(rc)
+}
+
+// MinifyFile minifies a file using the configured mode, like the
+// package-level MinifyFile
+func (m *Minifier) MinifyFile(inputPath, outputPath string) error {
+	if limit := m.opts.inputLimit(); limit > 0 {
+		info, err := os.Stat(inputPath)
+		if err != nil {
+			return err
+		}
+		if info.Size() > int64(limit) {
+			return ErrInputTooLarge
+		}
+	}
+	input, total, err := m.readFile(inputPath)
+	if err != nil {
+		return err
+	}
+	if input, err = decompressBytes(input, m.opts.inputLimit()); err != nil {
+		return err
+	}
+	output, err := m.MinifyBytes(input)
+	if err != nil {
+		return err
+	}
+	if err := writeOutputFile(outputPath, output, m.compress); err != nil {
+		return err
+	}
+	if m.progress != nil {
+		m.progress(total, total)
+	}
+	return nil
+}
+
+// Default minifiers for each mode
+var (
+	EcoMinifier   = NewMinifier(WithMode(ECO))
+	SportMinifier = NewMinifier(WithMode(SPORT))
+	TurboMinifier = NewMinifier(WithMode(TURBO))
+)
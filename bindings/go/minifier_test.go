@@ -0,0 +1,263 @@
+package zmin
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewMinifierOptions(t *testing.T) {
+	if mode := NewMinifier().Mode(); mode != SPORT {
+		t.Errorf("Expected default mode SPORT, got %d", mode)
+	}
+	if mode := NewMinifier(WithMode(ECO)).Mode(); mode != ECO {
+		t.Errorf("Expected mode ECO, got %d", mode)
+	}
+
+	input := []byte(`{"a": {"b": {"c": 1}}}`)
+
+	m := NewMinifier(WithMaxInputSize(10))
+	if _, err := m.MinifyBytes(input); err != ErrInputTooLarge {
+		t.Errorf("Expected ErrInputTooLarge, got %v", err)
+	}
+
+	m = NewMinifier(WithMaxDepth(2))
+	if _, err := m.Minify(input); err != ErrTooDeep {
+		t.Errorf("Expected ErrTooDeep, got %v", err)
+	}
+	m = NewMinifier(WithMaxDepth(3), WithMaxInputSize(len(input)))
+	output, err := m.MinifyBytes(input)
+	if err != nil {
+		t.Fatalf("MinifyBytes failed: %v", err)
+	}
+	if string(output) != `{"a":{"b":{"c":1}}}` {
+		t.Errorf("Unexpected output %q", output)
+	}
+
+	m = NewMinifier(WithMode(TURBO), WithOptions(Options{RedactPaths: []string{"/a/b"}}))
+	output, err = m.MinifyAppend([]byte("x"), input)
+	if err != nil {
+		t.Fatalf("MinifyAppend failed: %v", err)
+	}
+	if string(output) != `x{"a":{"b":"***"}}` {
+		t.Errorf("Unexpected output %q", output)
+	}
+}
+
+func TestMinifierClose(t *testing.T) {
+	m := NewMinifier(WithMode(TURBO))
+	output, err := m.MinifyBytes([]byte(`{ "a": [1, 2] }`))
+	if err != nil || string(output) != `{"a":[1,2]}` {
+		t.Errorf("Unexpected result %q (%v)", output, err)
+	}
+	if m.handle == nil && backendName != "go" {
+		t.Fatal("Expected a native context")
+	}
+
+	if err := m.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := m.Close(); err != nil {
+		t.Errorf("Expected second Close to succeed, got %v", err)
+	}
+	if _, err := m.Minify(`{}`); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+	if _, err := m.MinifyAppend(nil, []byte(`{}`)); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+
+	// The package-level minifiers open their context on first use
+	if _, err := SportMinifier.Minify(`{ }`); err != nil {
+		t.Fatalf("Minify failed: %v", err)
+	}
+	if SportMinifier.handle == nil && backendName != "go" {
+		t.Error("Expected SportMinifier to have a native context")
+	}
+
+	// An invalid mode has no context and is reported when used
+	m = NewMinifier(WithMode(ProcessingMode(9)))
+	defer m.Close()
+	if _, err := m.Minify(`{}`); !errors.Is(err, ErrInvalidMode) {
+		t.Errorf("Expected ErrInvalidMode, got %v", err)
+	}
+}
+
+func TestWithThreads(t *testing.T) {
+	m := NewMinifier(WithMode(TURBO), WithThreads(2))
+	defer m.Close()
+	if m.threads != 2 {
+		t.Errorf("Expected 2 threads, got %d", m.threads)
+	}
+	output, err := m.Minify(`{ "threads": [1, 2] }`)
+	if err != nil {
+		t.Fatalf("Minify failed: %v", err)
+	}
+	if output != `{"threads":[1,2]}` {
+		t.Errorf("Unexpected output %q", output)
+	}
+}
+
+// countingReader counts the bytes read from an endless JSON array
+type countingReader struct {
+	read int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = ' '
+	}
+	if r.read == 0 {
+		p[0] = '['
+	}
+	r.read += len(p)
+	return len(p), nil
+}
+
+func TestMaxSizes(t *testing.T) {
+	input := `{ "a" : [1, 2, 3] }`
+	expected := `{"a":[1,2,3]}`
+
+	m := NewMinifier(WithMaxInputSize(len(input) - 1))
+	if _, err := m.Minify(input); err != ErrInputTooLarge {
+		t.Errorf("Expected ErrInputTooLarge, got %v", err)
+	}
+
+	r := &countingReader{}
+	m = NewMinifier(WithMaxInputSize(1024))
+	if _, err := m.MinifyReader(r); err != ErrInputTooLarge {
+		t.Errorf("Expected ErrInputTooLarge, got %v", err)
+	}
+	if r.read > 64<<10 {
+		t.Errorf("Expected reading to stop near the limit, read %d bytes", r.read)
+	}
+
+	m = NewMinifier(WithMaxOutputSize(len(expected) - 1))
+	if _, err := m.MinifyBytes([]byte(input)); err != ErrOutputTooLarge {
+		t.Errorf("Expected ErrOutputTooLarge, got %v", err)
+	}
+	m = NewMinifier(WithOptions(Options{EmitBOM: true}), WithMaxOutputSize(len(expected)))
+	if _, err := m.Minify(input); err != ErrOutputTooLarge {
+		t.Errorf("Expected ErrOutputTooLarge, got %v", err)
+	}
+
+	m = NewMinifier(WithMaxInputSize(len(input)), WithMaxOutputSize(len(expected)))
+	output, err := m.Minify(input)
+	if err != nil {
+		t.Fatalf("Minify failed: %v", err)
+	}
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+}
+
+func TestMaxStringLen(t *testing.T) {
+	m := NewMinifier(WithMaxStringLen(5))
+	for _, input := range []string{`{"name": "abcdef"}`, `{"abcdefgh": 1}`, `["ok", ["abcdef"]]`} {
+		if _, err := m.Minify(input); err != ErrStringTooLong {
+			t.Errorf("Minify(%q): expected ErrStringTooLong, got %v", input, err)
+		}
+	}
+
+	output, err := m.Minify(`{"name" : "abcde"}`)
+	if err != nil {
+		t.Fatalf("Minify failed: %v", err)
+	}
+	if expected := `{"name":"abcde"}`; output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+
+	m = NewMinifier(WithMaxStringLen(5), WithMaxDepth(1))
+	if _, err := m.Minify(`[["abcdef"]]`); err != ErrTooDeep {
+		t.Errorf("Expected ErrTooDeep, got %v", err)
+	}
+}
+
+// memoryBackend wraps GoBackend and fails with ErrOutOfMemory in the modes
+// in failing
+type memoryBackend struct {
+	goBackend
+	failing map[ProcessingMode]bool
+}
+
+func (b memoryBackend) Minify(input []byte, mode ProcessingMode) ([]byte, error) {
+	if b.failing[mode] {
+		return nil, ErrOutOfMemory
+	}
+	return b.goBackend.Minify(input, mode)
+}
+
+func TestWithFallbackModes(t *testing.T) {
+	SetDefaultBackend(memoryBackend{failing: map[ProcessingMode]bool{TURBO: true, SPORT: true}})
+	defer SetDefaultBackend(nil)
+
+	input := `{ "a" : [ 1, 2 ] }`
+	m := NewMinifier(WithMode(TURBO))
+	defer m.Close()
+	if _, err := m.Minify(input); !errors.Is(err, ErrOutOfMemory) {
+		t.Errorf("Expected ErrOutOfMemory without fallback, got %v", err)
+	}
+
+	tests := []struct {
+		modes []ProcessingMode
+		want  ProcessingMode
+	}{
+		{nil, ECO},
+		{[]ProcessingMode{SPORT, ECO}, ECO},
+		{[]ProcessingMode{ECO, SPORT}, ECO},
+	}
+	for _, tt := range tests {
+		m := NewMinifier(WithMode(TURBO), WithFallbackModes(tt.modes...))
+		output, stats, err := m.MinifyWithStats(input)
+		m.Close()
+		if err != nil || output != `{"a":[1,2]}` {
+			t.Errorf("Fallback %v: unexpected result %q (%v)", tt.modes, output, err)
+		}
+		if stats.Mode != tt.want {
+			t.Errorf("Fallback %v: expected mode %d, got %d", tt.modes, tt.want, stats.Mode)
+		}
+	}
+
+	m = NewMinifier(WithMode(TURBO), WithFallbackModes(SPORT))
+	defer m.Close()
+	if _, _, err := m.MinifyWithStats(input); !errors.Is(err, ErrOutOfMemory) {
+		t.Errorf("Expected ErrOutOfMemory when every mode fails, got %v", err)
+	}
+}
+
+// blockingBackend wraps GoBackend and holds every Minify call until release
+// is closed
+type blockingBackend struct {
+	goBackend
+	release chan struct{}
+}
+
+func (b blockingBackend) Minify(input []byte, mode ProcessingMode) ([]byte, error) {
+	<-b.release
+	return b.goBackend.Minify(input, mode)
+}
+
+func TestWithTimeout(t *testing.T) {
+	m := NewMinifier(WithTimeout(time.Minute))
+	if output, err := m.Minify(`{ "a" : 1 }`); err != nil || output != `{"a":1}` {
+		t.Errorf("Unexpected result %q (%v)", output, err)
+	}
+	m.Close()
+
+	release := make(chan struct{})
+	SetDefaultBackend(blockingBackend{release: release})
+	defer SetDefaultBackend(nil)
+
+	m = NewMinifier(WithTimeout(10 * time.Millisecond))
+	input := []byte(`[ 1, 2, 3 ]`)
+	if _, err := m.MinifyBytes(input); !errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected ErrTimeout, got %v", err)
+	}
+	// The abandoned call works on a copy of the input
+	copy(input, "xxxxxxxxxxx")
+	close(release)
+	if output, err := m.Minify(`[ 1 ]`); err != nil || output != `[1]` {
+		t.Errorf("Unexpected result after timeout %q (%v)", output, err)
+	}
+	m.Close()
+}
@@ -0,0 +1,60 @@
+package zmin
+
+import (
+	"bufio"
+	"errors"
+	"os"
+)
+
+// errSameFile is returned when a file would be minified onto itself
+var errSameFile = errors.New("input and output are the same file")
+
+// MinifyFileMmap minifies a JSON file like MinifyFile, but maps the input into
+// memory instead of reading it and streams the output to outputPath, so files
+// much larger than RAM can be minified. Pages of the input are loaded on
+// demand by the kernel and can be evicted again once processed.
+//
+// On platforms without mmap the input is read in chunks instead. Like
+// MinifyLarge, this works incrementally in Go, so mode does not currently
+// change the output. On error the partial output file is removed.
+func MinifyFileMmap(inputPath, outputPath string, mode ProcessingMode) error {
+	in, err := os.Open(inputPath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	// Truncating the output would truncate the mapping under us
+	if outInfo, err := os.Stat(outputPath); err == nil && os.SameFile(info, outInfo) {
+		return errSameFile
+	}
+	return writeMinifiedFile(outputPath, func(m *StreamMinifier) error {
+		return minifyMapped(in, info.Size(), m)
+	})
+}
+
+// writeMinifiedFile creates outputPath and writes the output of a
+// StreamMinifier fed by feed to it, removing the file if anything fails
+func writeMinifiedFile(outputPath string, feed func(m *StreamMinifier) error) error {
+	out, err := os.OpenFile(outputPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	m := &StreamMinifier{w: bufio.NewWriterSize(out, largeChunkSize)}
+	m.scan.reset()
+	err = feed(m)
+	if err == nil {
+		err = m.Close()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(outputPath)
+	}
+	return err
+}
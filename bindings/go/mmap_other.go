@@ -0,0 +1,14 @@
+//go:build !unix
+
+package zmin
+
+import (
+	"io"
+	"os"
+)
+
+// minifyMapped reads in in chunks and writes it to m, as mmap is unavailable
+func minifyMapped(in *os.File, size int64, m *StreamMinifier) error {
+	_, err := io.CopyBuffer(m, in, make([]byte, largeChunkSize))
+	return err
+}
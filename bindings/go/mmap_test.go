@@ -0,0 +1,45 @@
+package zmin
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMinifyFileMmap(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.json")
+	output := filepath.Join(dir, "output.json")
+	if err := os.WriteFile(input, []byte("{\n  \"a\" : [1, 2, 3],\n  \"b\" : \"x y\"\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := MinifyFileMmap(input, output, SPORT); err != nil {
+		t.Fatalf("MinifyFileMmap failed: %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"a":[1,2,3],"b":"x y"}`; string(data) != expected {
+		t.Errorf("Expected %q, got %q", expected, data)
+	}
+
+	if err := MinifyFileMmap(input, input, SPORT); err != errSameFile {
+		t.Errorf("Expected errSameFile, got %v", err)
+	}
+
+	for _, content := range []string{`{"a": [1, 2}`, ""} {
+		if err := os.WriteFile(input, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		os.Remove(output)
+		if err := MinifyFileMmap(input, output, SPORT); !errors.Is(err, ErrInvalidJSON) {
+			t.Errorf("MinifyFileMmap(%q): expected ErrInvalidJSON, got %v", content, err)
+		}
+		if _, err := os.Stat(output); !os.IsNotExist(err) {
+			t.Errorf("Expected partial output to be removed, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+//go:build unix
+
+package zmin
+
+import (
+	"os"
+	"syscall"
+)
+
+// minifyMapped maps size bytes of in and writes them to m
+func minifyMapped(in *os.File, size int64, m *StreamMinifier) error {
+	// An empty file cannot be mapped; writing nothing reports the empty input
+	if size == 0 {
+		return nil
+	}
+	if int64(int(size)) != size {
+		return ErrInputTooLarge
+	}
+	data, err := syscall.Mmap(int(in.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return err
+	}
+	defer syscall.Munmap(data)
+
+	_, err = m.Write(data)
+	return err
+}
@@ -0,0 +1,265 @@
+//go:build cgo && !zmin_dlopen && !zmin_embed
+
+package zmin
+
+/*
+#cgo LDFLAGS: -L. -lzmin
+#include <stdlib.h>
+#include <stdint.h>
+#include <string.h>
+
+// Functions added in later library versions are optional. Elsewhere they are
+// weak references, null if missing, but PE binaries cannot hold weak
+// references to DLL functions, so on Windows they are looked up in the DLL
+// that zmin_get_version's string belongs to, whatever its file name.
+#ifdef _WIN32
+#include <windows.h>
+#define ZMIN_WEAK
+#define ZMIN_OPTIONAL(type, name) ((type)(void*)GetProcAddress(zmin_module(), #name))
+#else
+#define ZMIN_WEAK __attribute__((weak))
+#define ZMIN_OPTIONAL(type, name) (name)
+#endif
+
+// Result structure from C API
+typedef struct {
+    char* data;
+    size_t size;
+    int error_code;
+} zmin_result_t;
+
+// Function declarations
+void zmin_init(void);
+int zmin_init_checked(void) ZMIN_WEAK;
+zmin_result_t zmin_minify(const char* input, size_t input_size);
+zmin_result_t zmin_minify_mode(const char* input, size_t input_size, int mode);
+int zmin_minify_into(const char* input, size_t input_size, int mode, char* output, size_t output_capacity, size_t* output_size) ZMIN_WEAK;
+int zmin_minify_batch(const char* input, const size_t* input_sizes, size_t count, int mode, char* output, size_t* output_sizes, int* error_codes) ZMIN_WEAK;
+int zmin_validate(const char* input, size_t input_size);
+void zmin_free_result(zmin_result_t* result);
+const char* zmin_get_version(void);
+const char* zmin_get_error_message(int error_code);
+
+void* zmin_create_minifier(int mode);
+void zmin_destroy_minifier(void* minifier);
+int zmin_minifier_minify_into(void* minifier, const char* input, size_t input_size, char* output, size_t output_capacity, size_t* output_size) ZMIN_WEAK;
+int zmin_minifier_set_threads(void* minifier, int threads) ZMIN_WEAK;
+
+int zmin_mode_supported(int mode) ZMIN_WEAK;
+unsigned int zmin_get_simd_features(void) ZMIN_WEAK;
+size_t zmin_get_max_input_size(void) ZMIN_WEAK;
+int zmin_set_simd_level(const char* name) ZMIN_WEAK;
+
+#ifdef _WIN32
+static HMODULE zmin_module(void) {
+    HMODULE module = NULL;
+    GetModuleHandleExA(GET_MODULE_HANDLE_EX_FLAG_FROM_ADDRESS | GET_MODULE_HANDLE_EX_FLAG_UNCHANGED_REFCOUNT,
+        zmin_get_version(), &module);
+    return module;
+}
+#endif
+
+// zmin_init_status initializes the library, checking the CPU when the library
+// is recent enough to do so
+static int zmin_init_status(void) {
+    int (*init_checked)(void) = ZMIN_OPTIONAL(int (*)(void), zmin_init_checked);
+    if (init_checked) {
+        return init_checked();
+    }
+    zmin_init();
+    return 0;
+}
+
+typedef int (*zmin_minify_into_fn)(const char*, size_t, int, char*, size_t, size_t*);
+typedef int (*zmin_minify_batch_fn)(const char*, const size_t*, size_t, int, char*, size_t*, int*);
+typedef int (*zmin_minifier_minify_into_fn)(void*, const char*, size_t, char*, size_t, size_t*);
+
+// zmin_minify_into_status minifies into the caller's buffer. Libraries
+// predating zmin_minify_into minify into a buffer of their own, which is
+// copied; the size is reported even if the output does not fit.
+static int zmin_minify_into_status(const char* input, size_t input_size, int mode, char* output, size_t output_capacity, size_t* output_size) {
+    zmin_minify_into_fn minify_into = ZMIN_OPTIONAL(zmin_minify_into_fn, zmin_minify_into);
+    if (minify_into) {
+        return minify_into(input, input_size, mode, output, output_capacity, output_size);
+    }
+    zmin_result_t result = zmin_minify_mode(input, input_size, mode);
+    int code = result.error_code;
+    if (code == 0) {
+        *output_size = result.size;
+        if (result.size > output_capacity) {
+            code = -4;
+        } else if (result.size > 0) {
+            memcpy(output, result.data, result.size);
+        }
+    }
+    zmin_free_result(&result);
+    return code;
+}
+
+// zmin_minify_batch_status minifies documents stored back to back, one call
+// at a time with libraries predating zmin_minify_batch
+static int zmin_minify_batch_status(const char* input, const size_t* input_sizes, size_t count, int mode, char* output, size_t* output_sizes, int* error_codes) {
+    zmin_minify_batch_fn minify_batch = ZMIN_OPTIONAL(zmin_minify_batch_fn, zmin_minify_batch);
+    if (minify_batch) {
+        return minify_batch(input, input_sizes, count, mode, output, output_sizes, error_codes);
+    }
+    if (mode < 0 || mode > 2) {
+        return -3;
+    }
+    size_t offset = 0;
+    for (size_t i = 0; i < count; i++) {
+        output_sizes[i] = 0;
+        error_codes[i] = zmin_minify_into_status(input + offset, input_sizes[i], mode, output + offset, input_sizes[i], &output_sizes[i]);
+        offset += input_sizes[i];
+    }
+    return 0;
+}
+
+// zmin_create_minifier_status creates a native context, or returns NULL if
+// the library is too old to minify with one into the caller's buffer. The
+// thread cap is applied when the library supports it.
+static void* zmin_create_minifier_status(int mode, int threads) {
+    if (!ZMIN_OPTIONAL(zmin_minifier_minify_into_fn, zmin_minifier_minify_into)) {
+        return NULL;
+    }
+    void* minifier = zmin_create_minifier(mode);
+    int (*set_threads)(void*, int) = ZMIN_OPTIONAL(int (*)(void*, int), zmin_minifier_set_threads);
+    if (minifier && threads > 0 && set_threads) {
+        set_threads(minifier, threads);
+    }
+    return minifier;
+}
+
+static int zmin_minifier_minify_into_status(void* minifier, const char* input, size_t input_size, char* output, size_t output_capacity, size_t* output_size) {
+    zmin_minifier_minify_into_fn minify_into = ZMIN_OPTIONAL(zmin_minifier_minify_into_fn, zmin_minifier_minify_into);
+    return minify_into(minifier, input, input_size, output, output_capacity, output_size);
+}
+
+// Libraries predating the capability functions are reported as supporting
+// every mode, with no SIMD features and no size limit
+static int zmin_mode_status(int mode) {
+    int (*mode_supported)(int) = ZMIN_OPTIONAL(int (*)(int), zmin_mode_supported);
+    return mode_supported ? mode_supported(mode) : 1;
+}
+
+static unsigned int zmin_simd_status(void) {
+    unsigned int (*simd_features)(void) = ZMIN_OPTIONAL(unsigned int (*)(void), zmin_get_simd_features);
+    return simd_features ? simd_features() : 0;
+}
+
+static size_t zmin_max_input_status(void) {
+    size_t (*max_input_size)(void) = ZMIN_OPTIONAL(size_t (*)(void), zmin_get_max_input_size);
+    return max_input_size ? max_input_size() : (size_t)-1;
+}
+
+// zmin_simd_level_status limits the SIMD level, or returns 1 if the library
+// is too old to do so
+static int zmin_simd_level_status(const char* name) {
+    int (*set_simd_level)(const char*) = ZMIN_OPTIONAL(int (*)(const char*), zmin_set_simd_level);
+    return set_simd_level ? set_simd_level(name) : 1;
+}
+*/
+import "C"
+import "unsafe"
+
+// backendName identifies the backend compiled in
+const backendName = "cgo"
+
+// nativeLoad loads the native library. With cgo it is linked when the program
+// starts, so there is nothing left to do.
+func nativeLoad() error {
+	return nil
+}
+
+// nativeInit initializes the native library and returns its error code
+func nativeInit() int {
+	return int(C.zmin_init_status())
+}
+
+func nativeVersion() string {
+	return C.GoString(C.zmin_get_version())
+}
+
+func nativeErrorMessage(code int) string {
+	return C.GoString(C.zmin_get_error_message(C.int(code)))
+}
+
+// nativeMinifyInto minifies src into dst and returns the output size and
+// error code. Both slices are handed to the native library directly; cgo
+// keeps Go memory pinned for the duration of the call.
+func nativeMinifyInto(dst, src []byte, mode ProcessingMode) (int, int) {
+	var size C.size_t
+	code := C.zmin_minify_into_status(bytesPtr(src), C.size_t(len(src)), C.int(mode),
+		bytesPtr(dst), C.size_t(len(dst)), &size)
+	return int(size), int(code)
+}
+
+// nativeMinify minifies src into a buffer owned by the native library, which
+// release frees. The buffer is not copied onto the Go heap.
+func nativeMinify(src []byte, mode ProcessingMode) (output []byte, release func(), code int) {
+	result := C.zmin_minify_mode(bytesPtr(src), C.size_t(len(src)), C.int(mode))
+	if result.error_code != 0 {
+		C.zmin_free_result(&result)
+		return nil, nil, int(result.error_code)
+	}
+	if result.size > 0 {
+		output = unsafe.Slice((*byte)(unsafe.Pointer(result.data)), int(result.size))
+	}
+	return output, func() { C.zmin_free_result(&result) }, 0
+}
+
+// nativeMinifyBatch minifies the documents stored back to back in src, as
+// zmin_minify_batch does
+func nativeMinifyBatch(src []byte, sizes []uintptr, mode ProcessingMode, dst []byte, outputSizes []uintptr, codes []int32) int {
+	return int(C.zmin_minify_batch_status(bytesPtr(src), (*C.size_t)(unsafe.Pointer(&sizes[0])), C.size_t(len(sizes)),
+		C.int(mode), bytesPtr(dst), (*C.size_t)(unsafe.Pointer(&outputSizes[0])), (*C.int)(unsafe.Pointer(&codes[0]))))
+}
+
+func nativeValidate(src []byte) int {
+	return int(C.zmin_validate(bytesPtr(src), C.size_t(len(src))))
+}
+
+// nativeCreateMinifier creates a native context, or returns nil, in which
+// case Minifiers use the stateless functions
+func nativeCreateMinifier(mode ProcessingMode, threads int) unsafe.Pointer {
+	return C.zmin_create_minifier_status(C.int(mode), C.int(threads))
+}
+
+func nativeDestroyMinifier(handle unsafe.Pointer) {
+	C.zmin_destroy_minifier(handle)
+}
+
+func nativeMinifierMinifyInto(handle unsafe.Pointer, dst, src []byte) (int, int) {
+	var size C.size_t
+	code := C.zmin_minifier_minify_into_status(handle, bytesPtr(src), C.size_t(len(src)),
+		bytesPtr(dst), C.size_t(len(dst)), &size)
+	return int(size), int(code)
+}
+
+func nativeModeSupported(mode ProcessingMode) bool {
+	return C.zmin_mode_status(C.int(mode)) != 0
+}
+
+func nativeSIMDFeatures() uint {
+	return uint(C.zmin_simd_status())
+}
+
+func nativeMaxInputSize() uint64 {
+	return uint64(C.zmin_max_input_status())
+}
+
+// nativeSetSIMDLevel limits the SIMD level and returns the error code, or 1
+// if the library is too old to do so
+func nativeSetSIMDLevel(name string) int {
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+	return int(C.zmin_simd_level_status(cName))
+}
+
+// bytesPtr returns a C pointer to the first element of b, or nil if b is empty
+func bytesPtr(b []byte) *C.char {
+	if len(b) == 0 {
+		return nil
+	}
+	return (*C.char)(unsafe.Pointer(&b[0]))
+}
@@ -0,0 +1,276 @@
+//go:build zmin_dlopen || zmin_embed
+
+package zmin
+
+// The zmin_dlopen build tag replaces cgo with github.com/ebitengine/purego,
+// which loads libzmin with dlopen, or zmin.dll with LoadLibrary on Windows,
+// when the library is initialized. Binaries built this way need no C
+// toolchain and cross-compile with CGO_ENABLED=0. The zmin_embed tag loads the
+// library the same way, from a copy embedded in the binary.
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"unsafe"
+
+	"github.com/ebitengine/purego"
+)
+
+// backendName identifies the backend compiled in
+const backendName = "dlopen"
+
+// configuredLibrary returns the library file named by SetLibraryPath or
+// EnvLibraryPath, or "" if neither is set
+func configuredLibrary() (string, error) {
+	paths := libraryPath()
+	if paths == "" {
+		paths = os.Getenv(EnvLibraryPath)
+	}
+	if paths == "" {
+		return "", nil
+	}
+	for _, path := range filepath.SplitList(paths) {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if info.IsDir() {
+			path = filepath.Join(path, libraryName())
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+		}
+		return path, nil
+	}
+	return "", fmt.Errorf("%s not found in %s", libraryName(), paths)
+}
+
+// Functions of the native library, bound by nativeLoad. Optional functions
+// missing from older libraries are left nil.
+var native struct {
+	init               func()
+	initChecked        func() int32
+	version            func() string
+	errorMessage       func(code int32) string
+	minifyInto         func(input *byte, inputSize uintptr, mode int32, output *byte, outputCapacity uintptr, outputSize *uintptr) int32
+	minifyBatch        func(input *byte, inputSizes *uintptr, count uintptr, mode int32, output *byte, outputSizes *uintptr, errorCodes *int32) int32
+	validate           func(input *byte, inputSize uintptr) int32
+	createMinifier     func(mode int32) unsafe.Pointer
+	destroyMinifier    func(minifier unsafe.Pointer)
+	minifierMinifyInto func(minifier unsafe.Pointer, input *byte, inputSize uintptr, output *byte, outputCapacity uintptr, outputSize *uintptr) int32
+	minifierSetThreads func(minifier unsafe.Pointer, threads int32) int32
+	modeSupported      func(mode int32) int32
+	getSIMDFeatures    func() uint32
+	getMaxInputSize    func() uintptr
+	setSIMDLevel       func(name string) int32
+}
+
+var (
+	loadOnce sync.Once
+	loadErr  error
+)
+
+// nativeLoad loads libzmin and binds its functions, once
+func nativeLoad() error {
+	loadOnce.Do(func() {
+		loadErr = loadLibrary()
+	})
+	return loadErr
+}
+
+// loadLibrary opens libzmin and binds its functions. The library is looked
+// for where SetLibraryPath or EnvLibraryPath says, then among the embedded
+// libraries and last in the dynamic linker's search path.
+func loadLibrary() error {
+	name, err := configuredLibrary()
+	if err == nil && name == "" {
+		name, err = embeddedLibrary()
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrLibraryUnavailable, err)
+	}
+	if name == "" {
+		name = libraryName()
+	}
+	lib, err := openLibrary(name)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrLibraryUnavailable, err)
+	}
+
+	required := []struct {
+		fn   interface{}
+		name string
+	}{
+		{&native.init, "zmin_init"},
+		{&native.version, "zmin_get_version"},
+		{&native.errorMessage, "zmin_get_error_message"},
+		{&native.minifyInto, "zmin_minify_into"},
+		{&native.validate, "zmin_validate"},
+		{&native.createMinifier, "zmin_create_minifier"},
+		{&native.destroyMinifier, "zmin_destroy_minifier"},
+	}
+	for _, f := range required {
+		sym, err := librarySymbol(lib, f.name)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrIncompatibleLibrary, name, err)
+		}
+		purego.RegisterFunc(f.fn, sym)
+	}
+
+	optional := []struct {
+		fn   interface{}
+		name string
+	}{
+		{&native.initChecked, "zmin_init_checked"},
+		{&native.minifyBatch, "zmin_minify_batch"},
+		{&native.minifierMinifyInto, "zmin_minifier_minify_into"},
+		{&native.minifierSetThreads, "zmin_minifier_set_threads"},
+		{&native.modeSupported, "zmin_mode_supported"},
+		{&native.getSIMDFeatures, "zmin_get_simd_features"},
+		{&native.getMaxInputSize, "zmin_get_max_input_size"},
+		{&native.setSIMDLevel, "zmin_set_simd_level"},
+	}
+	for _, f := range optional {
+		if sym, err := librarySymbol(lib, f.name); err == nil {
+			purego.RegisterFunc(f.fn, sym)
+		}
+	}
+	return nil
+}
+
+// nativeInit initializes the native library and returns its error code
+func nativeInit() int {
+	if native.initChecked != nil {
+		return int(native.initChecked())
+	}
+	native.init()
+	return 0
+}
+
+func nativeVersion() string {
+	return native.version()
+}
+
+func nativeErrorMessage(code int) string {
+	return native.errorMessage(int32(code))
+}
+
+// nativeMinifyInto minifies src into dst and returns the output size and
+// error code. The Go garbage collector does not move heap memory, so both
+// slices are handed to the native library directly.
+func nativeMinifyInto(dst, src []byte, mode ProcessingMode) (int, int) {
+	var size uintptr
+	code := native.minifyInto(bytesPtr(src), uintptr(len(src)), int32(mode),
+		bytesPtr(dst), uintptr(len(dst)), &size)
+	runtime.KeepAlive(src)
+	runtime.KeepAlive(dst)
+	return int(size), int(code)
+}
+
+// nativeMinify minifies src into a Go buffer. Results are not returned in
+// native memory, as purego cannot receive C structures on every platform.
+func nativeMinify(src []byte, mode ProcessingMode) (output []byte, release func(), code int) {
+	output = make([]byte, len(src))
+	size, code := nativeMinifyInto(output, src, mode)
+	if code != 0 {
+		return nil, nil, code
+	}
+	return output[:size], func() {}, 0
+}
+
+// nativeMinifyBatch minifies the documents stored back to back in src, as
+// zmin_minify_batch does, one call at a time with libraries predating it
+func nativeMinifyBatch(src []byte, sizes []uintptr, mode ProcessingMode, dst []byte, outputSizes []uintptr, codes []int32) int {
+	if native.minifyBatch == nil {
+		if mode < ECO || mode > TURBO {
+			return -3
+		}
+		offset := 0
+		for i, size := range sizes {
+			end := offset + int(size)
+			n, code := nativeMinifyInto(dst[offset:end], src[offset:end], mode)
+			outputSizes[i], codes[i] = uintptr(n), int32(code)
+			offset = end
+		}
+		return 0
+	}
+	code := native.minifyBatch(bytesPtr(src), &sizes[0], uintptr(len(sizes)), int32(mode),
+		bytesPtr(dst), &outputSizes[0], &codes[0])
+	runtime.KeepAlive(src)
+	runtime.KeepAlive(dst)
+	return int(code)
+}
+
+func nativeValidate(src []byte) int {
+	code := native.validate(bytesPtr(src), uintptr(len(src)))
+	runtime.KeepAlive(src)
+	return int(code)
+}
+
+// nativeCreateMinifier creates a native context, or returns nil, in which
+// case Minifiers use the stateless functions. Libraries too old to minify
+// with a context into the caller's buffer get none.
+func nativeCreateMinifier(mode ProcessingMode, threads int) unsafe.Pointer {
+	if native.minifierMinifyInto == nil {
+		return nil
+	}
+	handle := native.createMinifier(int32(mode))
+	if handle != nil && threads > 0 && native.minifierSetThreads != nil {
+		native.minifierSetThreads(handle, int32(threads))
+	}
+	return handle
+}
+
+func nativeDestroyMinifier(handle unsafe.Pointer) {
+	native.destroyMinifier(handle)
+}
+
+func nativeMinifierMinifyInto(handle unsafe.Pointer, dst, src []byte) (int, int) {
+	var size uintptr
+	code := native.minifierMinifyInto(handle, bytesPtr(src), uintptr(len(src)),
+		bytesPtr(dst), uintptr(len(dst)), &size)
+	runtime.KeepAlive(src)
+	runtime.KeepAlive(dst)
+	return int(size), int(code)
+}
+
+// nativeModeSupported reports whether the library supports mode. Libraries
+// predating the capability functions are reported as supporting every mode,
+// with no SIMD features and no size limit.
+func nativeModeSupported(mode ProcessingMode) bool {
+	return native.modeSupported == nil || native.modeSupported(int32(mode)) != 0
+}
+
+func nativeSIMDFeatures() uint {
+	if native.getSIMDFeatures == nil {
+		return 0
+	}
+	return uint(native.getSIMDFeatures())
+}
+
+func nativeMaxInputSize() uint64 {
+	if native.getMaxInputSize == nil {
+		return ^uint64(0)
+	}
+	return uint64(native.getMaxInputSize())
+}
+
+// nativeSetSIMDLevel limits the SIMD level and returns the error code, or 1
+// if the library is too old to do so
+func nativeSetSIMDLevel(name string) int {
+	if native.setSIMDLevel == nil {
+		return 1
+	}
+	return int(native.setSIMDLevel(name))
+}
+
+// bytesPtr returns a pointer to the first element of b, or nil if b is empty
+func bytesPtr(b []byte) *byte {
+	if len(b) == 0 {
+		return nil
+	}
+	return &b[0]
+}
@@ -0,0 +1,44 @@
+//go:build zmin_dlopen || zmin_embed
+
+package zmin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfiguredLibrary(t *testing.T) {
+	dir := t.TempDir()
+	lib := filepath.Join(dir, libraryName())
+	if err := os.WriteFile(lib, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	empty := t.TempDir()
+	defer SetLibraryPath("")
+
+	t.Setenv(EnvLibraryPath, "")
+	if path, err := configuredLibrary(); path != "" || err != nil {
+		t.Errorf("Expected no library, got %q (%v)", path, err)
+	}
+
+	// Directories are searched in order, and files are taken as they are
+	t.Setenv(EnvLibraryPath, empty+string(os.PathListSeparator)+dir)
+	if path, err := configuredLibrary(); path != lib || err != nil {
+		t.Errorf("Expected %s, got %q (%v)", lib, path, err)
+	}
+	other := filepath.Join(empty, "libzmin-custom.so")
+	if err := os.WriteFile(other, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	SetLibraryPath(other)
+	if path, err := configuredLibrary(); path != other || err != nil {
+		t.Errorf("Expected SetLibraryPath to take precedence, got %q (%v)", path, err)
+	}
+
+	SetLibraryPath(filepath.Join(empty, "missing"))
+	if _, err := configuredLibrary(); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected a missing library to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,339 @@
+//go:build zmin_wasm && !cgo && !zmin_dlopen && !zmin_embed
+
+package zmin
+
+// The zmin_wasm build tag embeds libzmin compiled to WebAssembly and runs it
+// with github.com/tetratelabs/wazero when cgo is unavailable, so that the
+// package works on any platform Go supports without a native library. It
+// embeds zmin.wasm, which go generate rebuilds from src/wasm/exports.zig and
+// releases of the module include.
+
+//go:generate sh -c "cd ../.. && zig build-lib src/wasm/exports.zig -target wasm32-freestanding -dynamic -rdynamic -O ReleaseSmall -femit-bin=bindings/go/zmin.wasm"
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+// backendName identifies the backend compiled in
+const backendName = "wasm"
+
+//go:embed zmin.wasm
+var zminWasm []byte
+
+// The compiled module and the instances not in use. An instance runs one
+// call at a time, so concurrent calls each take their own.
+var wasm struct {
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+
+	mu   sync.Mutex
+	idle []*wasmInstance
+}
+
+// wasmInstance is an instance of the module and its exported functions
+type wasmInstance struct {
+	mod           api.Module
+	alloc         api.Function
+	free          api.Function
+	minifyInto    api.Function
+	validate      api.Function
+	version       api.Function
+	errorMessage  api.Function
+	modeSupported api.Function
+	maxInputSize  api.Function
+	broken        bool // memory could not be freed
+}
+
+var (
+	loadOnce sync.Once
+	loadErr  error
+)
+
+// nativeLoad compiles the embedded module and instantiates it once to check
+// that it has the expected exports
+func nativeLoad() error {
+	loadOnce.Do(func() {
+		ctx := context.Background()
+		wasm.runtime = wazero.NewRuntime(ctx)
+		compiled, err := wasm.runtime.CompileModule(ctx, zminWasm)
+		if err != nil {
+			loadErr = fmt.Errorf("%w: %v", ErrLibraryUnavailable, err)
+			return
+		}
+		wasm.compiled = compiled
+		inst, err := newWasmInstance()
+		if err != nil {
+			loadErr = err
+			return
+		}
+		wasm.idle = append(wasm.idle, inst)
+	})
+	return loadErr
+}
+
+// newWasmInstance instantiates the compiled module
+func newWasmInstance() (*wasmInstance, error) {
+	// Anonymous modules can be instantiated any number of times
+	mod, err := wasm.runtime.InstantiateModule(context.Background(), wasm.compiled, wazero.NewModuleConfig().WithName(""))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrLibraryUnavailable, err)
+	}
+	inst := &wasmInstance{
+		mod:           mod,
+		alloc:         mod.ExportedFunction("zmin_alloc"),
+		free:          mod.ExportedFunction("zmin_free"),
+		minifyInto:    mod.ExportedFunction("zmin_minify_into"),
+		validate:      mod.ExportedFunction("zmin_validate"),
+		version:       mod.ExportedFunction("zmin_version"),
+		errorMessage:  mod.ExportedFunction("zmin_get_error_message"),
+		modeSupported: mod.ExportedFunction("zmin_mode_supported"),
+		maxInputSize:  mod.ExportedFunction("zmin_get_max_input_size"),
+	}
+	if inst.alloc == nil || inst.free == nil || inst.minifyInto == nil || inst.validate == nil || inst.version == nil {
+		mod.Close(context.Background())
+		return nil, fmt.Errorf("%w: zmin.wasm lacks required exports", ErrIncompatibleLibrary)
+	}
+	return inst, nil
+}
+
+// withInstance calls fn with an idle instance and returns its error code. fn
+// frees the memory it allocates. An instance that traps, or fails to free its
+// memory, is discarded, so that a failure in one call cannot affect the next.
+func withInstance(fn func(inst *wasmInstance) (int, error)) int {
+	wasm.mu.Lock()
+	var inst *wasmInstance
+	if n := len(wasm.idle); n > 0 {
+		inst, wasm.idle = wasm.idle[n-1], wasm.idle[:n-1]
+	}
+	wasm.mu.Unlock()
+	if inst == nil {
+		var err error
+		if inst, err = newWasmInstance(); err != nil {
+			return -99
+		}
+	}
+
+	code, err := fn(inst)
+	if err != nil {
+		inst.mod.Close(context.Background())
+		return -99
+	}
+	if inst.broken {
+		inst.mod.Close(context.Background())
+		return code
+	}
+	wasm.mu.Lock()
+	wasm.idle = append(wasm.idle, inst)
+	wasm.mu.Unlock()
+	return code
+}
+
+// call calls f and returns its single result as a signed 32-bit value
+func call(f api.Function, params ...uint64) (int32, error) {
+	results, err := f.Call(context.Background(), params...)
+	if err != nil {
+		return 0, err
+	}
+	return int32(uint32(results[0])), nil
+}
+
+// write copies b into newly allocated module memory and returns its address,
+// or 0 if the module is out of memory. At least one byte is allocated, as
+// empty allocations have no address in module memory; the memory is freed
+// with release(ptr, len(b)+1).
+func (inst *wasmInstance) write(b []byte) (uint32, error) {
+	ptr, err := call(inst.alloc, uint64(len(b)+1))
+	if err != nil || ptr == 0 {
+		return 0, err
+	}
+	if !inst.mod.Memory().Write(uint32(ptr), b) {
+		inst.release(uint32(ptr), len(b)+1)
+		return 0, nil
+	}
+	return uint32(ptr), nil
+}
+
+// release frees size bytes at ptr. The module's allocator only reclaims its
+// latest allocation, so memory is released in the reverse order of its
+// allocation.
+func (inst *wasmInstance) release(ptr uint32, size int) {
+	if _, err := inst.free.Call(context.Background(), uint64(ptr), uint64(size)); err != nil {
+		inst.broken = true
+	}
+}
+
+// readString reads the NUL-terminated string at ptr
+func (inst *wasmInstance) readString(ptr uint32) string {
+	mem := inst.mod.Memory()
+	var s []byte
+	for {
+		b, ok := mem.ReadByte(ptr)
+		if !ok || b == 0 {
+			return string(s)
+		}
+		s = append(s, b)
+		ptr++
+	}
+}
+
+// nativeInit initializes the native library and returns its error code.
+// Instances need no initialization, so there is nothing to do.
+func nativeInit() int {
+	return 0
+}
+
+func nativeVersion() string {
+	var version string
+	withInstance(func(inst *wasmInstance) (int, error) {
+		ptr, err := call(inst.version)
+		version = inst.readString(uint32(ptr))
+		return 0, err
+	})
+	return version
+}
+
+func nativeErrorMessage(code int) string {
+	message := "Unknown error"
+	withInstance(func(inst *wasmInstance) (int, error) {
+		if inst.errorMessage == nil {
+			return 0, nil
+		}
+		ptr, err := call(inst.errorMessage, api.EncodeI32(int32(code)))
+		message = inst.readString(uint32(ptr))
+		return 0, err
+	})
+	return message
+}
+
+// nativeMinifyInto minifies src into dst and returns the output size and
+// error code. Both are copied in and out of module memory.
+func nativeMinifyInto(dst, src []byte, mode ProcessingMode) (int, int) {
+	var size uint32
+	code := withInstance(func(inst *wasmInstance) (int, error) {
+		in, err := inst.write(src)
+		if err != nil || in == 0 {
+			return -2, err
+		}
+		defer inst.release(in, len(src)+1)
+		// The output is followed by the size, aligned for a u32
+		out, err := call(inst.alloc, uint64(len(dst)+8))
+		if err != nil || out == 0 {
+			return -2, err
+		}
+		defer inst.release(uint32(out), len(dst)+8)
+		sizePtr := (uint32(out) + uint32(len(dst)) + 3) &^ 3
+		code, err := call(inst.minifyInto, uint64(in), uint64(len(src)), api.EncodeI32(int32(mode)),
+			uint64(out), uint64(len(dst)), uint64(sizePtr))
+		if err != nil {
+			return 0, err
+		}
+		mem := inst.mod.Memory()
+		size, _ = mem.ReadUint32Le(sizePtr)
+		if code == 0 {
+			output, _ := mem.Read(uint32(out), size)
+			copy(dst, output)
+		}
+		return int(code), nil
+	})
+	return int(size), code
+}
+
+// nativeMinify minifies src into a Go buffer, as module memory cannot be
+// handed out
+func nativeMinify(src []byte, mode ProcessingMode) (output []byte, release func(), code int) {
+	output = make([]byte, len(src))
+	size, code := nativeMinifyInto(output, src, mode)
+	if code != 0 {
+		return nil, nil, code
+	}
+	return output[:size], func() {}, 0
+}
+
+// nativeMinifyBatch minifies the documents stored back to back in src one at
+// a time, as zmin_minify_batch does
+func nativeMinifyBatch(src []byte, sizes []uintptr, mode ProcessingMode, dst []byte, outputSizes []uintptr, codes []int32) int {
+	if mode < ECO || mode > TURBO {
+		return -3
+	}
+	offset := 0
+	for i, size := range sizes {
+		end := offset + int(size)
+		n, code := nativeMinifyInto(dst[offset:end], src[offset:end], mode)
+		outputSizes[i], codes[i] = uintptr(n), int32(code)
+		offset = end
+	}
+	return 0
+}
+
+func nativeValidate(src []byte) int {
+	return withInstance(func(inst *wasmInstance) (int, error) {
+		in, err := inst.write(src)
+		if err != nil || in == 0 {
+			return -2, err
+		}
+		defer inst.release(in, len(src)+1)
+		code, err := call(inst.validate, uint64(in), uint64(len(src)))
+		return int(code), err
+	})
+}
+
+// nativeCreateMinifier returns nil: the module has no minifier contexts, so
+// Minifiers use the stateless functions
+func nativeCreateMinifier(mode ProcessingMode, threads int) unsafe.Pointer {
+	return nil
+}
+
+func nativeDestroyMinifier(handle unsafe.Pointer) {}
+
+func nativeMinifierMinifyInto(handle unsafe.Pointer, dst, src []byte) (int, int) {
+	return 0, -99
+}
+
+func nativeModeSupported(mode ProcessingMode) bool {
+	supported := true
+	withInstance(func(inst *wasmInstance) (int, error) {
+		if inst.modeSupported == nil {
+			return 0, nil
+		}
+		result, err := call(inst.modeSupported, api.EncodeI32(int32(mode)))
+		supported = result != 0
+		return 0, err
+	})
+	return supported
+}
+
+// nativeSIMDFeatures returns no features: the module runs scalar code
+func nativeSIMDFeatures() uint {
+	return 0
+}
+
+func nativeMaxInputSize() uint64 {
+	limit := ^uint64(0)
+	withInstance(func(inst *wasmInstance) (int, error) {
+		if inst.maxInputSize == nil {
+			return 0, nil
+		}
+		results, err := inst.maxInputSize.Call(context.Background())
+		if err == nil {
+			limit = uint64(uint32(results[0]))
+		}
+		return 0, err
+	})
+	return limit
+}
+
+// nativeSetSIMDLevel accepts only scalar code, which is all the module runs
+func nativeSetSIMDLevel(name string) int {
+	if strings.EqualFold(name, "scalar") {
+		return 0
+	}
+	return -14
+}
@@ -0,0 +1,72 @@
+package zmin
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+)
+
+// LineError reports a line of a JSON Lines stream that could not be minified
+type LineError struct {
+	Line int // 1-based line number
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// MinifyNDJSON minifies a newline-delimited JSON (JSON Lines) stream from r to
+// w, one value per line. Blank lines are skipped. Lines that are not valid
+// JSON are left out of the output and reported in Stats.Errors rather than
+// failing the stream; the returned error is reserved for I/O failures.
+func MinifyNDJSON(r io.Reader, w io.Writer, mode ProcessingMode) (Stats, error) {
+	start := time.Now()
+	stats, err := minifyNDJSON(r, w, mode)
+	stats.finish(mode, start)
+	return stats, err
+}
+
+// minifyNDJSON implements MinifyNDJSON, leaving timing to the caller
+func minifyNDJSON(r io.Reader, w io.Writer, mode ProcessingMode) (Stats, error) {
+	var stats Stats
+	br := bufio.NewReader(r)
+	bw := bufio.NewWriter(w)
+	var buf []byte
+	for {
+		line, readErr := br.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return stats, readErr
+		}
+		if len(line) > 0 {
+			stats.Lines++
+			stats.InputBytes += int64(len(line))
+		}
+
+		if value := bytes.TrimSpace(line); len(value) > 0 {
+			var err error
+			buf, err = minifyAppend(buf[:0], value, mode)
+			if err != nil {
+				stats.Errors = append(stats.Errors, &LineError{Line: stats.Lines, Err: locateError(value, err)})
+			} else {
+				buf = append(buf, '\n')
+				if _, err := bw.Write(buf); err != nil {
+					return stats, err
+				}
+				stats.Values++
+				stats.OutputBytes += int64(len(buf))
+			}
+		}
+
+		if readErr == io.EOF {
+			return stats, bw.Flush()
+		}
+	}
+}
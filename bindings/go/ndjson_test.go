@@ -0,0 +1,47 @@
+package zmin
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMinifyNDJSON(t *testing.T) {
+	input := "{ \"a\": 1 }\n\n  [1, 2]  \r\n{\"broken\": }\n\"last\""
+
+	var out bytes.Buffer
+	stats, err := MinifyNDJSON(strings.NewReader(input), &out, SPORT)
+	if err != nil {
+		t.Fatalf("MinifyNDJSON failed: %v", err)
+	}
+
+	expected := "{\"a\":1}\n[1,2]\n\"last\"\n"
+	if out.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, out.String())
+	}
+	if stats.Lines != 5 || stats.Values != 3 {
+		t.Errorf("Expected 5 lines and 3 values, got %+v", stats)
+	}
+	if stats.InputBytes != int64(len(input)) || stats.OutputBytes != int64(len(expected)) {
+		t.Errorf("Unexpected byte counts: %+v", stats)
+	}
+
+	if len(stats.Errors) != 1 {
+		t.Fatalf("Expected 1 line error, got %v", stats.Errors)
+	}
+	if stats.Errors[0].Line != 4 || !errors.Is(stats.Errors[0], ErrInvalidJSON) {
+		t.Errorf("Unexpected line error: %v", stats.Errors[0])
+	}
+}
+
+func TestMinifyNDJSONEmpty(t *testing.T) {
+	var out bytes.Buffer
+	stats, err := MinifyNDJSON(strings.NewReader(""), &out, SPORT)
+	if err != nil {
+		t.Fatalf("MinifyNDJSON failed: %v", err)
+	}
+	if out.Len() != 0 || stats.Lines != 0 {
+		t.Errorf("Expected no output, got %q (%+v)", out.String(), stats)
+	}
+}
@@ -0,0 +1,315 @@
+package zmin
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// utf8BOM is the UTF-8 encoding of U+FEFF
+const utf8BOM = "\xef\xbb\xbf"
+
+// Options configures the additional processing performed by MinifyWithOptions.
+// The zero value adds nothing on top of plain minification.
+type Options struct {
+	// MaxInputSize rejects inputs larger than this many bytes with
+	// ErrInputTooLarge. Zero means no limit.
+	MaxInputSize int
+
+	// MaxOutputSize rejects documents whose output would be larger than this
+	// many bytes with ErrOutputTooLarge. Zero means no limit.
+	MaxOutputSize int
+
+	// AllowComments accepts // and /* */ comments in the input, as found in
+	// JSONC configuration files, and strips them from the output
+	AllowComments bool
+
+	// Dialect is the syntax accepted in the input. The output is always
+	// strict JSON.
+	Dialect Dialect
+
+	// MaxDepth rejects documents nesting objects and arrays more than this
+	// many levels deep with ErrTooDeep. Zero means no limit.
+	MaxDepth int
+
+	// MaxStringLen rejects documents containing a string or object key longer
+	// than this many bytes, as written between the quotes, with
+	// ErrStringTooLong. Zero means no limit.
+	MaxStringLen int
+
+	// MaxFieldBytes maps object keys to the maximum minified size, in bytes,
+	// of the value stored under that key. The limit applies to every member
+	// with a matching key, at any depth, and is measured after redaction. A
+	// nil map imposes no limits.
+	MaxFieldBytes map[string]int
+
+	// StripBOM removes a leading UTF-8 byte order mark from the input, which
+	// would otherwise be rejected as invalid JSON.
+	StripBOM bool
+
+	// EmitBOM prepends a single UTF-8 byte order mark to the output for
+	// consumers that expect one. Off by default to keep RFC 8259 output.
+	EmitBOM bool
+
+	// RedactPaths lists JSON Pointers (RFC 6901) whose values are replaced
+	// with RedactToken. A "*" segment matches any array index or object key,
+	// so "/users/*/ssn" redacts the ssn of every user.
+	RedactPaths []string
+
+	// RedactRules redacts the values of object members whose keys match a
+	// rule, wherever they appear, in the same way as RedactPaths
+	RedactRules []RedactRule
+
+	// RedactToken is the string that replaces redacted values. Defaults to
+	// "***".
+	RedactToken string
+
+	// IncludePaths keeps only the listed values, their subtrees and the
+	// containers leading to them, dropping every other member and element.
+	// Paths are JSON Pointers, or dotted key paths such as "user.name" when
+	// they do not start with '/'. A "*" segment matches any array index or
+	// object key. Array indexes refer to the input.
+	IncludePaths []string
+
+	// ExcludePaths drops the listed values and their subtrees, in the syntax
+	// of IncludePaths, so "debug" removes the debug member and "debug.*"
+	// empties it. Exclusion takes precedence over inclusion.
+	ExcludePaths []string
+
+	// OmitNull drops object members whose value is null
+	OmitNull bool
+
+	// OmitEmpty drops object members whose value is an empty object or
+	// array, including containers left empty by the other options. Array
+	// elements are kept.
+	OmitEmpty bool
+
+	// KeyTransform renames object keys, given the JSON Pointer of the object
+	// and the unescaped key, to convert naming conventions with SnakeCase,
+	// CamelCase or a custom function. Paths, rules and limits in the other
+	// options refer to the original keys; sorting and duplicate detection
+	// use the new ones.
+	KeyTransform func(path, key string) string
+
+	// SortKeys emits the members of every object sorted by key, compared
+	// byte-wise after unescaping, so that equal documents minify identically.
+	// Members with equal keys keep their relative order.
+	SortKeys bool
+
+	// DuplicateKeys decides what happens to objects that contain the same key
+	// more than once. The default, KeepAll, passes them through unchanged.
+	DuplicateKeys DuplicateKeyPolicy
+
+	// ASCIIOnly escapes every non-ASCII character in strings and keys as
+	// \uXXXX, using surrogate pairs outside the Basic Multilingual Plane
+	ASCIIOnly bool
+
+	// HTMLSafe escapes <, >, &, U+2028 and U+2029 in strings and keys so the
+	// output can be embedded in an HTML <script> element
+	HTMLSafe bool
+
+	// NormalizeNumbers rewrites numbers with a fraction or exponent in their
+	// shortest round-trip form with a lowercase exponent, so 1.50E+2 becomes
+	// 150. Integers are kept verbatim, except that -0 becomes 0, as are
+	// numbers too large for a float64, such as 1e400.
+	NormalizeNumbers bool
+
+	// RejectUnsafeIntegers fails with ErrUnsafeInteger on integers whose
+	// magnitude exceeds 2^53, which JavaScript consumers cannot represent
+	// exactly
+	RejectUnsafeIntegers bool
+}
+
+// DuplicateKeyPolicy is the treatment of duplicate object keys
+type DuplicateKeyPolicy int
+
+const (
+	// KeepAll passes duplicate keys through unchanged
+	KeepAll DuplicateKeyPolicy = iota
+	// Reject fails with ErrDuplicateKey
+	Reject
+	// KeepFirst keeps the first member with a given key
+	KeepFirst
+	// KeepLast keeps the value of the last member with a given key, at the
+	// position of the first, matching JavaScript's JSON.parse
+	KeepLast
+)
+
+// Dialect is a JSON variant accepted as input
+type Dialect int
+
+const (
+	// StrictJSON accepts RFC 8259 JSON only
+	StrictJSON Dialect = iota
+	// JSONC accepts JSON with // and /* */ comments, like AllowComments
+	JSONC
+	// JSON5 accepts JSON5: comments, trailing commas, single-quoted and
+	// multi-line strings, unquoted keys, hexadecimal numbers, Infinity and
+	// NaN. Infinity and NaN become null, as in JSON.stringify.
+	JSON5
+)
+
+// FieldTooLargeError is returned when a value exceeds its MaxFieldBytes limit.
+// It matches ErrFieldTooLarge with errors.Is.
+type FieldTooLargeError struct {
+	Key   string // object key whose value was too large
+	Size  int    // minified size of the value in bytes
+	Limit int    // configured limit for the key
+}
+
+func (e *FieldTooLargeError) Error() string {
+	return fmt.Sprintf("%s: %q is %d bytes (limit %d)", ErrFieldTooLarge, e.Key, e.Size, e.Limit)
+}
+
+// Is reports whether target is ErrFieldTooLarge
+func (e *FieldTooLargeError) Is(target error) bool {
+	return target == ErrFieldTooLarge
+}
+
+// MinifyWithOptions minifies JSON data using the specified processing mode and
+// applies the checks and transformations configured in opts
+func MinifyWithOptions(input interface{}, mode ProcessingMode, opts Options) (string, error) {
+	m := &Minifier{mode: mode, opts: opts}
+	return m.minify(input)
+}
+
+// minify implements MinifyWithOptions with the configuration of m
+func (m *Minifier) minify(input interface{}) (string, error) {
+	return m.minifyStats(input, nil)
+}
+
+// minifyStats is like minify, and records the mode used in stats unless it
+// is nil
+func (m *Minifier) minifyStats(input interface{}, stats *Stats) (string, error) {
+	opts := &m.opts
+	jsonStr, err := m.input(input)
+	if err != nil {
+		return "", err
+	}
+	if opts.StripBOM {
+		jsonStr = strings.TrimPrefix(jsonStr, utf8BOM)
+	}
+	if opts.MaxInputSize > 0 && len(jsonStr) > opts.MaxInputSize {
+		return "", ErrInputTooLarge
+	}
+	switch {
+	case opts.Dialect == JSON5:
+		r := &relaxer{in: []byte(jsonStr), json5: true}
+		strict, err := r.run()
+		if err != nil {
+			return "", err
+		}
+		jsonStr = string(strict)
+	case opts.AllowComments || opts.Dialect == JSONC:
+		stripped, err := stripComments([]byte(jsonStr))
+		if err != nil {
+			return "", err
+		}
+		jsonStr = string(stripped)
+	}
+
+	into := m.minifyInto
+	if stats != nil {
+		into = func(dst, src []byte) (int, error) {
+			n, mode, err := m.minifyIntoMode(dst, src)
+			stats.Mode = mode
+			return n, err
+		}
+	}
+	if opts.MaxOutputSize > 0 {
+		into = limitOutput(into, opts.MaxOutputSize)
+	}
+	output, err := minifyStringWith(jsonStr, m.pool, into)
+	if err != nil {
+		return "", err
+	}
+	if opts.needsTransform() {
+		t, err := newTransformer(opts)
+		if err != nil {
+			return "", err
+		}
+		transformed, err := t.run([]byte(output))
+		if err != nil {
+			return "", err
+		}
+		output = string(transformed)
+	}
+
+	if opts.EmitBOM {
+		output = utf8BOM + output
+	}
+	if opts.MaxOutputSize > 0 && len(output) > opts.MaxOutputSize {
+		return "", ErrOutputTooLarge
+	}
+	return output, nil
+}
+
+// inputLimit returns the largest raw input accepted under MaxInputSize, which
+// applies after a byte order mark is stripped, or zero for no limit
+func (o *Options) inputLimit() int {
+	if o.MaxInputSize <= 0 {
+		return 0
+	}
+	if o.StripBOM {
+		return o.MaxInputSize + len(utf8BOM)
+	}
+	return o.MaxInputSize
+}
+
+// input converts input to a string like toJSONString, but rejects oversized
+// input with ErrInputTooLarge before copying it or reading it in full
+func (m *Minifier) input(input interface{}) (string, error) {
+	limit := m.opts.inputLimit()
+	if limit == 0 {
+		return toJSONString(input)
+	}
+	switch v := input.(type) {
+	case string:
+		if len(v) > limit {
+			return "", ErrInputTooLarge
+		}
+	case []byte:
+		if len(v) > limit {
+			return "", ErrInputTooLarge
+		}
+	case io.Reader:
+		data, err := io.ReadAll(io.LimitReader(v, int64(limit)+1))
+		if err != nil {
+			return "", err
+		}
+		if len(data) > limit {
+			return "", ErrInputTooLarge
+		}
+		return string(data), nil
+	}
+	return toJSONString(input)
+}
+
+// limitOutput caps the output buffer handed to into at limit bytes, turning
+// ErrBufferTooSmall into ErrOutputTooLarge
+func limitOutput(into minifyFunc, limit int) minifyFunc {
+	return func(dst, src []byte) (int, error) {
+		if len(dst) > limit {
+			dst = dst[:limit]
+		}
+		n, err := into(dst, src)
+		if err == ErrBufferTooSmall {
+			return n, ErrOutputTooLarge
+		}
+		return n, err
+	}
+}
+
+// needsTransform reports whether opts requires a pass over the minified output
+func (o *Options) needsTransform() bool {
+	return o.MaxDepth > 0 || o.MaxStringLen > 0 || len(o.MaxFieldBytes) > 0 || len(o.RedactPaths) > 0 ||
+		len(o.RedactRules) > 0 || len(o.IncludePaths) > 0 || len(o.ExcludePaths) > 0 || o.OmitNull || o.OmitEmpty ||
+		o.KeyTransform != nil || o.SortKeys || o.DuplicateKeys != KeepAll || o.ASCIIOnly || o.HTMLSafe ||
+		o.NormalizeNumbers || o.RejectUnsafeIntegers
+}
+
+// active reports whether opts changes anything compared to plain minification
+func (o *Options) active() bool {
+	return o.needsTransform() || o.MaxInputSize > 0 || o.MaxOutputSize > 0 || o.StripBOM || o.EmitBOM || o.AllowComments ||
+		o.Dialect != StrictJSON
+}
@@ -0,0 +1,317 @@
+package zmin
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMinifyWithOptionsMaxFieldBytes(t *testing.T) {
+	input := `{
+		"title": "short",
+		"description": "this description is far too long",
+		"tags": ["a", "b"]
+	}`
+
+	// No limits behaves like plain minification
+	output, err := MinifyWithOptions(input, SPORT, Options{})
+	if err != nil {
+		t.Fatalf("MinifyWithOptions failed: %v", err)
+	}
+	expected := `{"title":"short","description":"this description is far too long","tags":["a","b"]}`
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+
+	// Limits that are respected pass; containers are measured minified
+	opts := Options{MaxFieldBytes: map[string]int{"title": 7, "tags": 9}}
+	if _, err := MinifyWithOptions(input, SPORT, opts); err != nil {
+		t.Errorf("Expected limits to be respected, got %v", err)
+	}
+
+	// Exceeding a limit reports the key and size
+	opts = Options{MaxFieldBytes: map[string]int{"description": 16}}
+	_, err = MinifyWithOptions(input, SPORT, opts)
+	if !errors.Is(err, ErrFieldTooLarge) {
+		t.Fatalf("Expected ErrFieldTooLarge, got %v", err)
+	}
+	var fieldErr *FieldTooLargeError
+	if !errors.As(err, &fieldErr) {
+		t.Fatalf("Expected *FieldTooLargeError, got %T", err)
+	}
+	if fieldErr.Key != "description" || fieldErr.Size != 34 || fieldErr.Limit != 16 {
+		t.Errorf("Unexpected error details: %+v", fieldErr)
+	}
+
+	// Nested members are checked too
+	nested := `{"items": [{"tags": ["a", "b", "c"]}]}`
+	opts = Options{MaxFieldBytes: map[string]int{"tags": 9}}
+	if _, err := MinifyWithOptions(nested, SPORT, opts); !errors.Is(err, ErrFieldTooLarge) {
+		t.Errorf("Expected ErrFieldTooLarge for nested field, got %v", err)
+	}
+}
+
+func TestMinifyWithOptionsBOM(t *testing.T) {
+	input := utf8BOM + `{ "key": "value" }`
+
+	// A BOM is not valid JSON unless it is stripped
+	if _, err := MinifyWithOptions(input, SPORT, Options{}); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON for BOM input, got %v", err)
+	}
+
+	output, err := MinifyWithOptions(input, SPORT, Options{StripBOM: true})
+	if err != nil {
+		t.Fatalf("MinifyWithOptions failed: %v", err)
+	}
+	if output != `{"key":"value"}` {
+		t.Errorf("Expected BOM to be stripped, got %q", output)
+	}
+
+	// Round trip: strip the input BOM and emit exactly one on output
+	output, err = MinifyWithOptions(input, SPORT, Options{StripBOM: true, EmitBOM: true})
+	if err != nil {
+		t.Fatalf("MinifyWithOptions failed: %v", err)
+	}
+	if output != utf8BOM+`{"key":"value"}` {
+		t.Errorf("Expected a single leading BOM, got %q", output)
+	}
+}
+
+func TestMinifyWithOptionsRedactPaths(t *testing.T) {
+	input := `{
+		"users": [
+			{"name": "Alice", "ssn": "123-45-6789"},
+			{"name": "Bob", "ssn": "987-65-4321", "card": {"number": "4111"}}
+		],
+		"ssn": "kept",
+		"a/b": {"c~d": 1}
+	}`
+
+	opts := Options{RedactPaths: []string{"/users/*/ssn", "/users/1/card", "/a~1b/c~0d"}}
+	output, err := MinifyWithOptions(input, SPORT, opts)
+	if err != nil {
+		t.Fatalf("MinifyWithOptions failed: %v", err)
+	}
+	expected := `{"users":[{"name":"Alice","ssn":"***"},{"name":"Bob","ssn":"***","card":"***"}],"ssn":"kept","a/b":{"c~d":"***"}}`
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+
+	// Custom token, and the empty pointer redacts the whole document
+	output, err = MinifyWithOptions(input, SPORT, Options{RedactPaths: []string{""}, RedactToken: "[hidden]"})
+	if err != nil {
+		t.Fatalf("MinifyWithOptions failed: %v", err)
+	}
+	if output != `"[hidden]"` {
+		t.Errorf("Expected whole document to be redacted, got %q", output)
+	}
+
+	if _, err := MinifyWithOptions(input, SPORT, Options{RedactPaths: []string{"users"}}); err == nil {
+		t.Error("Expected error for invalid JSON pointer")
+	}
+}
+
+func TestMinifyWithOptionsFieldPaths(t *testing.T) {
+	input := `{
+		"id": 1,
+		"user": {"name": "Alice", "email": "a@example.com", "tags": ["x", "y", "z"]},
+		"items": [{"id": 1, "blob": "..."}, {"id": 2, "blob": "..."}],
+		"debug": {"trace": [1, 2, 3], "timing": 5}
+	}`
+
+	cases := []struct {
+		opts     Options
+		expected string
+	}{
+		{Options{ExcludePaths: []string{"debug"}},
+			`{"id":1,"user":{"name":"Alice","email":"a@example.com","tags":["x","y","z"]},"items":[{"id":1,"blob":"..."},{"id":2,"blob":"..."}]}`},
+		{Options{ExcludePaths: []string{"debug.*", "items.*.blob", "/user/tags/1"}},
+			`{"id":1,"user":{"name":"Alice","email":"a@example.com","tags":["x","z"]},"items":[{"id":1},{"id":2}],"debug":{}}`},
+		{Options{IncludePaths: []string{"id", "/user/name", "items.*.id"}},
+			`{"id":1,"user":{"name":"Alice"},"items":[{"id":1},{"id":2}]}`},
+		{Options{IncludePaths: []string{"user"}, ExcludePaths: []string{"user.email"}},
+			`{"user":{"name":"Alice","tags":["x","y","z"]}}`},
+		{Options{IncludePaths: []string{"id.x", "missing"}}, `{}`},
+	}
+	for _, c := range cases {
+		output, err := MinifyWithOptions(input, SPORT, c.opts)
+		if err != nil {
+			t.Fatalf("MinifyWithOptions(%+v) failed: %v", c.opts, err)
+		}
+		if output != c.expected {
+			t.Errorf("%+v: expected %q, got %q", c.opts, c.expected, output)
+		}
+	}
+
+	m := NewMinifier(WithIncludePaths("/user/tags/0", "/user/tags/2"), WithExcludePaths("user.tags.2"))
+	output, err := m.Minify(input)
+	if err != nil {
+		t.Fatalf("Minify failed: %v", err)
+	}
+	if output != `{"user":{"tags":["x"]}}` {
+		t.Errorf("Expected %q, got %q", `{"user":{"tags":["x"]}}`, output)
+	}
+}
+
+func TestMinifyWithOptionsOmit(t *testing.T) {
+	input := `{"a": null, "b": {}, "c": [], "d": {"x": null}, "e": [null, {}], "f": 0, "g": "", "h": false}`
+
+	cases := []struct {
+		opts     Options
+		expected string
+	}{
+		{Options{OmitNull: true}, `{"b":{},"c":[],"d":{},"e":[null,{}],"f":0,"g":"","h":false}`},
+		{Options{OmitEmpty: true}, `{"a":null,"d":{"x":null},"e":[null,{}],"f":0,"g":"","h":false}`},
+		{Options{OmitNull: true, OmitEmpty: true}, `{"e":[null,{}],"f":0,"g":"","h":false}`},
+		{Options{OmitNull: true, SortKeys: true}, `{"b":{},"c":[],"d":{},"e":[null,{}],"f":0,"g":"","h":false}`},
+		{Options{OmitEmpty: true, ExcludePaths: []string{"d.x", "e"}}, `{"a":null,"f":0,"g":"","h":false}`},
+	}
+	for _, c := range cases {
+		output, err := MinifyWithOptions(input, SPORT, c.opts)
+		if err != nil {
+			t.Fatalf("MinifyWithOptions(%+v) failed: %v", c.opts, err)
+		}
+		if output != c.expected {
+			t.Errorf("%+v: expected %q, got %q", c.opts, c.expected, output)
+		}
+	}
+
+	// The top-level value is always kept
+	m := NewMinifier(WithOmitNull(), WithOmitEmpty())
+	output, err := m.Minify(`{"a": null}`)
+	if err != nil {
+		t.Fatalf("Minify failed: %v", err)
+	}
+	if output != `{}` {
+		t.Errorf("Expected %q, got %q", `{}`, output)
+	}
+}
+
+func TestMinifyWithOptionsSortKeys(t *testing.T) {
+	input := `{"b": 1, "a": {"d": [{"y": 1, "x": 2}], "c": null}, "B": 2, "aa": 3}`
+
+	output, err := MinifyWithOptions(input, SPORT, Options{SortKeys: true})
+	if err != nil {
+		t.Fatalf("MinifyWithOptions failed: %v", err)
+	}
+	expected := `{"B":2,"a":{"c":null,"d":[{"x":2,"y":1}]},"aa":3,"b":1}`
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+
+	// Key order no longer affects the output
+	m := NewMinifier(WithSortKeys())
+	first, _ := m.Minify(`{"x": 1, "y": 2}`)
+	second, _ := m.Minify(`{"y": 2, "x": 1}`)
+	if first != second {
+		t.Errorf("Expected identical output, got %q and %q", first, second)
+	}
+}
+
+func TestMinifyWithOptionsDuplicateKeys(t *testing.T) {
+	input := `{"a": 1, "b": {"x": 1, "x": 2}, "a": 3}`
+
+	output, err := MinifyWithOptions(input, SPORT, Options{})
+	if err != nil {
+		t.Fatalf("MinifyWithOptions failed: %v", err)
+	}
+	if output != `{"a":1,"b":{"x":1,"x":2},"a":3}` {
+		t.Errorf("Expected duplicates to pass through, got %q", output)
+	}
+
+	if _, err := MinifyWithOptions(input, SPORT, Options{DuplicateKeys: Reject}); !errors.Is(err, ErrDuplicateKey) {
+		t.Errorf("Expected ErrDuplicateKey, got %v", err)
+	}
+
+	tests := []struct {
+		policy   DuplicateKeyPolicy
+		expected string
+	}{
+		{KeepFirst, `{"a":1,"b":{"x":1}}`},
+		{KeepLast, `{"a":3,"b":{"x":2}}`},
+	}
+	for _, test := range tests {
+		output, err := NewMinifier(WithDuplicateKeyPolicy(test.policy)).Minify(input)
+		if err != nil {
+			t.Fatalf("Minify failed: %v", err)
+		}
+		if output != test.expected {
+			t.Errorf("Expected %q, got %q", test.expected, output)
+		}
+	}
+
+	// Deduplication composes with sorting
+	output, err = MinifyWithOptions(`{"b": 1, "a": 2, "b": 3}`, SPORT, Options{DuplicateKeys: KeepLast, SortKeys: true})
+	if err != nil {
+		t.Fatalf("MinifyWithOptions failed: %v", err)
+	}
+	if output != `{"a":2,"b":3}` {
+		t.Errorf("Unexpected output %q", output)
+	}
+}
+
+func TestMinifyWithOptionsEscaping(t *testing.T) {
+	input := `{"café": "<b>&</b> 😀 \n", "sep": "a` + "\u2028" + `b"}`
+
+	output, err := MinifyWithOptions(input, SPORT, Options{HTMLSafe: true})
+	if err != nil {
+		t.Fatalf("MinifyWithOptions failed: %v", err)
+	}
+	expected := `{"café":"\u003cb\u003e\u0026\u003c/b\u003e 😀 \n","sep":"a\u2028b"}`
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+
+	output, err = MinifyWithOptions(input, SPORT, Options{ASCIIOnly: true})
+	if err != nil {
+		t.Fatalf("MinifyWithOptions failed: %v", err)
+	}
+	expected = `{"caf\u00e9":"<b>&</b> \ud83d\ude00 \n","sep":"a\u2028b"}`
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+
+	// Escaped output decodes to the same document
+	var original, escaped interface{}
+	_ = json.Unmarshal([]byte(input), &original)
+	if err := json.Unmarshal([]byte(output), &escaped); err != nil {
+		t.Fatalf("Escaped output is not valid JSON: %v", err)
+	}
+	if !reflect.DeepEqual(original, escaped) {
+		t.Errorf("Expected %v, got %v", original, escaped)
+	}
+}
+
+func TestMinifyWithOptionsNumbers(t *testing.T) {
+	input := `[1.50E+2, 0.10, -0, -0.0, 1e-7, 12345678901234567890, 2.5e400]`
+
+	output, err := MinifyWithOptions(input, SPORT, Options{})
+	if err != nil {
+		t.Fatalf("MinifyWithOptions failed: %v", err)
+	}
+	if output != `[1.50E+2,0.10,-0,-0.0,1e-7,12345678901234567890,2.5e400]` {
+		t.Errorf("Expected numbers to pass through verbatim, got %q", output)
+	}
+
+	// Out of range floats cannot be normalized and are kept as written
+	output, err = MinifyWithOptions(input, SPORT, Options{NormalizeNumbers: true})
+	if err != nil {
+		t.Fatalf("MinifyWithOptions failed: %v", err)
+	}
+	expected := `[150,0.1,0,0,1e-7,12345678901234567890,2.5e400]`
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+	if output, err := MinifyWithOptions(`[1e400, -1E400]`, SPORT, Options{NormalizeNumbers: true}); err != nil || output != `[1e400,-1E400]` {
+		t.Errorf("Unexpected result %q (%v)", output, err)
+	}
+
+	opts := Options{RejectUnsafeIntegers: true}
+	if _, err := MinifyWithOptions(`[9007199254740992, -9007199254740992, 1e300]`, SPORT, opts); err != nil {
+		t.Errorf("Expected safe integers to pass, got %v", err)
+	}
+	if _, err := MinifyWithOptions(`{"id": 9007199254740993}`, SPORT, opts); !errors.Is(err, ErrUnsafeInteger) {
+		t.Errorf("Expected ErrUnsafeInteger, got %v", err)
+	}
+}
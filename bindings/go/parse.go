@@ -0,0 +1,22 @@
+package zmin
+
+import (
+	"encoding/json"
+)
+
+// Parse minifies and validates JSON data using the specified processing mode,
+// then decodes it into a new value of type T. T may be any type accepted by
+// json.Unmarshal, including pointers, slices and maps. On failure the zero
+// value of T is returned along with the error.
+func Parse[T any](input []byte, mode ProcessingMode) (T, error) {
+	var value T
+	output, err := MinifyBytes(input, mode)
+	if err != nil {
+		return value, err
+	}
+	if err := json.Unmarshal(output, &value); err != nil {
+		var zero T
+		return zero, err
+	}
+	return value, nil
+}
@@ -0,0 +1,64 @@
+package zmin
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	input := []byte(`{ "name": "John", "age": 30 }`)
+
+	u, err := Parse[user](input, SPORT)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if u.Name != "John" || u.Age != 30 {
+		t.Errorf("Unexpected value: %+v", u)
+	}
+
+	p, err := Parse[*user](input, SPORT)
+	if err != nil {
+		t.Fatalf("Parse pointer failed: %v", err)
+	}
+	if p == nil || p.Name != "John" {
+		t.Errorf("Unexpected pointer value: %+v", p)
+	}
+
+	m, err := Parse[map[string]interface{}](input, ECO)
+	if err != nil {
+		t.Fatalf("Parse map failed: %v", err)
+	}
+	if m["name"] != "John" {
+		t.Errorf("Unexpected map value: %v", m)
+	}
+
+	s, err := Parse[[]int]([]byte(`[1, 2, 3]`), TURBO)
+	if err != nil {
+		t.Fatalf("Parse slice failed: %v", err)
+	}
+	if len(s) != 3 || s[2] != 3 {
+		t.Errorf("Unexpected slice value: %v", s)
+	}
+
+	// Invalid JSON returns the zero value
+	p, err = Parse[*user]([]byte(`{"name": }`), SPORT)
+	if !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON, got %v", err)
+	}
+	if p != nil {
+		t.Errorf("Expected zero value on error, got %+v", p)
+	}
+
+	// Type mismatches also return the zero value
+	u, err = Parse[user]([]byte(`{"name": "John", "age": "thirty"}`), SPORT)
+	if err == nil {
+		t.Error("Expected error for mismatched type")
+	}
+	if u != (user{}) {
+		t.Errorf("Expected zero value on error, got %+v", u)
+	}
+}
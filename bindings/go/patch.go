@@ -0,0 +1,199 @@
+package zmin
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// patchOperation is an operation of an RFC 6902 JSON Patch
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// ApplyPatch applies an RFC 6902 JSON Patch to doc and returns the minified
+// result. Operations are applied in order and the patch is atomic: if any
+// operation fails, including a failed "test", an error identifying it is
+// returned and no result is produced. Members keep their position when
+// replaced, and added members are appended. The changes returned by Diff, marshaled with
+// encoding/json, form a valid patch.
+func ApplyPatch(doc, patch []byte) ([]byte, error) {
+	var ops []patchOperation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, fmt.Errorf("invalid JSON patch: %w", err)
+	}
+	root, err := parseTree(doc)
+	if err != nil {
+		return nil, err
+	}
+	for i, op := range ops {
+		if root, err = applyOperation(root, op); err != nil {
+			return nil, fmt.Errorf("patch operation %d (%s): %w", i, op.Op, err)
+		}
+	}
+	return root.appendTo(make([]byte, 0, len(doc))), nil
+}
+
+// applyOperation applies op to the tree at root and returns the new root
+func applyOperation(root *jsonNode, op patchOperation) (*jsonNode, error) {
+	if op.Path == nil {
+		return nil, fmt.Errorf("missing path")
+	}
+	path, err := parsePointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var value *jsonNode
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, fmt.Errorf("missing value")
+		}
+		if value, err = parseTree(op.Value); err != nil {
+			return nil, err
+		}
+	case "move", "copy":
+		if op.From == nil {
+			return nil, fmt.Errorf("missing from")
+		}
+		from, err := parsePointer(*op.From)
+		if err != nil {
+			return nil, err
+		}
+		if value, err = lookupNode(root, from); err != nil {
+			return nil, err
+		}
+		if op.Op == "copy" {
+			value = value.clone()
+			break
+		}
+		if *op.Path == *op.From {
+			return root, nil
+		}
+		if strings.HasPrefix(*op.Path, *op.From+"/") {
+			return nil, fmt.Errorf("cannot move %s into itself", *op.From)
+		}
+		if root, err = removeNode(root, from); err != nil {
+			return nil, err
+		}
+	case "remove":
+		return removeNode(root, path)
+	default:
+		return nil, fmt.Errorf("unknown operation %q", op.Op)
+	}
+
+	switch op.Op {
+	case "test":
+		target, err := lookupNode(root, path)
+		if err != nil {
+			return nil, err
+		}
+		if !target.equal(value) {
+			return nil, ErrTestFailed
+		}
+		return root, nil
+	case "replace":
+		return replaceNode(root, path, value)
+	}
+	return addNode(root, path, value)
+}
+
+// lookupNode returns the value at path
+func lookupNode(root *jsonNode, path []string) (*jsonNode, error) {
+	n := root
+	for _, part := range path {
+		switch n.kind {
+		case tokObjectStart:
+			member, ok := n.members[part]
+			if !ok {
+				return nil, ErrPointerNotFound
+			}
+			n = member
+		case tokArrayStart:
+			i, ok := parseIndex(part)
+			if !ok || i >= len(n.elems) {
+				return nil, ErrPointerNotFound
+			}
+			n = n.elems[i]
+		default:
+			return nil, ErrPointerNotFound
+		}
+	}
+	return n, nil
+}
+
+// addNode adds value at path, replacing an existing member, inserting into an
+// array or, for the empty path, replacing the whole document. It returns the
+// new root.
+func addNode(root *jsonNode, path []string, value *jsonNode) (*jsonNode, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := lookupNode(root, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := path[len(path)-1]
+	switch parent.kind {
+	case tokObjectStart:
+		parent.set(last, value)
+	case tokArrayStart:
+		i := len(parent.elems)
+		if last != "-" {
+			var ok bool
+			if i, ok = parseIndex(last); !ok || i > len(parent.elems) {
+				return nil, ErrPointerNotFound
+			}
+		}
+		parent.elems = append(parent.elems, nil)
+		copy(parent.elems[i+1:], parent.elems[i:])
+		parent.elems[i] = value
+	default:
+		return nil, ErrPointerNotFound
+	}
+	return root, nil
+}
+
+// replaceNode replaces the value at path, which must exist, in place and
+// returns the new root
+func replaceNode(root *jsonNode, path []string, value *jsonNode) (*jsonNode, error) {
+	if _, err := lookupNode(root, path); err != nil {
+		return nil, err
+	}
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, _ := lookupNode(root, path[:len(path)-1])
+	last := path[len(path)-1]
+	if parent.kind == tokObjectStart {
+		parent.members[last] = value
+	} else {
+		i, _ := parseIndex(last)
+		parent.elems[i] = value
+	}
+	return root, nil
+}
+
+// removeNode removes the value at path, which must exist, and returns the new
+// root. Removing the whole document leaves null.
+func removeNode(root *jsonNode, path []string) (*jsonNode, error) {
+	if len(path) == 0 {
+		return &jsonNode{kind: tokNull, raw: []byte("null")}, nil
+	}
+	if _, err := lookupNode(root, path); err != nil {
+		return nil, err
+	}
+	parent, _ := lookupNode(root, path[:len(path)-1])
+	last := path[len(path)-1]
+	if parent.kind == tokObjectStart {
+		parent.delete(last)
+	} else {
+		i, _ := parseIndex(last)
+		parent.elems = append(parent.elems[:i], parent.elems[i+1:]...)
+	}
+	return root, nil
+}
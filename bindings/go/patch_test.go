@@ -0,0 +1,92 @@
+package zmin
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestApplyPatch(t *testing.T) {
+	doc := `{
+		"name": "app",
+		"replicas": 1,
+		"labels": {"tier": "web"},
+		"ports": [80, 443],
+		"old": true
+	}`
+	patch := `[
+		{"op": "test", "path": "/name", "value": "app"},
+		{"op": "replace", "path": "/replicas", "value": 3},
+		{"op": "add", "path": "/labels/env", "value": "prod"},
+		{"op": "add", "path": "/ports/1", "value": 8080},
+		{"op": "add", "path": "/ports/-", "value": 9090},
+		{"op": "remove", "path": "/old"},
+		{"op": "copy", "from": "/labels", "path": "/selector"},
+		{"op": "move", "from": "/labels/tier", "path": "/tier"},
+		{"op": "add", "path": "/a~1b", "value": {"x": [null]}}
+	]`
+	output, err := ApplyPatch([]byte(doc), []byte(patch))
+	if err != nil {
+		t.Fatalf("ApplyPatch failed: %v", err)
+	}
+	expected := `{"name":"app","replicas":3,"labels":{"env":"prod"},"ports":[80,8080,443,9090],` +
+		`"selector":{"tier":"web","env":"prod"},"tier":"web","a/b":{"x":[null]}}`
+	if string(output) != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+
+	// Replacing the root
+	output, err = ApplyPatch([]byte(`{"a":1}`), []byte(`[{"op":"replace","path":"","value":[1]}]`))
+	if err != nil || string(output) != `[1]` {
+		t.Errorf("Expected %q, got %q, %v", `[1]`, output, err)
+	}
+}
+
+func TestApplyPatchErrors(t *testing.T) {
+	doc := []byte(`{"a": {"b": [1, 2]}}`)
+	cases := []struct {
+		patch string
+		err   error
+	}{
+		{`[{"op":"test","path":"/a/b/0","value":2}]`, ErrTestFailed},
+		{`[{"op":"remove","path":"/missing"}]`, ErrPointerNotFound},
+		{`[{"op":"replace","path":"/a/c","value":1}]`, ErrPointerNotFound},
+		{`[{"op":"add","path":"/a/b/5","value":1}]`, ErrPointerNotFound},
+		{`[{"op":"add","path":"/x/y","value":1}]`, ErrPointerNotFound},
+		{`[{"op":"add","path":"/a","value":{"b":}}]`, nil},
+		{`[{"op":"move","from":"/a","path":"/a/b/0"}]`, nil},
+		{`[{"op":"add","path":"/a"}]`, nil},
+		{`[{"op":"frobnicate","path":"/a"}]`, nil},
+		{`{"op":"add"}`, nil},
+	}
+	for _, c := range cases {
+		_, err := ApplyPatch(doc, []byte(c.patch))
+		if err == nil {
+			t.Errorf("%s: expected an error", c.patch)
+			continue
+		}
+		if c.err != nil && !errors.Is(err, c.err) {
+			t.Errorf("%s: expected %v, got %v", c.patch, c.err, err)
+		}
+	}
+}
+
+func TestApplyPatchDiff(t *testing.T) {
+	a := []byte(`{"a":[1,2,3],"b":{"c":"x","d":null},"e":1}`)
+	b := []byte(`{"a":[1,4],"b":{"c":"y","f":[]},"g":true}`)
+	changes, err := Diff(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	patch, err := json.Marshal(changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := ApplyPatch(a, patch)
+	if err != nil {
+		t.Fatalf("ApplyPatch failed: %v", err)
+	}
+	if equal, err := Equal(output, b); err != nil || !equal {
+		t.Errorf("Expected %s, got %s", b, output)
+	}
+}
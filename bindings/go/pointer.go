@@ -0,0 +1,51 @@
+package zmin
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parsePointer splits an RFC 6901 JSON Pointer into its unescaped reference
+// tokens. The empty pointer refers to the whole document.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("invalid JSON pointer %q: must be empty or start with '/'", pointer)
+	}
+	parts := strings.Split(pointer[1:], "/")
+	for i, part := range parts {
+		if strings.Contains(part, "~") {
+			part = strings.ReplaceAll(part, "~1", "/")
+			part = strings.ReplaceAll(part, "~0", "~")
+			parts[i] = part
+		}
+	}
+	return parts, nil
+}
+
+// matchPath reports whether path matches pattern, where a "*" segment in the
+// pattern matches any single array index or object key
+func matchPath(pattern, path []string) bool {
+	if len(pattern) != len(path) {
+		return false
+	}
+	for i, segment := range pattern {
+		if segment != "*" && segment != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// formatPointer joins reference tokens into an RFC 6901 JSON Pointer
+func formatPointer(path []string) string {
+	var b strings.Builder
+	for _, part := range path {
+		b.WriteByte('/')
+		part = strings.ReplaceAll(part, "~", "~0")
+		b.WriteString(strings.ReplaceAll(part, "/", "~1"))
+	}
+	return b.String()
+}
@@ -0,0 +1,102 @@
+package zmin
+
+import (
+	"math/bits"
+	"sync"
+)
+
+// BufferPool supplies the scratch buffers used for input conversion and
+// native output while minifying. Implementations must be safe for concurrent
+// use.
+type BufferPool interface {
+	// Get returns a buffer with a length of at least size
+	Get(size int) []byte
+	// Put returns a buffer obtained from Get for reuse
+	Put(buf []byte)
+}
+
+// Size classes of the default pool are powers of two from 512 bytes to 64MB.
+// Larger buffers are allocated on demand and left to the garbage collector.
+const (
+	minPoolClass = 9
+	maxPoolClass = 26
+)
+
+// sizeClassPool is a BufferPool keeping a sync.Pool per size class
+type sizeClassPool struct {
+	classes [maxPoolClass - minPoolClass + 1]sync.Pool
+}
+
+// NewBufferPool returns a BufferPool backed by sync.Pools keyed by
+// power-of-two size class
+func NewBufferPool() BufferPool {
+	return &sizeClassPool{}
+}
+
+// defaultBufferPool is shared by the package-level functions and by minifiers
+// without a pool of their own
+var defaultBufferPool = NewBufferPool()
+
+func (p *sizeClassPool) Get(size int) []byte {
+	class := sizeClass(size)
+	if class > maxPoolClass {
+		return make([]byte, size)
+	}
+	if buf, ok := p.classes[class-minPoolClass].Get().(*[]byte); ok {
+		return (*buf)[:size]
+	}
+	return make([]byte, size, 1<<class)
+}
+
+func (p *sizeClassPool) Put(buf []byte) {
+	// Only buffers with exactly a class capacity are pooled, which also
+	// keeps out buffers that were not obtained from Get
+	class := sizeClass(cap(buf))
+	if class > maxPoolClass || cap(buf) != 1<<class {
+		return
+	}
+	buf = buf[:0]
+	p.classes[class-minPoolClass].Put(&buf)
+}
+
+// sizeClass returns the smallest class whose buffers hold size bytes
+func sizeClass(size int) int {
+	if size <= 1<<minPoolClass {
+		return minPoolClass
+	}
+	return bits.Len(uint(size - 1))
+}
+
+// minifyPooled minifies input with into, using a pooled scratch buffer for the
+// output, and passes the output to fn, which must not retain it. A nil pool
+// means the default pool.
+func minifyPooled(input interface{}, pool BufferPool, into minifyFunc, fn func(output []byte)) error {
+	if pool == nil {
+		pool = defaultBufferPool
+	}
+	var src []byte
+	switch v := input.(type) {
+	case []byte:
+		src = v
+	case string:
+		buf := pool.Get(len(v))
+		defer pool.Put(buf)
+		src = buf[:copy(buf, v)]
+	default:
+		jsonStr, err := toJSONString(input)
+		if err != nil {
+			return err
+		}
+		src = []byte(jsonStr)
+	}
+
+	// Minification only removes bytes, so the input size always suffices
+	out := pool.Get(len(src))
+	defer pool.Put(out)
+	n, err := into(out[:len(src)], src)
+	if err != nil {
+		return err
+	}
+	fn(out[:n])
+	return nil
+}
@@ -0,0 +1,79 @@
+package zmin
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSizeClassPool(t *testing.T) {
+	pool := NewBufferPool()
+
+	tests := []struct {
+		size     int
+		capacity int
+	}{
+		{0, 512},
+		{512, 512},
+		{513, 1024},
+		{100000, 131072},
+		{1<<26 + 1, 1<<26 + 1}, // beyond the largest class
+	}
+	for _, test := range tests {
+		buf := pool.Get(test.size)
+		if len(buf) != test.size || cap(buf) != test.capacity {
+			t.Errorf("Get(%d): expected len %d cap %d, got len %d cap %d",
+				test.size, test.size, test.capacity, len(buf), cap(buf))
+		}
+		pool.Put(buf)
+	}
+
+	// Foreign buffers are not pooled
+	pool.Put(make([]byte, 1000))
+	if buf := pool.Get(1000); cap(buf) != 1024 {
+		t.Errorf("Expected a class-sized buffer, got cap %d", cap(buf))
+	}
+}
+
+// countingPool records how buffers are used
+type countingPool struct {
+	mu         sync.Mutex
+	gets, puts int
+}
+
+func (p *countingPool) Get(size int) []byte {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.gets++
+	return make([]byte, size)
+}
+
+func (p *countingPool) Put(buf []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.puts++
+}
+
+func TestWithBufferPool(t *testing.T) {
+	pool := &countingPool{}
+	m := NewMinifier(WithBufferPool(pool))
+
+	output, err := m.Minify(`{ "key": "value" }`)
+	if err != nil {
+		t.Fatalf("Minify failed: %v", err)
+	}
+	if output != `{"key":"value"}` {
+		t.Errorf("Unexpected output %q", output)
+	}
+	// One buffer for the input copy and one for the output
+	if pool.gets != 2 || pool.puts != 2 {
+		t.Errorf("Expected 2 gets and 2 puts, got %d and %d", pool.gets, pool.puts)
+	}
+
+	// Buffers are returned on error too
+	if _, err := m.Minify(`{"key": }`); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+	if pool.gets != pool.puts {
+		t.Errorf("Expected every buffer to be returned, got %d gets and %d puts", pool.gets, pool.puts)
+	}
+}
@@ -0,0 +1,74 @@
+package zmin
+
+import (
+	"io"
+	"os"
+)
+
+// WithProgress makes long-running file operations report their progress to
+// f, for progress bars and liveness probes. Minifier.MinifyFile and
+// MinifyFileContext report the input bytes read out of the file size;
+// MinifyDir and MinifyGlob, given the option through their Minifier options,
+// report the combined size of the files finished out of the size of all files
+// found. Calls are never concurrent, and processed equals total only once the
+// operation is complete.
+func WithProgress(f func(processed, total int64)) Option {
+	return func(m *Minifier) {
+		m.progress = f
+	}
+}
+
+// configOf returns a Minifier configured by opts without a native context,
+// for reading the settings of operations that create their own Minifiers
+func configOf(opts []Option) *Minifier {
+	m := &Minifier{mode: SPORT}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// readFile reads a file like os.ReadFile, reporting progress as the input is
+// read. The final call, with processed equal to total, is left to the caller.
+func (m *Minifier) readFile(path string) ([]byte, int64, error) {
+	if m.progress == nil {
+		data, err := os.ReadFile(path)
+		return data, int64(len(data)), err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total := info.Size()
+	data := make([]byte, 0, total)
+	for {
+		if len(data) == cap(data) {
+			data = append(data, 0)[:len(data)]
+		}
+		end := cap(data)
+		if end-len(data) > largeChunkSize {
+			end = len(data) + largeChunkSize
+		}
+		n, err := f.Read(data[len(data):end])
+		data = data[:len(data)+n]
+		if int64(len(data)) > total {
+			total = int64(len(data))
+		}
+		if n > 0 && int64(len(data)) < total {
+			m.progress(int64(len(data)), total)
+		}
+		if err == io.EOF {
+			return data, int64(len(data)), nil
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+}
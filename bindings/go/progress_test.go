@@ -0,0 +1,73 @@
+package zmin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// progressRecorder records progress reports
+type progressRecorder struct {
+	calls [][2]int64
+}
+
+func (r *progressRecorder) report(processed, total int64) {
+	r.calls = append(r.calls, [2]int64{processed, total})
+}
+
+// check verifies that progress increased steadily and completed at total
+func (r *progressRecorder) check(t *testing.T, total int64, minCalls int) {
+	t.Helper()
+	if len(r.calls) < minCalls {
+		t.Fatalf("Expected at least %d progress reports, got %d", minCalls, len(r.calls))
+	}
+	for i, call := range r.calls {
+		if call[1] != total {
+			t.Errorf("Report %d: expected total %d, got %d", i, total, call[1])
+		}
+		if i > 0 && call[0] < r.calls[i-1][0] {
+			t.Errorf("Report %d: progress went back from %d to %d", i, r.calls[i-1][0], call[0])
+		}
+		if last := i == len(r.calls)-1; (call[0] == total) != last {
+			t.Errorf("Report %d: processed %d of %d, last report: %v", i, call[0], total, last)
+		}
+	}
+}
+
+func TestWithProgressFile(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.json")
+	content := "[" + strings.Repeat(`{ "a" : 1 }, `, 300000) + "0]"
+	if err := os.WriteFile(input, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var r progressRecorder
+	m := NewMinifier(WithProgress(r.report))
+	defer m.Close()
+	if err := m.MinifyFile(input, filepath.Join(dir, "output.json")); err != nil {
+		t.Fatalf("MinifyFile failed: %v", err)
+	}
+	r.check(t, int64(len(content)), 3)
+}
+
+func TestWithProgressDir(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{"a.json": `{ "a" : 1 }`, "b.json": `[ 1 ]`, "sub/c.json": `{}`}
+	writeTree(t, root, files)
+	var total int64
+	for _, content := range files {
+		total += int64(len(content))
+	}
+
+	var r progressRecorder
+	_, err := MinifyDir(context.Background(), root, DirOptions{
+		Options: []Option{WithProgress(r.report)},
+	})
+	if err != nil {
+		t.Fatalf("MinifyDir failed: %v", err)
+	}
+	r.check(t, total, len(files))
+}
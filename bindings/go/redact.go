@@ -0,0 +1,42 @@
+package zmin
+
+import (
+	"path"
+	"regexp"
+)
+
+// redactPlaceholder replaces the values redacted by Redact
+const redactPlaceholder = "[REDACTED]"
+
+// RedactRule selects object members, at any depth, whose values are redacted.
+// A rule matches a key if Key matches it or Pattern does.
+type RedactRule struct {
+	// Key matches whole keys with the syntax of path.Match, so "password"
+	// matches only that key and "*_token" matches "access_token"
+	Key string
+
+	// Pattern matches keys by regular expression; use (?i) to ignore case
+	Pattern *regexp.Regexp
+}
+
+// matches reports whether the rule selects key. Key is known to be a
+// well-formed pattern.
+func (r RedactRule) matches(key string) bool {
+	if r.Key != "" {
+		if ok, _ := path.Match(r.Key, key); ok {
+			return true
+		}
+	}
+	return r.Pattern != nil && r.Pattern.MatchString(key)
+}
+
+// Redact minifies input and replaces the values of the members selected by
+// rules, whole subtrees included, with "[REDACTED]", so that documents can be
+// logged or shipped without their secrets
+func Redact(input []byte, rules []RedactRule) ([]byte, error) {
+	output, err := MinifyWithOptions(input, SPORT, Options{RedactRules: rules, RedactToken: redactPlaceholder})
+	if err != nil {
+		return nil, err
+	}
+	return []byte(output), nil
+}
@@ -0,0 +1,44 @@
+package zmin
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	input := []byte(`{
+		"user": "alice",
+		"password": "hunter2",
+		"session": {"access_token": "abc", "refresh_token": {"value": "def"}, "expires": 60},
+		"headers": [{"Authorization": "Bearer xyz"}, {"Accept": "*/*"}],
+		"Password": "kept"
+	}`)
+	rules := []RedactRule{
+		{Key: "password"},
+		{Key: "*_token"},
+		{Pattern: regexp.MustCompile(`(?i)^authorization$`)},
+	}
+	output, err := Redact(input, rules)
+	if err != nil {
+		t.Fatalf("Redact failed: %v", err)
+	}
+	expected := `{"user":"alice","password":"[REDACTED]","session":{"access_token":"[REDACTED]","refresh_token":"[REDACTED]","expires":60},` +
+		`"headers":[{"Authorization":"[REDACTED]"},{"Accept":"*/*"}],"Password":"kept"}`
+	if string(output) != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+
+	// Rules combine with the other options and their token
+	m := NewMinifier(WithOptions(Options{RedactRules: []RedactRule{{Key: "secret"}}}))
+	got, err := m.Minify(`[{"secret": 1}]`)
+	if err != nil {
+		t.Fatalf("Minify failed: %v", err)
+	}
+	if got != `[{"secret":"***"}]` {
+		t.Errorf("Expected %q, got %q", `[{"secret":"***"}]`, got)
+	}
+
+	if _, err := Redact(input, []RedactRule{{Key: "["}}); err == nil {
+		t.Error("Expected an error for a malformed key pattern")
+	}
+}
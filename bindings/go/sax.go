@@ -0,0 +1,80 @@
+package zmin
+
+import (
+	"encoding/json"
+)
+
+// Handler receives the events of a JSON document from Walk, in document order.
+// Returning an error from any method stops the walk, and Walk returns that
+// error unchanged. Embed BaseHandler to implement only the events you need.
+type Handler interface {
+	ObjectStart() error
+	ObjectEnd() error
+	ArrayStart() error
+	ArrayEnd() error
+	// Key is called with the unescaped key of each object member, before
+	// the events of its value
+	Key(key string) error
+	String(value string) error
+	Number(value json.Number) error
+	Bool(value bool) error
+	Null() error
+}
+
+// BaseHandler implements Handler by ignoring every event
+type BaseHandler struct{}
+
+func (BaseHandler) ObjectStart() error             { return nil }
+func (BaseHandler) ObjectEnd() error               { return nil }
+func (BaseHandler) ArrayStart() error              { return nil }
+func (BaseHandler) ArrayEnd() error                { return nil }
+func (BaseHandler) Key(key string) error           { return nil }
+func (BaseHandler) String(value string) error      { return nil }
+func (BaseHandler) Number(value json.Number) error { return nil }
+func (BaseHandler) Bool(value bool) error          { return nil }
+func (BaseHandler) Null() error                    { return nil }
+
+// Walk scans a JSON document and reports each element to h without building
+// any intermediate representation. The document is validated as it is
+// walked, so events may be delivered before a syntax error later in the
+// input is returned as a *SyntaxError.
+func Walk(input []byte, h Handler) error {
+	s := newScanner(input)
+	for {
+		tok, err := s.next()
+		if err != nil {
+			return err
+		}
+		switch tok.kind {
+		case tokEOF:
+			return nil
+		case tokObjectStart:
+			err = h.ObjectStart()
+		case tokObjectEnd:
+			err = h.ObjectEnd()
+		case tokArrayStart:
+			err = h.ArrayStart()
+		case tokArrayEnd:
+			err = h.ArrayEnd()
+		case tokKey, tokString:
+			var str string
+			if str, err = decodeString(tok.raw); err != nil {
+				return err
+			}
+			if tok.kind == tokKey {
+				err = h.Key(str)
+			} else {
+				err = h.String(str)
+			}
+		case tokNumber:
+			err = h.Number(json.Number(tok.raw))
+		case tokTrue, tokFalse:
+			err = h.Bool(tok.kind == tokTrue)
+		case tokNull:
+			err = h.Null()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package zmin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// recorder logs every event it receives
+type recorder struct {
+	events []string
+}
+
+func (r *recorder) ObjectStart() error             { return r.log("{") }
+func (r *recorder) ObjectEnd() error               { return r.log("}") }
+func (r *recorder) ArrayStart() error              { return r.log("[") }
+func (r *recorder) ArrayEnd() error                { return r.log("]") }
+func (r *recorder) Key(key string) error           { return r.log("key:" + key) }
+func (r *recorder) String(value string) error      { return r.log("string:" + value) }
+func (r *recorder) Number(value json.Number) error { return r.log("number:" + value.String()) }
+func (r *recorder) Bool(value bool) error          { return r.log(fmt.Sprint("bool:", value)) }
+func (r *recorder) Null() error                    { return r.log("null") }
+
+func (r *recorder) log(event string) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+func TestWalk(t *testing.T) {
+	input := `{"name": "Aé", "tags": [1.5, true, null, {}], "ok": false}`
+
+	r := &recorder{}
+	if err := Walk([]byte(input), r); err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+	expected := "{ key:name string:Aé key:tags [ number:1.5 bool:true null { } ] key:ok bool:false }"
+	if got := strings.Join(r.events, " "); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	if err := Walk([]byte(`[1, 2`), &recorder{}); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON, got %v", err)
+	}
+}
+
+// idFinder stops at the first "id" member
+type idFinder struct {
+	BaseHandler
+	inID bool
+	id   json.Number
+}
+
+var errFound = errors.New("found")
+
+func (f *idFinder) Key(key string) error {
+	f.inID = key == "id"
+	return nil
+}
+
+func (f *idFinder) Number(value json.Number) error {
+	if f.inID {
+		f.id = value
+		return errFound
+	}
+	return nil
+}
+
+func TestWalkStop(t *testing.T) {
+	f := &idFinder{}
+	// The walk stops before reaching the syntax error
+	err := Walk([]byte(`{"count": 2, "id": 42, "rest": [`), f)
+	if err != errFound {
+		t.Fatalf("Expected handler error, got %v", err)
+	}
+	if f.id != "42" {
+		t.Errorf("Expected id 42, got %q", f.id)
+	}
+}
@@ -0,0 +1,360 @@
+package zmin
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// tokenKind identifies the kind of a token produced by the scanner
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokObjectStart
+	tokObjectEnd
+	tokArrayStart
+	tokArrayEnd
+	tokKey
+	tokString
+	tokNumber
+	tokTrue
+	tokFalse
+	tokNull
+)
+
+// token is a single lexical element of a JSON document. raw holds the exact
+// source bytes of the token, including the quotes of strings and keys.
+type token struct {
+	kind   tokenKind
+	offset int
+	raw    []byte
+}
+
+// isValue reports whether the token begins a value
+func (t token) isValue() bool {
+	return t.kind != tokEOF && t.kind != tokObjectEnd && t.kind != tokArrayEnd && t.kind != tokKey
+}
+
+// scanState is the position of the scanner within the JSON grammar
+type scanState int
+
+const (
+	stateBegin       scanState = iota // expecting the top-level value
+	stateObjectFirst                  // after '{': expecting a key or '}'
+	stateObjectKey                    // after ',' in an object: expecting a key
+	stateColon                        // after a key: expecting ':' and a value
+	stateArrayFirst                   // after '[': expecting a value or ']'
+	stateArrayValue                   // after ',' in an array: expecting a value
+	stateAfterValue                   // expecting ',' or the end of the container
+	stateEnd                          // top-level value complete
+)
+
+// scanner tokenizes a JSON document held in memory. It enforces the JSON
+// grammar as it goes, so the tokens it returns are well-formed up to the
+// first error.
+type scanner struct {
+	data  []byte
+	pos   int
+	stack []byte // open containers, '{' or '['
+	state scanState
+}
+
+// newScanner returns a scanner positioned at the start of data
+func newScanner(data []byte) *scanner {
+	return &scanner{data: data}
+}
+
+// depth returns the number of currently open containers
+func (s *scanner) depth() int {
+	return len(s.stack)
+}
+
+// next returns the next token. Separators (',' and ':') and insignificant
+// whitespace are consumed without producing tokens.
+func (s *scanner) next() (token, error) {
+	for {
+		s.skipSpace()
+		switch s.state {
+		case stateEnd:
+			if s.pos < len(s.data) {
+				return token{}, s.errorf("unexpected %s after top-level value", quoteChar(s.data[s.pos]))
+			}
+			return token{kind: tokEOF, offset: s.pos}, nil
+
+		case stateAfterValue:
+			if len(s.stack) == 0 {
+				s.state = stateEnd
+				continue
+			}
+			if s.pos >= len(s.data) {
+				return token{}, s.errorf("unexpected end of input")
+			}
+			top := s.stack[len(s.stack)-1]
+			c := s.data[s.pos]
+			switch {
+			case c == ',':
+				s.pos++
+				if top == '{' {
+					s.state = stateObjectKey
+				} else {
+					s.state = stateArrayValue
+				}
+				continue
+			case c == '}' && top == '{':
+				return s.closeContainer(tokObjectEnd), nil
+			case c == ']' && top == '[':
+				return s.closeContainer(tokArrayEnd), nil
+			case top == '{':
+				return token{}, s.errorf("expected ',' or '}' but found %s", quoteChar(c))
+			default:
+				return token{}, s.errorf("expected ',' or ']' but found %s", quoteChar(c))
+			}
+
+		case stateObjectFirst, stateObjectKey:
+			if s.pos >= len(s.data) {
+				return token{}, s.errorf("unexpected end of input")
+			}
+			c := s.data[s.pos]
+			if c == '}' && s.state == stateObjectFirst {
+				return s.closeContainer(tokObjectEnd), nil
+			}
+			if c != '"' {
+				return token{}, s.errorf("expected object key but found %s", quoteChar(c))
+			}
+			start := s.pos
+			if err := s.scanString(); err != nil {
+				return token{}, err
+			}
+			s.state = stateColon
+			return token{kind: tokKey, offset: start, raw: s.data[start:s.pos]}, nil
+
+		case stateColon:
+			if s.pos >= len(s.data) {
+				return token{}, s.errorf("unexpected end of input")
+			}
+			if c := s.data[s.pos]; c != ':' {
+				return token{}, s.errorf("expected ':' after object key but found %s", quoteChar(c))
+			}
+			s.pos++
+			s.skipSpace()
+			return s.value()
+
+		case stateArrayFirst:
+			if s.pos < len(s.data) && s.data[s.pos] == ']' {
+				return s.closeContainer(tokArrayEnd), nil
+			}
+			return s.value()
+
+		default:
+			return s.value()
+		}
+	}
+}
+
+// skipValue scans the next complete value and returns the offset just past it
+func (s *scanner) skipValue() (int, error) {
+	tok, err := s.next()
+	if err != nil {
+		return 0, err
+	}
+	if !tok.isValue() {
+		return 0, s.errorf("expected value")
+	}
+	return s.finishValue(tok)
+}
+
+// finishValue consumes the rest of the value that tok begins and returns the
+// offset just past it
+func (s *scanner) finishValue(tok token) (int, error) {
+	if tok.kind == tokObjectStart || tok.kind == tokArrayStart {
+		base := s.depth() - 1
+		for s.depth() > base {
+			if _, err := s.next(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return s.pos, nil
+}
+
+// value scans the value starting at the current position
+func (s *scanner) value() (token, error) {
+	if s.pos >= len(s.data) {
+		return token{}, s.errorf("unexpected end of input")
+	}
+	start := s.pos
+	c := s.data[s.pos]
+	switch {
+	case c == '{':
+		s.pos++
+		s.stack = append(s.stack, '{')
+		s.state = stateObjectFirst
+		return token{kind: tokObjectStart, offset: start, raw: s.data[start:s.pos]}, nil
+	case c == '[':
+		s.pos++
+		s.stack = append(s.stack, '[')
+		s.state = stateArrayFirst
+		return token{kind: tokArrayStart, offset: start, raw: s.data[start:s.pos]}, nil
+	case c == '"':
+		if err := s.scanString(); err != nil {
+			return token{}, err
+		}
+		s.state = stateAfterValue
+		return token{kind: tokString, offset: start, raw: s.data[start:s.pos]}, nil
+	case c == '-' || (c >= '0' && c <= '9'):
+		if err := s.scanNumber(); err != nil {
+			return token{}, err
+		}
+		s.state = stateAfterValue
+		return token{kind: tokNumber, offset: start, raw: s.data[start:s.pos]}, nil
+	case c == 't':
+		return s.literal("true", tokTrue)
+	case c == 'f':
+		return s.literal("false", tokFalse)
+	case c == 'n':
+		return s.literal("null", tokNull)
+	default:
+		return token{}, s.errorf("unexpected %s looking for beginning of value", quoteChar(c))
+	}
+}
+
+// closeContainer consumes the closing bracket of the innermost container
+func (s *scanner) closeContainer(kind tokenKind) token {
+	start := s.pos
+	s.pos++
+	s.stack = s.stack[:len(s.stack)-1]
+	s.state = stateAfterValue
+	return token{kind: kind, offset: start, raw: s.data[start:s.pos]}
+}
+
+// literal scans one of the keywords true, false or null
+func (s *scanner) literal(word string, kind tokenKind) (token, error) {
+	start := s.pos
+	if len(s.data)-s.pos < len(word) || string(s.data[s.pos:s.pos+len(word)]) != word {
+		return token{}, s.errorf("invalid literal, expected %q", word)
+	}
+	s.pos += len(word)
+	s.state = stateAfterValue
+	return token{kind: kind, offset: start, raw: s.data[start:s.pos]}, nil
+}
+
+// scanString advances past a string starting at the current position
+func (s *scanner) scanString() error {
+	s.pos++ // opening quote
+	for s.pos < len(s.data) {
+		c := s.data[s.pos]
+		switch {
+		case c == '"':
+			s.pos++
+			return nil
+		case c == '\\':
+			s.pos++
+			if s.pos >= len(s.data) {
+				return s.errorf("unexpected end of input in string escape")
+			}
+			switch s.data[s.pos] {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+				s.pos++
+			case 'u':
+				s.pos++
+				for i := 0; i < 4; i++ {
+					if s.pos >= len(s.data) {
+						return s.errorf("unexpected end of input in string escape")
+					}
+					if !isHex(s.data[s.pos]) {
+						return s.errorf("invalid character %s in \\u escape", quoteChar(s.data[s.pos]))
+					}
+					s.pos++
+				}
+			default:
+				return s.errorf("invalid escape character %s in string", quoteChar(s.data[s.pos]))
+			}
+		case c < 0x20:
+			return s.errorf("invalid control character %s in string", quoteChar(c))
+		default:
+			s.pos++
+		}
+	}
+	return s.errorf("unexpected end of input in string")
+}
+
+// scanNumber advances past a number starting at the current position
+func (s *scanner) scanNumber() error {
+	if s.data[s.pos] == '-' {
+		s.pos++
+	}
+	switch {
+	case s.pos < len(s.data) && s.data[s.pos] == '0':
+		s.pos++
+	case s.pos < len(s.data) && isDigit(s.data[s.pos]):
+		s.skipDigits()
+	default:
+		return s.errorf("invalid number, expected digit")
+	}
+	if s.pos < len(s.data) && s.data[s.pos] == '.' {
+		s.pos++
+		if s.pos >= len(s.data) || !isDigit(s.data[s.pos]) {
+			return s.errorf("invalid number, expected digit after decimal point")
+		}
+		s.skipDigits()
+	}
+	if s.pos < len(s.data) && (s.data[s.pos] == 'e' || s.data[s.pos] == 'E') {
+		s.pos++
+		if s.pos < len(s.data) && (s.data[s.pos] == '+' || s.data[s.pos] == '-') {
+			s.pos++
+		}
+		if s.pos >= len(s.data) || !isDigit(s.data[s.pos]) {
+			return s.errorf("invalid number, expected digit in exponent")
+		}
+		s.skipDigits()
+	}
+	return nil
+}
+
+func (s *scanner) skipDigits() {
+	for s.pos < len(s.data) && isDigit(s.data[s.pos]) {
+		s.pos++
+	}
+}
+
+func (s *scanner) skipSpace() {
+	for s.pos < len(s.data) && isSpace(s.data[s.pos]) {
+		s.pos++
+	}
+}
+
+// errorf reports a syntax error at the current position
+func (s *scanner) errorf(format string, args ...interface{}) error {
+	return newSyntaxError(s.data, s.pos, fmt.Sprintf(format, args...))
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHex(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// quoteChar formats a byte for use in an error message
+func quoteChar(c byte) string {
+	return strconv.QuoteRune(rune(c))
+}
+
+// decodeString returns the Go string for a raw JSON string or key token
+func decodeString(raw []byte) (string, error) {
+	inner := raw[1 : len(raw)-1]
+	for _, c := range inner {
+		if c == '\\' {
+			var s string
+			err := json.Unmarshal(raw, &s)
+			return s, err
+		}
+	}
+	return string(inner), nil
+}
@@ -0,0 +1,82 @@
+package zmin
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EnvISA is the environment variable read by Init as InitOptions.ForceISA,
+// so that operators can change the instruction set without a rebuild
+const EnvISA = "ZMIN_ISA"
+
+// InitOptions configures InitWithOptions
+type InitOptions struct {
+	// DisableSIMD restricts the native library to scalar code. It takes
+	// precedence over ForceISA.
+	DisableSIMD bool
+
+	// ForceISA caps the SIMD instruction sets used at the one named, as
+	// listed in Capabilities.SIMD, such as "SSE4.1", "AVX2" or "NEON", or
+	// "scalar" for none. The CPU must support it. Empty uses the best
+	// available.
+	ForceISA string
+}
+
+// InitWithOptions is like Init, but first restricts the instruction sets the
+// native library uses, to work around crashes on a particular CPU family or
+// under emulation such as Rosetta or QEMU. The options also apply when the
+// library has already been initialized, but they do not replace the
+// instruction sets the library was compiled for; Capabilities.SIMD reports
+// those in use. It fails with ErrUnsupportedCPU if the CPU lacks ForceISA.
+func InitWithOptions(opts InitOptions) error {
+	applied := false
+	initOnce.Do(func() {
+		applied = true
+		initErr = initLibrary(opts)
+	})
+	if applied || initErr != nil {
+		return initErr
+	}
+	return setISA(opts)
+}
+
+// initOptionsFromEnv returns the options Init applies
+func initOptionsFromEnv() InitOptions {
+	return InitOptions{ForceISA: os.Getenv(EnvISA)}
+}
+
+// setISA applies the instruction set options to the native library
+func setISA(opts InitOptions) error {
+	name := opts.ForceISA
+	if opts.DisableSIMD {
+		name = "scalar"
+	}
+	if name == "" {
+		return nil
+	}
+	if !strings.EqualFold(name, "scalar") && !knownISA(name) {
+		return fmt.Errorf("unknown instruction set %q", name)
+	}
+
+	switch code := nativeSetSIMDLevel(name); code {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("%w: version %s cannot select instruction sets", ErrIncompatibleLibrary, Version())
+	case -14:
+		return fmt.Errorf("%w: no %s support", ErrUnsupportedCPU, name)
+	default:
+		return getError(code)
+	}
+}
+
+// knownISA reports whether name is one of simdNames, ignoring case
+func knownISA(name string) bool {
+	for _, known := range simdNames {
+		if strings.EqualFold(name, known) {
+			return true
+		}
+	}
+	return false
+}
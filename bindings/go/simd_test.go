@@ -0,0 +1,40 @@
+package zmin
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestInitWithOptions(t *testing.T) {
+	if err := InitWithOptions(InitOptions{ForceISA: "MMX"}); err == nil {
+		t.Error("Expected an unknown instruction set to fail")
+	}
+	if err := InitWithOptions(InitOptions{ForceISA: "AVX-512"}); err != nil && !errors.Is(err, ErrUnsupportedCPU) {
+		t.Errorf("Expected nil or ErrUnsupportedCPU, got %v", err)
+	}
+
+	caps := LibraryCapabilities()
+	if len(caps.SIMD) == 0 {
+		t.Skip("library reports no SIMD features")
+	}
+	best := caps.SIMD[len(caps.SIMD)-1]
+	defer InitWithOptions(InitOptions{ForceISA: best})
+
+	if err := InitWithOptions(InitOptions{ForceISA: caps.SIMD[0]}); err != nil {
+		t.Fatalf("InitWithOptions failed: %v", err)
+	}
+	if simd := LibraryCapabilities().SIMD; !reflect.DeepEqual(simd, caps.SIMD[:1]) {
+		t.Errorf("Expected %q, got %q", caps.SIMD[:1], simd)
+	}
+
+	if err := InitWithOptions(InitOptions{DisableSIMD: true, ForceISA: best}); err != nil {
+		t.Fatalf("InitWithOptions failed: %v", err)
+	}
+	if simd := LibraryCapabilities().SIMD; len(simd) != 0 {
+		t.Errorf("Expected no SIMD features, got %q", simd)
+	}
+	if output, err := Minify(`{ "a": [1, 2] }`); err != nil || output != `{"a":[1,2]}` {
+		t.Errorf("Unexpected result %q (%v)", output, err)
+	}
+}
@@ -0,0 +1,42 @@
+package zmin
+
+// ScanJSONValues is a split function for a bufio.Scanner that returns each
+// top-level JSON value of a stream, such as NDJSON or concatenated documents,
+// as a token. Whitespace between values is dropped but tokens are otherwise
+// returned as they appear in the input; pass them to MinifyBytes to minify
+// them. Invalid JSON stops the scanner with a *SyntaxError whose offsets are
+// relative to the start of the offending value.
+//
+// The scanner's buffer must be large enough for the largest value; see
+// bufio.Scanner.Buffer.
+func ScanJSONValues(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	start := 0
+	for start < len(data) && isSpace(data[start]) {
+		start++
+	}
+	if start == len(data) {
+		if atEOF {
+			return len(data), nil, nil
+		}
+		return start, nil, nil
+	}
+
+	var s incrementalScanner
+	s.resetMulti()
+	for i := start; i < len(data); i++ {
+		if s.feed(data[i]) == scanError {
+			return 0, nil, s.err
+		}
+		if s.values > 0 {
+			// The value ended at the byte before i
+			return i, data[start:i], nil
+		}
+	}
+	if !atEOF {
+		return start, nil, nil
+	}
+	if err := s.eof(); err != nil {
+		return 0, nil, err
+	}
+	return len(data), data[start:], nil
+}
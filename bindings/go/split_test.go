@@ -0,0 +1,43 @@
+package zmin
+
+import (
+	"bufio"
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestScanJSONValues(t *testing.T) {
+	input := " {\"a\": [1, \"}\"]}\n\n\"text\" 42 -1.5e3{}[]true null "
+
+	// One byte at a time exercises requests for more data
+	scanner := bufio.NewScanner(iotest.OneByteReader(strings.NewReader(input)))
+	scanner.Split(ScanJSONValues)
+
+	var values []string
+	for scanner.Scan() {
+		values = append(values, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+
+	expected := []string{`{"a": [1, "}"]}`, `"text"`, `42`, `-1.5e3`, `{}`, `[]`, `true`, `null`}
+	if strings.Join(values, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected %q, got %q", expected, values)
+	}
+}
+
+func TestScanJSONValuesErrors(t *testing.T) {
+	inputs := []string{`{"a": 1} {"b" 2}`, `[1, 2`, `"open`}
+	for _, input := range inputs {
+		scanner := bufio.NewScanner(strings.NewReader(input))
+		scanner.Split(ScanJSONValues)
+		for scanner.Scan() {
+		}
+		if err := scanner.Err(); !errors.Is(err, ErrInvalidJSON) {
+			t.Errorf("Expected ErrInvalidJSON for %q, got %v", input, err)
+		}
+	}
+}
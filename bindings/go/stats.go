@@ -0,0 +1,81 @@
+package zmin
+
+import "time"
+
+// Stats summarizes a minification run
+type Stats struct {
+	InputBytes  int64          // bytes read
+	OutputBytes int64          // bytes written
+	Ratio       float64        // OutputBytes / InputBytes, 1 for empty input
+	Duration    time.Duration  // wall-clock time taken
+	Mode        ProcessingMode // mode used, with AUTO resolved where possible
+	Unchanged   bool           // the input was already minified
+	Lines       int            // lines read, including blank and invalid ones
+	Values      int            // values written
+	Errors      []*LineError
+}
+
+// Saved returns the number of bytes minification removed
+func (s Stats) Saved() int64 {
+	return s.InputBytes - s.OutputBytes
+}
+
+// finish records the mode, duration and ratio of a run started at start
+func (s *Stats) finish(mode ProcessingMode, start time.Time) {
+	s.Mode = mode
+	s.Duration = time.Since(start)
+	s.Ratio = ratio(s.InputBytes, s.OutputBytes)
+}
+
+// ratio returns out / in, or 1 if in is zero
+func ratio(in, out int64) float64 {
+	if in == 0 {
+		return 1
+	}
+	return float64(out) / float64(in)
+}
+
+// MinifyWithStats minifies JSON like MinifyWithMode and reports the sizes,
+// the ratio between them, the time taken and the mode used, with AUTO
+// resolved to the mode it picked for input
+func MinifyWithStats(input interface{}, mode ProcessingMode) (string, Stats, error) {
+	var stats Stats
+	start := time.Now()
+	jsonStr, err := toJSONString(input)
+	if err != nil {
+		return "", stats, err
+	}
+	output, err := MinifyWithMode(jsonStr, mode)
+	if err != nil {
+		return "", stats, err
+	}
+	stats.InputBytes = int64(len(jsonStr))
+	stats.OutputBytes = int64(len(output))
+	stats.Values = 1
+	stats.Unchanged = len(output) == len(jsonStr)
+	stats.finish(resolveMode(mode, len(jsonStr)), start)
+	return output, stats, nil
+}
+
+// MinifyWithStats minifies JSON like Minify and reports the sizes, the ratio
+// between them, the time taken and the mode used: the configured one, with
+// AUTO resolved, or the mode WithFallbackModes fell back to.
+func (m *Minifier) MinifyWithStats(input interface{}) (string, Stats, error) {
+	var stats Stats
+	start := time.Now()
+	jsonStr, err := m.input(input)
+	if err != nil {
+		return "", stats, err
+	}
+	stats.Mode = resolveMode(m.mode, len(jsonStr))
+	output, err := m.minifyStats(jsonStr, &stats)
+	if err != nil {
+		return "", stats, err
+	}
+	stats.InputBytes = int64(len(jsonStr))
+	stats.OutputBytes = int64(len(output))
+	stats.Values = 1
+	stats.Unchanged = output == jsonStr
+	stats.finish(stats.Mode, start)
+	return output, stats, nil
+}
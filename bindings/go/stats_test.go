@@ -0,0 +1,72 @@
+package zmin
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestMinifyWithStats(t *testing.T) {
+	input := `{ "a" : [ 1, 2, 3 ] }`
+	output, stats, err := MinifyWithStats(input, AUTO)
+	if err != nil {
+		t.Fatalf("MinifyWithStats failed: %v", err)
+	}
+	if output != `{"a":[1,2,3]}` {
+		t.Errorf("Expected %q, got %q", `{"a":[1,2,3]}`, output)
+	}
+	if stats.InputBytes != int64(len(input)) || stats.OutputBytes != int64(len(output)) {
+		t.Errorf("Expected %d -> %d bytes, got %d -> %d", len(input), len(output), stats.InputBytes, stats.OutputBytes)
+	}
+	if stats.Saved() != int64(len(input)-len(output)) {
+		t.Errorf("Expected %d bytes saved, got %d", len(input)-len(output), stats.Saved())
+	}
+	if expected := float64(len(output)) / float64(len(input)); stats.Ratio != expected {
+		t.Errorf("Expected ratio %v, got %v", expected, stats.Ratio)
+	}
+	if stats.Mode != SPORT {
+		t.Errorf("Expected AUTO to resolve to SPORT for a small input, got %d", stats.Mode)
+	}
+	if stats.Duration < 0 {
+		t.Errorf("Expected a non-negative duration, got %v", stats.Duration)
+	}
+
+	if _, _, err := MinifyWithStats(`{"a": }`, SPORT); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+}
+
+func TestStatsAggregate(t *testing.T) {
+	var out strings.Builder
+	stats, err := MinifyNDJSON(strings.NewReader("{ \"a\" : 1 }\n[ 1 ]\n"), &out, ECO)
+	if err != nil {
+		t.Fatalf("MinifyNDJSON failed: %v", err)
+	}
+	if stats.Mode != ECO || stats.Ratio != ratio(stats.InputBytes, stats.OutputBytes) {
+		t.Errorf("Expected ECO and a ratio of %d/%d, got %d and %v", stats.OutputBytes, stats.InputBytes, stats.Mode, stats.Ratio)
+	}
+
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"a.json":     `{ "a" : 1 }`,
+		"sub/b.json": `[ 1, 2 ]`,
+	})
+	dirStats, err := MinifyDir(context.Background(), root, DirOptions{Options: []Option{WithMode(TURBO)}})
+	if err != nil {
+		t.Fatalf("MinifyDir failed: %v", err)
+	}
+	if dirStats.InputBytes != 19 || dirStats.OutputBytes != 12 || dirStats.Saved() != 7 {
+		t.Errorf("Expected 19 -> 12 bytes, got %d -> %d", dirStats.InputBytes, dirStats.OutputBytes)
+	}
+	if dirStats.Ratio != 12.0/19.0 || dirStats.Mode != TURBO {
+		t.Errorf("Expected ratio %v and TURBO, got %v and %d", 12.0/19.0, dirStats.Ratio, dirStats.Mode)
+	}
+
+	empty, err := MinifyDir(context.Background(), t.TempDir(), DirOptions{})
+	if err != nil {
+		t.Fatalf("MinifyDir failed: %v", err)
+	}
+	if empty.Ratio != 1 {
+		t.Errorf("Expected ratio 1 for no files, got %v", empty.Ratio)
+	}
+}
@@ -0,0 +1,237 @@
+package zmin
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// errClosed is returned when writing to a closed stream
+var errClosed = errors.New("write to closed stream")
+
+// StreamMinifier minifies a JSON document written to it in chunks of any size
+// and forwards the result to an underlying io.Writer. Only a small, fixed
+// amount of output is buffered, so documents of any size can be processed.
+//
+// The native library operates on complete documents, so streaming is
+// implemented in Go: the document is validated incrementally and
+// insignificant whitespace is dropped as it passes through.
+type StreamMinifier struct {
+	w      *bufio.Writer
+	scan   incrementalScanner
+	err    error
+	closed bool
+
+	// Output between top-level values when several are accepted
+	separator []byte
+	last      byte // last byte written
+}
+
+// NewStreamMinifier returns a StreamMinifier writing minified JSON to w
+func NewStreamMinifier(w io.Writer) *StreamMinifier {
+	m := &StreamMinifier{w: bufio.NewWriter(w)}
+	m.scan.reset()
+	return m
+}
+
+// Write minifies the next chunk of the document. It returns an error wrapping
+// ErrInvalidJSON as soon as the input stops being valid JSON; the number of
+// bytes returned is then the offset of the offending byte within p.
+func (m *StreamMinifier) Write(p []byte) (int, error) {
+	if m.closed {
+		return 0, errClosed
+	}
+	if m.err != nil {
+		return 0, m.err
+	}
+
+	// Forward runs of significant bytes, cutting them at whitespace
+	start := 0
+	for i, c := range p {
+		switch m.scan.feed(c) {
+		case scanSkip:
+			if err := m.forward(p[start:i]); err != nil {
+				return i, err
+			}
+			start = i + 1
+		case scanNewValue:
+			if err := m.forward(p[start:i]); err != nil {
+				return i, err
+			}
+			if err := m.separate(c); err != nil {
+				return i, err
+			}
+			start = i
+		case scanError:
+			m.err = m.scan.err
+			return i, m.err
+		}
+	}
+	if err := m.forward(p[start:]); err != nil {
+		return start, err
+	}
+	return len(p), nil
+}
+
+// forward writes a run of significant bytes
+func (m *StreamMinifier) forward(run []byte) error {
+	if len(run) == 0 {
+		return nil
+	}
+	if _, err := m.w.Write(run); err != nil {
+		m.err = err
+		return err
+	}
+	m.last = run[len(run)-1]
+	return nil
+}
+
+// separate writes the separator before a further top-level value starting
+// with c. Without a separator, a space still keeps adjacent numbers and
+// literals from running together.
+func (m *StreamMinifier) separate(c byte) error {
+	sep := m.separator
+	if len(sep) == 0 && isWordByte(m.last) && isWordByte(c) {
+		sep = []byte{' '}
+	}
+	if _, err := m.w.Write(sep); err != nil {
+		m.err = err
+		return err
+	}
+	return nil
+}
+
+// isWordByte reports whether c can be part of a number or literal
+func isWordByte(c byte) bool {
+	return isDigit(c) || c == '-' || c == '.' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// Flush writes any buffered output to the underlying writer
+func (m *StreamMinifier) Flush() error {
+	if m.err != nil {
+		return m.err
+	}
+	if err := m.w.Flush(); err != nil {
+		m.err = err
+	}
+	return m.err
+}
+
+// Close checks that a complete document was written and flushes the
+// remaining output. It does not close the underlying writer.
+func (m *StreamMinifier) Close() error {
+	if m.closed {
+		return m.err
+	}
+	m.closed = true
+	if m.err != nil {
+		return m.err
+	}
+	if err := m.scan.eof(); err != nil {
+		m.err = err
+		return err
+	}
+	if m.scan.multi && m.scan.values > 0 && string(m.separator) == "\n" {
+		if err := m.w.WriteByte('\n'); err != nil {
+			m.err = err
+			return err
+		}
+	}
+	return m.Flush()
+}
+
+// NewMinifyingWriter returns a writer that minifies the JSON written to it and
+// forwards the result to w. It accepts a sequence of top-level values, such as
+// the output of a json.Encoder, and writes each one minified and followed by a
+// newline. Close flushes the remaining output and reports an incomplete
+// value; it does not close w.
+//
+// Like StreamMinifier, the writer works incrementally in Go, so mode does not
+// currently change its output; it is accepted for symmetry with the other
+// minify functions.
+func NewMinifyingWriter(w io.Writer, mode ProcessingMode) io.WriteCloser {
+	m := &StreamMinifier{w: bufio.NewWriter(w), separator: []byte{'\n'}}
+	m.scan.resetMulti()
+	return m
+}
+
+// MinifyDocuments minifies a stream of concatenated JSON documents, separated
+// by optional whitespace as accepted by json.Decoder, from r to w. Documents
+// are written with separator between them: "\n" produces JSON Lines, which
+// also end with a newline, and "" writes them back to back, inserting a space
+// only where two numbers or literals would otherwise merge. It returns the
+// number of documents written.
+//
+// Like StreamMinifier, this works incrementally in Go, so mode does not
+// currently change the output.
+func MinifyDocuments(r io.Reader, w io.Writer, mode ProcessingMode, separator string) (int, error) {
+	m := &StreamMinifier{w: bufio.NewWriter(w), separator: []byte(separator)}
+	m.scan.resetMulti()
+	if _, err := io.Copy(m, r); err != nil {
+		return m.scan.values, err
+	}
+	if err := m.Close(); err != nil {
+		return m.scan.values, err
+	}
+	return m.scan.values, nil
+}
+
+// minifyingReader minifies the JSON read from an underlying reader
+type minifyingReader struct {
+	r    io.Reader
+	scan incrementalScanner
+	err  error
+}
+
+// NewMinifyingReader returns a reader that yields the minified form of the
+// JSON document read from r. Minification happens on the fly as the consumer
+// reads, so it can wrap request bodies and uploads without buffering them.
+// A syntax error is returned from Read as a *SyntaxError.
+//
+// Like StreamMinifier, the reader works incrementally in Go, so mode does not
+// currently change its output; it is accepted for symmetry with the other
+// minify functions.
+func NewMinifyingReader(r io.Reader, mode ProcessingMode) io.Reader {
+	m := &minifyingReader{r: r}
+	m.scan.reset()
+	return m
+}
+
+func (m *minifyingReader) Read(p []byte) (int, error) {
+	for {
+		if m.err != nil {
+			return 0, m.err
+		}
+		if len(p) == 0 {
+			return 0, nil
+		}
+
+		// Read into p and compact the significant bytes in place
+		n, err := m.r.Read(p)
+		out := 0
+		for i := 0; i < n; i++ {
+			switch m.scan.feed(p[i]) {
+			case scanEmit:
+				p[out] = p[i]
+				out++
+			case scanError:
+				m.err = m.scan.err
+				return out, m.err
+			}
+		}
+
+		if err == io.EOF {
+			if m.err = m.scan.eof(); m.err == nil {
+				m.err = io.EOF
+			}
+			return out, m.err
+		}
+		if err != nil {
+			m.err = err
+			return out, err
+		}
+		if out > 0 {
+			return out, nil
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package zmin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestStreamMinifier(t *testing.T) {
+	input := `{
+		"name": "John Doe",
+		"escaped": "a \"quoted\" \\ value é",
+		"numbers": [0, -1.5, 2e10, 3E-2, 10],
+		"flags": [true, false, null],
+		"empty": [{}, []]
+	}`
+	expected := `{"name":"John Doe","escaped":"a \"quoted\" \\ value é","numbers":[0,-1.5,2e10,3E-2,10],"flags":[true,false,null],"empty":[{},[]]}`
+
+	// Feed the document in chunks of every size
+	for size := 1; size <= len(input); size++ {
+		var buf bytes.Buffer
+		m := NewStreamMinifier(&buf)
+		for i := 0; i < len(input); i += size {
+			end := i + size
+			if end > len(input) {
+				end = len(input)
+			}
+			if _, err := m.Write([]byte(input[i:end])); err != nil {
+				t.Fatalf("Write failed with chunk size %d: %v", size, err)
+			}
+		}
+		if err := m.Close(); err != nil {
+			t.Fatalf("Close failed with chunk size %d: %v", size, err)
+		}
+		if buf.String() != expected {
+			t.Fatalf("Chunk size %d: expected %q, got %q", size, expected, buf.String())
+		}
+	}
+
+	// A top-level number is only complete at the end of the stream
+	var buf bytes.Buffer
+	m := NewStreamMinifier(&buf)
+	io.WriteString(m, " 42 ")
+	if err := m.Close(); err != nil || buf.String() != "42" {
+		t.Errorf("Expected %q, got %q (%v)", "42", buf.String(), err)
+	}
+}
+
+func TestStreamMinifierErrors(t *testing.T) {
+	invalid := []string{
+		`{"key": "value",}`,
+		`{"key" "value"}`,
+		`[1, 2`,
+		`"unterminated`,
+		`01`,
+		`tru`,
+		`{} {}`,
+		``,
+	}
+	for _, input := range invalid {
+		m := NewStreamMinifier(io.Discard)
+		_, err := io.WriteString(m, input)
+		if err == nil {
+			err = m.Close()
+		}
+		if !errors.Is(err, ErrInvalidJSON) {
+			t.Errorf("Expected ErrInvalidJSON for %q, got %v", input, err)
+		}
+	}
+
+	m := NewStreamMinifier(io.Discard)
+	m.Close()
+	if _, err := m.Write([]byte("{}")); err == nil {
+		t.Error("Expected error writing to closed StreamMinifier")
+	}
+}
+
+func TestMinifyingReader(t *testing.T) {
+	input := "{\n  \"name\": \"John Doe\",\n  \"tags\": [ \"a b\", 1.5e3 ]\n}\n"
+
+	output, err := io.ReadAll(NewMinifyingReader(iotest.HalfReader(strings.NewReader(input)), SPORT))
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	if string(output) != `{"name":"John Doe","tags":["a b",1.5e3]}` {
+		t.Errorf("Unexpected output %q", output)
+	}
+
+	if err := iotest.TestReader(NewMinifyingReader(strings.NewReader(`[ 1, 2 ]`), SPORT), []byte(`[1,2]`)); err != nil {
+		t.Error(err)
+	}
+
+	_, err = io.ReadAll(NewMinifyingReader(strings.NewReader(`{"a": [1, 2}`), SPORT))
+	if !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON, got %v", err)
+	}
+	_, err = io.ReadAll(NewMinifyingReader(strings.NewReader(`{"a": 1`), SPORT))
+	if !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON for truncated input, got %v", err)
+	}
+}
+
+func TestMinifyingWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewMinifyingWriter(&buf, SPORT)
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	for _, v := range []interface{}{map[string]int{"a": 1}, []string{"x", "y"}, 42, 7} {
+		if err := enc.Encode(v); err != nil {
+			t.Fatalf("Encode failed: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	expected := "{\"a\":1}\n[\"x\",\"y\"]\n42\n7\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+
+	// An empty stream is valid, an incomplete value is not
+	if err := NewMinifyingWriter(io.Discard, SPORT).Close(); err != nil {
+		t.Errorf("Expected empty stream to be valid, got %v", err)
+	}
+	w = NewMinifyingWriter(io.Discard, SPORT)
+	io.WriteString(w, `{"a": `)
+	if err := w.Close(); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON, got %v", err)
+	}
+}
+
+func TestMinifyDocuments(t *testing.T) {
+	input := `{"a": 1} [1, 2]
+	"s" 1 -2 true null{"b":
+	false}`
+
+	tests := []struct {
+		separator string
+		expected  string
+	}{
+		{"\n", "{\"a\":1}\n[1,2]\n\"s\"\n1\n-2\ntrue\nnull\n{\"b\":false}\n"},
+		{"", `{"a":1}[1,2]"s"1 -2 true null{"b":false}`},
+		{",", `{"a":1},[1,2],"s",1,-2,true,null,{"b":false}`},
+	}
+	for _, test := range tests {
+		var out bytes.Buffer
+		n, err := MinifyDocuments(strings.NewReader(input), &out, SPORT, test.separator)
+		if err != nil {
+			t.Fatalf("MinifyDocuments failed: %v", err)
+		}
+		if n != 8 {
+			t.Errorf("Expected 8 documents, got %d", n)
+		}
+		if out.String() != test.expected {
+			t.Errorf("Expected %q, got %q", test.expected, out.String())
+		}
+	}
+
+	var out bytes.Buffer
+	n, err := MinifyDocuments(strings.NewReader(`{} {"a": `), &out, SPORT, "\n")
+	if !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON, got %v", err)
+	}
+	if n != 1 {
+		t.Errorf("Expected 1 complete document, got %d", n)
+	}
+}
@@ -0,0 +1,280 @@
+package zmin
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// subprocessMaxInput is the largest document a request to zmin --serve can
+// hold
+const subprocessMaxInput int64 = math.MaxUint32
+
+// defaultSubprocessTimeout bounds a request to a worker unless SetTimeout
+// changes it
+const defaultSubprocessTimeout = time.Minute
+
+// subprocessStopTimeout is how long a stopped worker has to exit, and then
+// to close its output, before it is killed
+var subprocessStopTimeout = 5 * time.Second
+
+// SubprocessBackend is a Backend that runs the zmin command-line tool as a
+// pool of worker processes, started with --serve and fed documents over
+// pipes. A crash in the native code then ends a worker rather than the Go
+// program, which matters for untrusted input: the call fails and the worker
+// is replaced. The program itself needs no native library.
+type SubprocessBackend struct {
+	path string
+	args []string
+
+	mu      sync.Mutex
+	idle    []*zminProcess
+	closed  bool
+	timeout time.Duration
+
+	versionOnce sync.Once
+	version     string
+}
+
+// zminProcess is a worker process running zmin --serve
+type zminProcess struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	out    io.Closer // standard output, read through stdout
+	stdout *bufio.Reader
+	stderr bytes.Buffer
+}
+
+// NewSubprocessBackend returns a backend running the zmin binary at path. A
+// path without a separator is looked up in PATH, as by exec.Command. args
+// are passed ahead of zmin's own arguments, so path may also be a wrapper,
+// such as a sandbox, given the zmin command in args.
+//
+// Workers are started on demand, one per concurrent call, and up to
+// GOMAXPROCS are kept running between calls. Close stops them. A worker that
+// has not replied within a minute is killed; SetTimeout changes the limit.
+func NewSubprocessBackend(path string, args ...string) *SubprocessBackend {
+	return &SubprocessBackend{path: path, args: args, timeout: defaultSubprocessTimeout}
+}
+
+// SetTimeout sets how long a worker has to reply to a call, or removes the
+// limit if d is 0. A worker that takes longer is killed, and the call fails
+// with ErrTimeout.
+func (b *SubprocessBackend) SetTimeout(d time.Duration) {
+	b.mu.Lock()
+	b.timeout = d
+	b.mu.Unlock()
+}
+
+// Minify minifies input in a worker process
+func (b *SubprocessBackend) Minify(input []byte, mode ProcessingMode) ([]byte, error) {
+	if mode < ECO || mode > AUTO {
+		return nil, ErrInvalidMode
+	}
+	mode = resolveMode(mode, len(input))
+	return b.call('m', mode, input)
+}
+
+// Validate validates input in a worker process. Invalid documents are
+// located with the Go scanner, as zmin only reports the kind of error.
+func (b *SubprocessBackend) Validate(input []byte) error {
+	_, err := b.call('v', 0, input)
+	return err
+}
+
+// Version returns the version reported by zmin --version, or "" if the
+// command cannot be run
+func (b *SubprocessBackend) Version() string {
+	b.versionOnce.Do(func() {
+		// zmin prints its version on standard error
+		output, err := b.command("--version").CombinedOutput()
+		if err != nil {
+			return
+		}
+		for _, line := range strings.Split(string(output), "\n") {
+			if rest := strings.TrimPrefix(line, "zmin "); rest != line {
+				b.version = strings.TrimSpace(rest)
+				return
+			}
+		}
+	})
+	return b.version
+}
+
+// Capabilities describes the zmin command. Modes is empty if it cannot be
+// run.
+func (b *SubprocessBackend) Capabilities() Capabilities {
+	caps := Capabilities{Version: b.Version(), Backend: "subprocess", MaxInputSize: subprocessMaxInput}
+	if caps.Version != "" {
+		caps.Modes = []ProcessingMode{ECO, SPORT, TURBO, AUTO}
+	}
+	return caps
+}
+
+// Close stops the idle workers. Calls after Close fail with ErrClosed, and
+// workers busy with a call stop when it returns.
+func (b *SubprocessBackend) Close() error {
+	b.mu.Lock()
+	idle := b.idle
+	b.idle, b.closed = nil, true
+	b.mu.Unlock()
+
+	for _, p := range idle {
+		p.stop()
+	}
+	return nil
+}
+
+// command returns the command running zmin with args
+func (b *SubprocessBackend) command(args ...string) *exec.Cmd {
+	return exec.Command(b.path, append(append([]string(nil), b.args...), args...)...)
+}
+
+// call sends a request to a worker and returns its output. A worker that
+// fails mid-call is stopped and reported as ErrUnknown.
+func (b *SubprocessBackend) call(op byte, mode ProcessingMode, input []byte) ([]byte, error) {
+	if int64(len(input)) > subprocessMaxInput {
+		return nil, ErrInputTooLarge
+	}
+	p, err := b.get()
+	if err != nil {
+		return nil, err
+	}
+	b.mu.Lock()
+	timeout := b.timeout
+	b.mu.Unlock()
+	code, output, err := p.request(op, mode, input, timeout)
+	if err != nil {
+		p.stop()
+		if errors.Is(err, ErrTimeout) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: zmin worker failed: %v", ErrUnknown, p.failure(err))
+	}
+	b.put(p)
+
+	if code != 0 {
+		err := errorForCode(code)
+		if err == nil {
+			err = fmt.Errorf("%w: zmin error %d", ErrUnknown, code)
+		}
+		return nil, locateError(input, err)
+	}
+	return output, nil
+}
+
+// get takes an idle worker, or starts one
+func (b *SubprocessBackend) get() (*zminProcess, error) {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil, ErrClosed
+	}
+	if n := len(b.idle); n > 0 {
+		p := b.idle[n-1]
+		b.idle = b.idle[:n-1]
+		b.mu.Unlock()
+		return p, nil
+	}
+	b.mu.Unlock()
+
+	cmd := b.command("--serve")
+	p := &zminProcess{cmd: cmd}
+	cmd.Stderr = &p.stderr
+	// A worker's own children may hold its output open after it exits
+	cmd.WaitDelay = subprocessStopTimeout
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrLibraryUnavailable, err)
+	}
+	p.stdin, p.out, p.stdout = stdin, stdout, bufio.NewReader(stdout)
+	return p, nil
+}
+
+// put returns a worker to the pool, or stops it if the pool is full or closed
+func (b *SubprocessBackend) put(p *zminProcess) {
+	b.mu.Lock()
+	if !b.closed && len(b.idle) < runtime.GOMAXPROCS(0) {
+		b.idle = append(b.idle, p)
+		p = nil
+	}
+	b.mu.Unlock()
+	if p != nil {
+		p.stop()
+	}
+}
+
+// request sends a request to the worker and reads its reply, framed as
+// zmin --serve expects. If timeout is positive and the reply has not been
+// read by then, the worker is killed and ErrTimeout returned.
+func (p *zminProcess) request(op byte, mode ProcessingMode, input []byte, timeout time.Duration) (code int, output []byte, err error) {
+	if timeout > 0 {
+		timer := time.AfterFunc(timeout, p.kill)
+		defer func() {
+			if !timer.Stop() {
+				code, output, err = 0, nil, fmt.Errorf("%w: zmin worker killed after %v", ErrTimeout, timeout)
+			}
+		}()
+	}
+	header := make([]byte, 6, 6+len(input))
+	header[0], header[1] = op, byte(mode)
+	binary.LittleEndian.PutUint32(header[2:], uint32(len(input)))
+	if _, err := p.stdin.Write(append(header, input...)); err != nil {
+		return 0, nil, err
+	}
+
+	var reply [8]byte
+	if _, err := io.ReadFull(p.stdout, reply[:]); err != nil {
+		return 0, nil, err
+	}
+	output = make([]byte, binary.LittleEndian.Uint32(reply[4:]))
+	if _, err := io.ReadFull(p.stdout, output); err != nil {
+		return 0, nil, err
+	}
+	return int(int32(binary.LittleEndian.Uint32(reply[:4]))), output, nil
+}
+
+// failure describes why the worker failed, from its exit status and standard
+// error if it has exited. p must have been stopped.
+func (p *zminProcess) failure(err error) error {
+	if p.cmd.ProcessState == nil || p.cmd.ProcessState.Success() {
+		return err
+	}
+	if msg := strings.TrimSpace(p.stderr.String()); msg != "" {
+		return fmt.Errorf("%v: %s", p.cmd.ProcessState, msg)
+	}
+	return fmt.Errorf("%v", p.cmd.ProcessState)
+}
+
+// stop closes the worker's standard input, which ends it, and waits for it
+// to exit, killing it if it has not within subprocessStopTimeout
+func (p *zminProcess) stop() {
+	p.stdin.Close()
+	timer := time.AfterFunc(subprocessStopTimeout, p.kill)
+	p.cmd.Wait()
+	timer.Stop()
+}
+
+// kill ends the worker and closes its pipes, so that pending reads and
+// writes fail even if another process holds the other ends
+func (p *zminProcess) kill() {
+	p.cmd.Process.Kill()
+	p.stdin.Close()
+	p.out.Close()
+}
@@ -0,0 +1,91 @@
+//go:build go1.23
+
+package zmin
+
+import (
+	"iter"
+)
+
+// TokenKind identifies the kind of a Token
+type TokenKind int
+
+const (
+	TokenObjectStart TokenKind = iota + 1
+	TokenObjectEnd
+	TokenArrayStart
+	TokenArrayEnd
+	TokenKey
+	TokenString
+	TokenNumber
+	TokenBool
+	TokenNull
+)
+
+var tokenKindNames = map[TokenKind]string{
+	TokenObjectStart: "ObjectStart",
+	TokenObjectEnd:   "ObjectEnd",
+	TokenArrayStart:  "ArrayStart",
+	TokenArrayEnd:    "ArrayEnd",
+	TokenKey:         "Key",
+	TokenString:      "String",
+	TokenNumber:      "Number",
+	TokenBool:        "Bool",
+	TokenNull:        "Null",
+}
+
+func (k TokenKind) String() string {
+	if name, ok := tokenKindNames[k]; ok {
+		return name
+	}
+	return "Unknown"
+}
+
+// Token is a lexical element of a JSON document
+type Token struct {
+	Kind   TokenKind
+	Offset int    // byte offset of the token in the input
+	Raw    []byte // source bytes, including the quotes of keys and strings
+}
+
+var tokenKinds = map[tokenKind]TokenKind{
+	tokObjectStart: TokenObjectStart,
+	tokObjectEnd:   TokenObjectEnd,
+	tokArrayStart:  TokenArrayStart,
+	tokArrayEnd:    TokenArrayEnd,
+	tokKey:         TokenKey,
+	tokString:      TokenString,
+	tokNumber:      TokenNumber,
+	tokTrue:        TokenBool,
+	tokFalse:       TokenBool,
+	tokNull:        TokenNull,
+}
+
+// Tokens returns an iterator over the tokens of a JSON document:
+//
+//	for tok, err := range zmin.Tokens(data) {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(tok.Offset, tok.Kind, string(tok.Raw))
+//	}
+//
+// A syntax error is yielded once, as a *SyntaxError, and ends the iteration.
+// Raw aliases input. The tokenizer is implemented in Go.
+func Tokens(input []byte) iter.Seq2[Token, error] {
+	return func(yield func(Token, error) bool) {
+		s := newScanner(input)
+		for {
+			tok, err := s.next()
+			if err != nil {
+				yield(Token{}, err)
+				return
+			}
+			if tok.kind == tokEOF {
+				return
+			}
+			if !yield(Token{Kind: tokenKinds[tok.kind], Offset: tok.offset, Raw: tok.raw}, nil) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,45 @@
+//go:build go1.23
+
+package zmin
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTokens(t *testing.T) {
+	var got []string
+	for tok, err := range Tokens([]byte(`{"a": [1, "x", true]}`)) {
+		if err != nil {
+			t.Fatalf("Tokens failed: %v", err)
+		}
+		got = append(got, fmt.Sprintf("%d:%s:%s", tok.Offset, tok.Kind, tok.Raw))
+	}
+	expected := `0:ObjectStart:{ 1:Key:"a" 6:ArrayStart:[ 7:Number:1 10:String:"x" 15:Bool:true 19:ArrayEnd:] 20:ObjectEnd:}`
+	if strings.Join(got, " ") != expected {
+		t.Errorf("Expected %q, got %q", expected, strings.Join(got, " "))
+	}
+
+	// Breaking out of the loop stops the iteration
+	count := 0
+	for range Tokens([]byte(`[1, 2, 3]`)) {
+		if count++; count == 2 {
+			break
+		}
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 iterations, got %d", count)
+	}
+
+	var lastErr error
+	count = 0
+	for _, err := range Tokens([]byte(`[1, }`)) {
+		count++
+		lastErr = err
+	}
+	if count != 3 || !errors.Is(lastErr, ErrInvalidJSON) {
+		t.Errorf("Expected 2 tokens then ErrInvalidJSON, got %d yields ending in %v", count, lastErr)
+	}
+}
@@ -0,0 +1,107 @@
+package zmin
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// MinifyTOML parses a TOML document and returns it as minified JSON, with the
+// keys of every table sorted. Dates and times become strings in their TOML
+// form, such as "2024-01-02" or "1979-05-27T07:32:00Z". Infinite and NaN
+// floats cannot be represented in JSON and return an error.
+func MinifyTOML(input []byte) ([]byte, error) {
+	data, err := convertTOML(input)
+	if err != nil {
+		return nil, err
+	}
+	return MinifyBytes(data, SPORT)
+}
+
+// convertTOML converts a TOML document to compact JSON
+func convertTOML(input []byte) ([]byte, error) {
+	var doc map[string]interface{}
+	if _, err := toml.Decode(string(input), &doc); err != nil {
+		return nil, err
+	}
+	return appendTOMLValue(make([]byte, 0, len(input)), doc)
+}
+
+// appendTOMLValue appends the JSON form of a decoded TOML value to dst
+func appendTOMLValue(dst []byte, v interface{}) ([]byte, error) {
+	var err error
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		dst = append(dst, '{')
+		for i, key := range keys {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = appendCanonicalString(dst, key)
+			dst = append(dst, ':')
+			if dst, err = appendTOMLValue(dst, v[key]); err != nil {
+				return nil, err
+			}
+		}
+		return append(dst, '}'), nil
+	case []map[string]interface{}:
+		dst = append(dst, '[')
+		for i, table := range v {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			if dst, err = appendTOMLValue(dst, table); err != nil {
+				return nil, err
+			}
+		}
+		return append(dst, ']'), nil
+	case []interface{}:
+		dst = append(dst, '[')
+		for i, elem := range v {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			if dst, err = appendTOMLValue(dst, elem); err != nil {
+				return nil, err
+			}
+		}
+		return append(dst, ']'), nil
+	case string:
+		return appendCanonicalString(dst, v), nil
+	case int64:
+		return strconv.AppendInt(dst, v, 10), nil
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return nil, fmt.Errorf("TOML float %v cannot be represented in JSON", v)
+		}
+		return strconv.AppendFloat(dst, v, 'g', -1, 64), nil
+	case bool:
+		return strconv.AppendBool(dst, v), nil
+	case time.Time:
+		return appendCanonicalString(dst, formatTOMLTime(v)), nil
+	}
+	return nil, fmt.Errorf("unsupported TOML value of type %T", v)
+}
+
+// formatTOMLTime formats a decoded date or time in its TOML form. Local dates
+// and times are decoded with marker locations, which select the layout.
+func formatTOMLTime(t time.Time) string {
+	switch t.Location().String() {
+	case "date-local":
+		return t.Format("2006-01-02")
+	case "time-local":
+		return t.Format("15:04:05.999999999")
+	case "datetime-local":
+		return t.Format("2006-01-02T15:04:05.999999999")
+	}
+	return t.Format(time.RFC3339Nano)
+}
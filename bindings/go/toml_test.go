@@ -0,0 +1,40 @@
+package zmin
+
+import (
+	"testing"
+)
+
+func TestMinifyTOML(t *testing.T) {
+	input := `
+title = "app <web>"
+ratio = 0.5
+
+[server]
+port = 8080
+hosts = ["a", "b"]
+started = 1979-05-27T07:32:00Z
+day = 2024-01-02
+
+[[plugins]]
+name = "x"
+
+[[plugins]]
+name = "y"
+enabled = true
+`
+	output, err := MinifyTOML([]byte(input))
+	if err != nil {
+		t.Fatalf("MinifyTOML failed: %v", err)
+	}
+	expected := `{"plugins":[{"name":"x"},{"enabled":true,"name":"y"}],"ratio":0.5,` +
+		`"server":{"day":"2024-01-02","hosts":["a","b"],"port":8080,"started":"1979-05-27T07:32:00Z"},"title":"app <web>"}`
+	if string(output) != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+
+	for _, input := range []string{"a = [1,\n", "a = nan\n"} {
+		if _, err := MinifyTOML([]byte(input)); err == nil {
+			t.Errorf("MinifyTOML(%q): expected an error", input)
+		}
+	}
+}
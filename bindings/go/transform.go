@@ -0,0 +1,456 @@
+package zmin
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// defaultRedactToken replaces redacted values when Options.RedactToken is empty
+const defaultRedactToken = "***"
+
+// transformer rewrites a minified document according to Options. It walks the
+// token stream recursively, tracking the JSON Pointer path of the value being
+// processed.
+type transformer struct {
+	opts        *Options
+	s           *scanner
+	out         []byte
+	path        []string
+	redact      [][]string
+	redactToken []byte
+	include     [][]string
+	exclude     [][]string
+}
+
+// newTransformer prepares a transformer for opts
+func newTransformer(opts *Options) (*transformer, error) {
+	t := &transformer{opts: opts}
+	var err error
+	for _, p := range opts.RedactPaths {
+		pattern, err := parsePointer(p)
+		if err != nil {
+			return nil, err
+		}
+		t.redact = append(t.redact, pattern)
+	}
+	if t.include, err = parseFieldPaths(opts.IncludePaths); err != nil {
+		return nil, err
+	}
+	if t.exclude, err = parseFieldPaths(opts.ExcludePaths); err != nil {
+		return nil, err
+	}
+	for _, rule := range opts.RedactRules {
+		if _, err := path.Match(rule.Key, ""); err != nil {
+			return nil, fmt.Errorf("invalid redact key %q: %w", rule.Key, err)
+		}
+	}
+	if len(t.redact) > 0 || len(opts.RedactRules) > 0 {
+		redactToken := opts.RedactToken
+		if redactToken == "" {
+			redactToken = defaultRedactToken
+		}
+		value, err := json.Marshal(redactToken)
+		if err != nil {
+			return nil, err
+		}
+		t.redactToken = value
+	}
+	return t, nil
+}
+
+// run transforms a complete document
+func (t *transformer) run(data []byte) ([]byte, error) {
+	t.s = newScanner(data)
+	t.out = make([]byte, 0, len(data))
+	tok, err := t.s.next()
+	if err != nil {
+		return nil, err
+	}
+	if err := t.value(tok); err != nil {
+		return nil, err
+	}
+	if _, err := t.s.next(); err != nil {
+		return nil, err
+	}
+	return t.out, nil
+}
+
+// value writes the value that tok begins
+func (t *transformer) value(tok token) error {
+	if t.redacted() {
+		return t.redactValue(tok)
+	}
+
+	switch tok.kind {
+	case tokObjectStart:
+		return t.object()
+	case tokArrayStart:
+		return t.array()
+	case tokString:
+		return t.appendString(tok.raw)
+	case tokNumber:
+		return t.appendNumber(tok.raw)
+	default:
+		t.out = append(t.out, tok.raw...)
+		return nil
+	}
+}
+
+// appendString writes a raw string or key token, applying the configured
+// length limit and escaping. Existing escape sequences are kept as they are.
+func (t *transformer) appendString(raw []byte) error {
+	if t.opts.MaxStringLen > 0 && len(raw)-2 > t.opts.MaxStringLen {
+		return ErrStringTooLong
+	}
+	if !t.opts.ASCIIOnly && !t.opts.HTMLSafe {
+		t.out = append(t.out, raw...)
+		return nil
+	}
+	for i := 0; i < len(raw); {
+		c := raw[i]
+		if c < utf8.RuneSelf {
+			if t.opts.HTMLSafe && (c == '<' || c == '>' || c == '&') {
+				t.out = appendEscapedRune(t.out, rune(c))
+			} else {
+				t.out = append(t.out, c)
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRune(raw[i:])
+		switch {
+		case t.opts.ASCIIOnly:
+			if r1, r2 := utf16.EncodeRune(r); r1 != utf8.RuneError {
+				t.out = appendEscapedRune(t.out, r1)
+				t.out = appendEscapedRune(t.out, r2)
+			} else {
+				t.out = appendEscapedRune(t.out, r)
+			}
+		case r == '\u2028' || r == '\u2029':
+			t.out = appendEscapedRune(t.out, r)
+		default:
+			t.out = append(t.out, raw[i:i+size]...)
+		}
+		i += size
+	}
+	return nil
+}
+
+// appendNumber writes a raw number token, applying the configured checks and
+// normalization
+func (t *transformer) appendNumber(raw []byte) error {
+	integer := isInteger(raw)
+	if integer && t.opts.RejectUnsafeIntegers && !isSafeInteger(raw) {
+		return fmt.Errorf("%w: %s", ErrUnsafeInteger, raw)
+	}
+	if !t.opts.NormalizeNumbers {
+		t.out = append(t.out, raw...)
+		return nil
+	}
+	if integer {
+		if string(raw) == "-0" {
+			raw = raw[1:]
+		}
+		t.out = append(t.out, raw...)
+		return nil
+	}
+	// Numbers beyond float64 range are valid JSON and are kept as written
+	if out, err := appendCanonicalNumber(t.out, raw); err == nil {
+		t.out = out
+	} else {
+		t.out = append(t.out, raw...)
+	}
+	return nil
+}
+
+// isInteger reports whether a number token has neither fraction nor exponent
+func isInteger(raw []byte) bool {
+	for _, c := range raw {
+		if c == '.' || c == 'e' || c == 'E' {
+			return false
+		}
+	}
+	return true
+}
+
+// isSafeInteger reports whether an integer token is within ±2^53
+func isSafeInteger(raw []byte) bool {
+	n, err := strconv.ParseInt(string(raw), 10, 64)
+	return err == nil && n <= 1<<53 && n >= -(1<<53)
+}
+
+// appendEscapedRune appends r, which must be in the Basic Multilingual Plane,
+// as a \uXXXX escape
+func appendEscapedRune(dst []byte, r rune) []byte {
+	const hex = "0123456789abcdef"
+	return append(dst, '\\', 'u', hex[r>>12&0xf], hex[r>>8&0xf], hex[r>>4&0xf], hex[r&0xf])
+}
+
+// enter checks the depth limit before descending into a container
+func (t *transformer) enter() error {
+	if t.opts.MaxDepth > 0 && len(t.path) >= t.opts.MaxDepth {
+		return ErrTooDeep
+	}
+	return nil
+}
+
+// object writes the members of an object whose '{' has been consumed
+func (t *transformer) object() error {
+	if err := t.enter(); err != nil {
+		return err
+	}
+	t.out = append(t.out, '{')
+	body := len(t.out)
+	var members []memberSpan
+	var seen map[string]int
+	duplicates := false
+	if t.opts.DuplicateKeys != KeepAll {
+		seen = make(map[string]int)
+	}
+	for {
+		tok, err := t.s.next()
+		if err != nil {
+			return err
+		}
+		if tok.kind == tokObjectEnd {
+			break
+		}
+		key, err := decodeString(tok.raw)
+		if err != nil {
+			return err
+		}
+		valueTok, err := t.s.next()
+		if err != nil {
+			return err
+		}
+		if t.filtered(key, valueTok) {
+			if _, err := t.s.finishValue(valueTok); err != nil {
+				return err
+			}
+			continue
+		}
+		raw, outKey := tok.raw, key
+		if t.opts.KeyTransform != nil {
+			if outKey = t.opts.KeyTransform(formatPointer(t.path), key); outKey != key {
+				raw = appendCanonicalString(nil, outKey)
+			}
+		}
+		if _, ok := seen[outKey]; ok {
+			if t.opts.DuplicateKeys == Reject {
+				return fmt.Errorf("%w %q", ErrDuplicateKey, outKey)
+			}
+			duplicates = true
+		}
+		previous := len(t.out)
+		if previous > body {
+			t.out = append(t.out, ',')
+		}
+		start := len(t.out)
+		if err := t.appendString(raw); err != nil {
+			return err
+		}
+		t.out = append(t.out, ':')
+
+		valueStart := len(t.out)
+		if err := t.member(key, valueTok); err != nil {
+			return err
+		}
+		if t.omitted(t.out[valueStart:]) {
+			t.out = t.out[:previous]
+			continue
+		}
+		member := memberSpan{key: outKey, start: start, end: len(t.out)}
+		if i, ok := seen[outKey]; ok {
+			if t.opts.DuplicateKeys == KeepLast {
+				members[i] = member
+			}
+			continue
+		}
+		if seen != nil {
+			seen[outKey] = len(members)
+		}
+		members = append(members, member)
+	}
+	if t.opts.SortKeys {
+		sort.SliceStable(members, func(i, j int) bool {
+			return members[i].key < members[j].key
+		})
+	}
+	if t.opts.SortKeys || duplicates {
+		t.rewriteMembers(body, members)
+	}
+	t.out = append(t.out, '}')
+	return nil
+}
+
+// omitted reports whether a member with the written value is dropped by
+// OmitNull or OmitEmpty
+func (t *transformer) omitted(value []byte) bool {
+	switch string(value) {
+	case "null":
+		return t.opts.OmitNull
+	case "{}", "[]":
+		return t.opts.OmitEmpty
+	}
+	return false
+}
+
+// memberSpan locates a member written to the output
+type memberSpan struct {
+	key        string
+	start, end int
+}
+
+// rewriteMembers replaces the object members written since body with members,
+// in order
+func (t *transformer) rewriteMembers(body int, members []memberSpan) {
+	written := append([]byte(nil), t.out[body:]...)
+	t.out = t.out[:body]
+	for i, m := range members {
+		if i > 0 {
+			t.out = append(t.out, ',')
+		}
+		t.out = append(t.out, written[m.start-body:m.end-body]...)
+	}
+}
+
+// member writes the value that tok begins, stored under key, and enforces its
+// size limit
+func (t *transformer) member(key string, tok token) error {
+	t.path = append(t.path, key)
+	start := len(t.out)
+	write := t.value
+	if t.redactedKey(key) {
+		write = t.redactValue
+	}
+	if err := write(tok); err != nil {
+		return err
+	}
+	t.path = t.path[:len(t.path)-1]
+
+	if limit, ok := t.opts.MaxFieldBytes[key]; ok {
+		if size := len(t.out) - start; size > limit {
+			return &FieldTooLargeError{Key: key, Size: size, Limit: limit}
+		}
+	}
+	return nil
+}
+
+// array writes the elements of an array whose '[' has been consumed
+func (t *transformer) array() error {
+	if err := t.enter(); err != nil {
+		return err
+	}
+	t.out = append(t.out, '[')
+	body := len(t.out)
+	for i := 0; ; i++ {
+		tok, err := t.s.next()
+		if err != nil {
+			return err
+		}
+		if tok.kind == tokArrayEnd {
+			break
+		}
+		index := strconv.Itoa(i)
+		if t.filtered(index, tok) {
+			if _, err := t.s.finishValue(tok); err != nil {
+				return err
+			}
+			continue
+		}
+		if len(t.out) > body {
+			t.out = append(t.out, ',')
+		}
+		t.path = append(t.path, index)
+		if err := t.value(tok); err != nil {
+			return err
+		}
+		t.path = t.path[:len(t.path)-1]
+	}
+	t.out = append(t.out, ']')
+	return nil
+}
+
+// redactValue skips the value that tok begins and writes the redaction token
+// in its place
+func (t *transformer) redactValue(tok token) error {
+	if _, err := t.s.finishValue(tok); err != nil {
+		return err
+	}
+	t.out = append(t.out, t.redactToken...)
+	return nil
+}
+
+// redactedKey reports whether the value stored under key must be redacted
+func (t *transformer) redactedKey(key string) bool {
+	for _, rule := range t.opts.RedactRules {
+		if rule.matches(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// redacted reports whether the value at the current path must be redacted
+func (t *transformer) redacted() bool {
+	for _, pattern := range t.redact {
+		if matchPath(pattern, t.path) {
+			return true
+		}
+	}
+	return false
+}
+
+// filtered reports whether the member or element named segment of the current
+// container, whose value tok begins, is dropped by the include and exclude
+// paths
+func (t *transformer) filtered(segment string, tok token) bool {
+	if len(t.include) == 0 && len(t.exclude) == 0 {
+		return false
+	}
+	path := append(t.path[:len(t.path):len(t.path)], segment)
+
+	for _, pattern := range t.exclude {
+		if matchPath(pattern, path) {
+			return true
+		}
+	}
+	if len(t.include) == 0 {
+		return false
+	}
+	container := tok.kind == tokObjectStart || tok.kind == tokArrayStart
+	for _, pattern := range t.include {
+		if len(pattern) <= len(path) && matchPath(pattern, path[:len(pattern)]) {
+			return false // within an included subtree
+		}
+		if container && len(pattern) > len(path) && matchPath(pattern[:len(path)], path) {
+			return false // on the way to an included value
+		}
+	}
+	return true
+}
+
+// parseFieldPaths parses include or exclude paths, which are JSON Pointers if
+// they are empty or start with '/' and dotted key paths such as "debug.trace"
+// otherwise
+func parseFieldPaths(paths []string) ([][]string, error) {
+	var patterns [][]string
+	for _, p := range paths {
+		if p == "" || p[0] == '/' {
+			pattern, err := parsePointer(p)
+			if err != nil {
+				return nil, err
+			}
+			patterns = append(patterns, pattern)
+			continue
+		}
+		patterns = append(patterns, strings.Split(p, "."))
+	}
+	return patterns, nil
+}
@@ -0,0 +1,203 @@
+package zmin
+
+import (
+	"fmt"
+)
+
+// jsonNode is a parsed JSON value that keeps its minified source, for the
+// operations that compare or rewrite documents structurally
+type jsonNode struct {
+	kind    tokenKind            // tokObjectStart, tokArrayStart or the scalar kind
+	raw     []byte               // minified source of the value
+	keys    []string             // object keys in document order
+	members map[string]*jsonNode // object members by key
+	elems   []*jsonNode          // array elements
+	scalar  string               // decoded string or canonical number
+}
+
+// parseTree minifies input and parses it into a tree. Duplicate keys are
+// rejected with ErrDuplicateKey.
+func parseTree(input []byte) (*jsonNode, error) {
+	data, err := MinifyBytes(input, SPORT)
+	if err != nil {
+		return nil, err
+	}
+	s := newScanner(data)
+	tok, err := s.next()
+	if err != nil {
+		return nil, err
+	}
+	n, err := parseNode(s, tok)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.next(); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// parseNode parses the value that tok begins
+func parseNode(s *scanner, tok token) (*jsonNode, error) {
+	n := &jsonNode{kind: tok.kind}
+	switch tok.kind {
+	case tokObjectStart:
+		n.members = make(map[string]*jsonNode)
+		for {
+			keyTok, err := s.next()
+			if err != nil {
+				return nil, err
+			}
+			if keyTok.kind == tokObjectEnd {
+				n.raw = s.data[tok.offset:s.pos]
+				return n, nil
+			}
+			key, err := decodeString(keyTok.raw)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := n.members[key]; ok {
+				return nil, fmt.Errorf("%w %q", ErrDuplicateKey, key)
+			}
+			valueTok, err := s.next()
+			if err != nil {
+				return nil, err
+			}
+			member, err := parseNode(s, valueTok)
+			if err != nil {
+				return nil, err
+			}
+			n.keys = append(n.keys, key)
+			n.members[key] = member
+		}
+	case tokArrayStart:
+		for {
+			elemTok, err := s.next()
+			if err != nil {
+				return nil, err
+			}
+			if elemTok.kind == tokArrayEnd {
+				n.raw = s.data[tok.offset:s.pos]
+				return n, nil
+			}
+			elem, err := parseNode(s, elemTok)
+			if err != nil {
+				return nil, err
+			}
+			n.elems = append(n.elems, elem)
+		}
+	case tokString:
+		str, err := decodeString(tok.raw)
+		if err != nil {
+			return nil, err
+		}
+		n.scalar = str
+	case tokNumber:
+		// Numbers beyond float64 range have no canonical form and compare
+		// by their source
+		if number, err := appendCanonicalNumber(nil, tok.raw); err == nil {
+			n.scalar = string(number)
+		} else {
+			n.scalar = string(tok.raw)
+		}
+	}
+	n.raw = tok.raw
+	return n, nil
+}
+
+// equal reports whether two trees are semantically equal, as Equal defines it
+func (n *jsonNode) equal(other *jsonNode) bool {
+	if n.kind != other.kind {
+		return false
+	}
+	switch n.kind {
+	case tokObjectStart:
+		if len(n.keys) != len(other.keys) {
+			return false
+		}
+		for key, member := range n.members {
+			if otherMember, ok := other.members[key]; !ok || !member.equal(otherMember) {
+				return false
+			}
+		}
+		return true
+	case tokArrayStart:
+		if len(n.elems) != len(other.elems) {
+			return false
+		}
+		for i, elem := range n.elems {
+			if !elem.equal(other.elems[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return n.scalar == other.scalar
+	}
+}
+
+// appendTo appends the minified encoding of the tree to dst
+func (n *jsonNode) appendTo(dst []byte) []byte {
+	switch n.kind {
+	case tokObjectStart:
+		dst = append(dst, '{')
+		for i, key := range n.keys {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = appendCanonicalString(dst, key)
+			dst = append(dst, ':')
+			dst = n.members[key].appendTo(dst)
+		}
+		return append(dst, '}')
+	case tokArrayStart:
+		dst = append(dst, '[')
+		for i, elem := range n.elems {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = elem.appendTo(dst)
+		}
+		return append(dst, ']')
+	default:
+		return append(dst, n.raw...)
+	}
+}
+
+// clone returns a deep copy of the tree, sharing only the immutable source
+func (n *jsonNode) clone() *jsonNode {
+	c := *n
+	if n.members != nil {
+		c.keys = append([]string(nil), n.keys...)
+		c.members = make(map[string]*jsonNode, len(n.members))
+		for key, member := range n.members {
+			c.members[key] = member.clone()
+		}
+	}
+	if n.elems != nil {
+		c.elems = make([]*jsonNode, len(n.elems))
+		for i, elem := range n.elems {
+			c.elems[i] = elem.clone()
+		}
+	}
+	return &c
+}
+
+// set stores value under key, keeping the position of an existing member
+func (n *jsonNode) set(key string, value *jsonNode) {
+	if _, ok := n.members[key]; !ok {
+		n.keys = append(n.keys, key)
+	}
+	n.members[key] = value
+}
+
+// delete removes the member named key
+func (n *jsonNode) delete(key string) {
+	delete(n.members, key)
+	for i, k := range n.keys {
+		if k == key {
+			n.keys = append(n.keys[:i], n.keys[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package zmin
+
+// MinifyUpTo minifies complete top-level values from the start of a stream of
+// whitespace-separated JSON values, stopping before the value that would take
+// the amount of input consumed past budget bytes. Each minified value in
+// output is followed by a newline, so the outputs of successive calls can be
+// concatenated.
+//
+// consumed is the number of input bytes processed; the caller resumes with
+// input[consumed:]. Processing only stops at value boundaries, so resumption
+// is always clean. done reports whether the whole input was consumed. If the
+// first value alone is larger than budget, nothing is consumed.
+//
+// On error, output and consumed describe the values processed before the
+// invalid one.
+func MinifyUpTo(input []byte, mode ProcessingMode, budget int) (output []byte, consumed int, done bool, err error) {
+	for {
+		start := consumed
+		for start < len(input) && isSpace(input[start]) {
+			start++
+		}
+		if start == len(input) {
+			if start <= budget {
+				consumed = start
+			}
+			return output, consumed, consumed == len(input), nil
+		}
+
+		n, err := newScanner(input[start:]).skipValue()
+		if err != nil {
+			return output, consumed, false, err
+		}
+		end := start + n
+		if end > budget {
+			return output, consumed, false, nil
+		}
+
+		minified, err := MinifyBytes(input[start:end], mode)
+		if err != nil {
+			return output, consumed, false, err
+		}
+		output = append(output, minified...)
+		output = append(output, '\n')
+		consumed = end
+	}
+}
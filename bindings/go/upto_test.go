@@ -0,0 +1,55 @@
+package zmin
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMinifyUpTo(t *testing.T) {
+	input := []byte("{ \"a\": 1 }\n[ 1, 2 ]\n\"three\"\n")
+
+	// A budget that fits everything finishes in one call
+	output, consumed, done, err := MinifyUpTo(input, SPORT, len(input))
+	if err != nil {
+		t.Fatalf("MinifyUpTo failed: %v", err)
+	}
+	if string(output) != "{\"a\":1}\n[1,2]\n\"three\"\n" || consumed != len(input) || !done {
+		t.Errorf("Unexpected result: %q, %d, %v", output, consumed, done)
+	}
+
+	// Small budgets stop at value boundaries and resume cleanly
+	var all []byte
+	rest := input
+	for calls := 0; ; calls++ {
+		if calls > len(input) {
+			t.Fatal("MinifyUpTo made no progress")
+		}
+		output, consumed, done, err := MinifyUpTo(rest, SPORT, 12)
+		if err != nil {
+			t.Fatalf("MinifyUpTo failed: %v", err)
+		}
+		all = append(all, output...)
+		rest = rest[consumed:]
+		if done {
+			break
+		}
+	}
+	if string(all) != "{\"a\":1}\n[1,2]\n\"three\"\n" {
+		t.Errorf("Unexpected resumed output: %q", all)
+	}
+
+	// A first value larger than the budget consumes nothing
+	_, consumed, done, err = MinifyUpTo(input, SPORT, 5)
+	if err != nil || consumed != 0 || done {
+		t.Errorf("Expected no progress, got %d, %v, %v", consumed, done, err)
+	}
+
+	// Errors report the values processed before the invalid one
+	output, consumed, _, err = MinifyUpTo([]byte(`1 {"a": } 2`), SPORT, 100)
+	if !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON, got %v", err)
+	}
+	if string(output) != "1\n" || consumed != 1 {
+		t.Errorf("Unexpected partial result: %q, %d", output, consumed)
+	}
+}
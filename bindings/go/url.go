@@ -0,0 +1,44 @@
+package zmin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// MinifyURL fetches a JSON document with an HTTP GET and returns it minified
+// by a Minifier configured by opts. The body is read no further than the
+// limit set by WithMaxInputSize, which also rejects responses announcing a
+// larger Content-Length before reading them, and gzip-compressed bodies are
+// decompressed. The request, including reading the body, is abandoned with
+// ctx.Err() once ctx is done. Responses with a status other than 2xx are
+// reported as errors.
+func MinifyURL(ctx context.Context, url string, opts ...Option) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+
+	m := NewMinifier(opts...)
+	defer m.Close()
+	if limit := m.opts.inputLimit(); limit > 0 && resp.ContentLength > int64(limit) {
+		return nil, ErrInputTooLarge
+	}
+	output, err := m.MinifyReader(contextReader{ctx: ctx, r: resp.Body})
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, err
+	}
+	return []byte(output), nil
+}
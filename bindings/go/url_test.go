@@ -0,0 +1,51 @@
+package zmin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMinifyURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/doc":
+			w.Write([]byte(`{ "a": [1, 2] }`))
+		case "/big":
+			w.Write([]byte(`[` + strings.Repeat(`1, `, 100) + `1]`))
+		case "/slow":
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	output, err := MinifyURL(context.Background(), server.URL+"/doc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(output) != `{"a":[1,2]}` {
+		t.Errorf("Expected %q, got %q", `{"a":[1,2]}`, output)
+	}
+
+	if _, err := MinifyURL(context.Background(), server.URL+"/big", WithMaxInputSize(50)); !errors.Is(err, ErrInputTooLarge) {
+		t.Errorf("Expected ErrInputTooLarge, got %v", err)
+	}
+	if _, err := MinifyURL(context.Background(), server.URL+"/missing"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected a 404 error, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := MinifyURL(ctx, server.URL+"/slow"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package zmin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// snippetContext is the number of bytes shown on either side of an error
+const snippetContext = 24
+
+// SyntaxError describes malformed JSON and where it was found. It matches
+// ErrInvalidJSON with errors.Is.
+type SyntaxError struct {
+	Offset  int64  // byte offset of the error
+	Line    int    // line of the error, starting at 1
+	Column  int    // byte column of the error within its line, starting at 1
+	Message string // description of the problem
+	Snippet string // surrounding input, with the offending byte marked >>like this<<
+	Err     error  // specific error reported by the native library, if any
+}
+
+func (e *SyntaxError) Error() string {
+	msg := fmt.Sprintf("%s: %s at line %d, column %d (offset %d)", ErrInvalidJSON, e.Message, e.Line, e.Column, e.Offset)
+	if e.Snippet != "" {
+		msg += fmt.Sprintf(" near %q", e.Snippet)
+	}
+	return msg
+}
+
+// Is reports whether target is ErrInvalidJSON
+func (e *SyntaxError) Is(target error) bool {
+	return target == ErrInvalidJSON
+}
+
+// Unwrap returns the specific error reported by the native library
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// newSyntaxError builds a SyntaxError for offset within data
+func newSyntaxError(data []byte, offset int, message string) *SyntaxError {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	line := bytes.Count(data[:offset], []byte{'\n'}) + 1
+	lineStart := bytes.LastIndexByte(data[:offset], '\n') + 1
+	return &SyntaxError{
+		Offset:  int64(offset),
+		Line:    line,
+		Column:  offset - lineStart + 1,
+		Message: message,
+		Snippet: snippet(data, offset),
+	}
+}
+
+// snippet excerpts the input around offset, marking the byte at offset
+func snippet(data []byte, offset int) string {
+	start := offset - snippetContext
+	if start < 0 {
+		start = 0
+	}
+	end := offset + 1 + snippetContext
+	if end > len(data) {
+		end = len(data)
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("...")
+	}
+	b.Write(data[start:offset])
+	b.WriteString(">>")
+	if offset < len(data) {
+		b.WriteByte(data[offset])
+		b.WriteString("<<")
+		b.Write(data[offset+1 : end])
+	} else {
+		b.WriteString("<<")
+	}
+	if end < len(data) {
+		b.WriteString("...")
+	}
+	// Keep the excerpt on one line
+	return strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\r' || r == '\t' {
+			return ' '
+		}
+		return r
+	}, b.String())
+}
+
+// scanDocument runs the Go scanner over a complete document
+func scanDocument(input []byte) error {
+	s := newScanner(input)
+	for {
+		tok, err := s.next()
+		if err != nil {
+			return err
+		}
+		if tok.kind == tokEOF {
+			return nil
+		}
+	}
+}
+
+// locateError turns a native syntax error, which carries no position, into a
+// *SyntaxError pointing at the problem in input. Other errors are returned
+// unchanged.
+func locateError(input []byte, err error) error {
+	if !errors.Is(err, ErrInvalidJSON) {
+		return err
+	}
+	var syntaxErr *SyntaxError
+	if errors.As(scanDocument(input), &syntaxErr) {
+		syntaxErr.Err = err
+		return syntaxErr
+	}
+	return err
+}
+
+// ValidateWithError checks if the input is valid JSON. It returns nil for
+// valid JSON and a *SyntaxError locating the problem otherwise.
+func ValidateWithError(input []byte) error {
+	if validateBytes(input) {
+		return nil
+	}
+
+	// The native validator only reports failure, so locate the error in Go
+	if err := scanDocument(input); err != nil {
+		return err
+	}
+	return ErrInvalidJSON
+}
+
+// ValidateReader validates JSON read from r without holding the document in
+// memory. It returns nil for a valid document, a *SyntaxError for invalid
+// JSON, or the error returned by r.
+func ValidateReader(r io.Reader) error {
+	var s incrementalScanner
+	s.reset()
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(buf)
+		for _, c := range buf[:n] {
+			if s.feed(c) == scanError {
+				return s.err
+			}
+		}
+		if err == io.EOF {
+			return s.eof()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package zmin
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestValidateReader(t *testing.T) {
+	valid := `{"name": "John", "tags": ["a", "b"], "age": 30.5e1}`
+	if err := ValidateReader(iotest.OneByteReader(strings.NewReader(valid))); err != nil {
+		t.Errorf("Valid JSON was rejected: %v", err)
+	}
+
+	err := ValidateReader(strings.NewReader(`{"name": "John", "age": 30,}`))
+	if !errors.Is(err, ErrInvalidJSON) {
+		t.Fatalf("Expected ErrInvalidJSON, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "column 28 (offset 27)") {
+		t.Errorf("Expected error to report column 28, got %v", err)
+	}
+
+	if err := ValidateReader(strings.NewReader(`[1, 2`)); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON for truncated input, got %v", err)
+	}
+
+	readErr := errors.New("read failed")
+	r := io.MultiReader(strings.NewReader(`{"a":`), iotest.ErrReader(readErr))
+	if err := ValidateReader(r); err != readErr {
+		t.Errorf("Expected read error, got %v", err)
+	}
+}
+
+func TestValidateWithError(t *testing.T) {
+	if err := ValidateWithError([]byte(`{"name": "John"}`)); err != nil {
+		t.Errorf("Valid JSON was rejected: %v", err)
+	}
+
+	input := []byte("{\n  \"name\": \"John\",\n  \"age\": 30,\n}")
+	err := ValidateWithError(input)
+	if !errors.Is(err, ErrInvalidJSON) {
+		t.Fatalf("Expected ErrInvalidJSON, got %v", err)
+	}
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("Expected *SyntaxError, got %T", err)
+	}
+	expected := SyntaxError{
+		Offset:  33,
+		Line:    4,
+		Column:  1,
+		Message: "expected object key but found '}'",
+		Snippet: `...": "John",   "age": 30, >>}<<`,
+	}
+	if *syntaxErr != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *syntaxErr)
+	}
+
+	// The streaming validator reports the same position, without a snippet
+	err = ValidateReader(strings.NewReader(string(input)))
+	expected.Snippet = ""
+	if !errors.As(err, &syntaxErr) || *syntaxErr != expected {
+		t.Errorf("Expected %+v from ValidateReader, got %v", expected, err)
+	}
+}
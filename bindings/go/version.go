@@ -1,4 +1,5 @@
 package zmin
 
-// Version is the current version of zmin
-const Version = "1.0.0"
\ No newline at end of file
+// BindingVersion is the version of these Go bindings. They support native
+// libraries of the same major version.
+const BindingVersion = "1.0.0"
@@ -0,0 +1,384 @@
+package zmin
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// defaultWatchDebounce is how long a file must stay unchanged before a
+// Watcher minifies it
+const defaultWatchDebounce = 100 * time.Millisecond
+
+// WatchOptions configures a Watcher
+type WatchOptions struct {
+	// OutputDir receives the minified files: a watched file is written under
+	// its base name and files within a watched directory mirror its layout.
+	// If empty, files are minified in place.
+	OutputDir string
+
+	// Patterns selects the files within watched directories by base name,
+	// using the syntax of filepath.Match. Defaults to "*.json". Files
+	// watched directly are always minified.
+	Patterns []string
+
+	// Debounce is how long a changed file must stay unchanged before it is
+	// minified, so that a file being written is not minified half-way.
+	// Defaults to 100ms.
+	Debounce time.Duration
+
+	// Options configures the Minifier used for the files
+	Options []Option
+}
+
+// WatchEvent reports a changed file that was minified, or failed to be. An
+// event with an empty Path reports an error from the change notifications,
+// such as an overflow of the operating system's event queue.
+type WatchEvent struct {
+	Path        string        // changed input file
+	Output      string        // file the output was written to
+	InputBytes  int64         // size of the input
+	OutputBytes int64         // size of the output
+	Duration    time.Duration // time taken to minify and write the file
+	Err         error         // nil on success
+}
+
+// Watcher monitors files and directories and re-minifies JSON files when they
+// change, for dev servers and asset pipelines. Changes are reported by the
+// operating system through fsnotify; directories are watched recursively,
+// including those created later. Files present when the Watcher starts are
+// not minified until they change, and the Watcher's own rewrites of files
+// minified in place are not taken for changes.
+type Watcher struct {
+	// Events receives the outcome of each re-minification. It must be
+	// drained, and is closed by Close.
+	Events <-chan WatchEvent
+
+	events    chan WatchEvent
+	opts      WatchOptions
+	files     []string // watched files
+	dirs      []string // watched directory trees
+	patterns  []string
+	outputDir string // absolute, skipped when inside a watched directory
+	minifier  *Minifier
+	notify    *fsnotify.Watcher
+
+	pending map[string]time.Time // changed files, with the time of the last change
+	written map[string]fileState // files minified in place, as last written
+
+	done      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+}
+
+// fileState identifies a version of a file
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewWatcher starts watching paths, which may be files or directories;
+// directories are watched recursively
+func NewWatcher(opts WatchOptions, paths ...string) (*Watcher, error) {
+	patterns := opts.Patterns
+	if len(patterns) == 0 {
+		patterns = []string{"*.json"}
+	}
+	for _, pattern := range patterns {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Debounce <= 0 {
+		opts.Debounce = defaultWatchDebounce
+	}
+
+	w := &Watcher{
+		events:   make(chan WatchEvent, 16),
+		opts:     opts,
+		patterns: patterns,
+		pending:  make(map[string]time.Time),
+		written:  make(map[string]fileState),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	w.Events = w.events
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			w.dirs = append(w.dirs, path)
+		} else {
+			w.files = append(w.files, path)
+		}
+	}
+	if opts.OutputDir != "" {
+		outputDir, err := filepath.Abs(opts.OutputDir)
+		if err != nil {
+			return nil, err
+		}
+		w.outputDir = outputDir
+	}
+
+	notify, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	w.notify = notify
+	// Files are watched through their directory, as editors often replace
+	// a file rather than write to it
+	for _, file := range w.files {
+		if err := notify.Add(filepath.Dir(file)); err != nil {
+			notify.Close()
+			return nil, err
+		}
+	}
+	for _, dir := range w.dirs {
+		if err := w.watchTree(dir, false); err != nil {
+			notify.Close()
+			return nil, err
+		}
+	}
+	w.minifier = NewMinifier(opts.Options...)
+
+	go w.run()
+	return w, nil
+}
+
+// Close stops watching and closes Events
+func (w *Watcher) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.done)
+		<-w.stopped
+		w.notify.Close()
+		w.minifier.Close()
+		close(w.events)
+	})
+	return nil
+}
+
+// run handles change notifications until the Watcher is closed, minifying
+// files once they settle
+func (w *Watcher) run() {
+	defer close(w.stopped)
+	settle := time.NewTimer(w.opts.Debounce)
+	settle.Stop()
+	armed := false
+	for {
+		select {
+		case <-w.done:
+			return
+		case event, ok := <-w.notify.Events:
+			if !ok {
+				return
+			}
+			if w.changed(event) && !armed {
+				settle.Reset(w.opts.Debounce)
+				armed = true
+			}
+		case err, ok := <-w.notify.Errors:
+			if !ok || !w.send(WatchEvent{Err: err}) {
+				return
+			}
+		case now := <-settle.C:
+			armed = false
+			if !w.flush(now) {
+				return
+			}
+			if wait, ok := w.nextSettle(now); ok {
+				settle.Reset(wait)
+				armed = true
+			}
+		}
+	}
+}
+
+// changed records a change notification and reports whether it left a file
+// waiting to be minified
+func (w *Watcher) changed(event fsnotify.Event) bool {
+	path := event.Name
+	if w.isOutput(path) {
+		return false
+	}
+	if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+		delete(w.pending, path)
+		delete(w.written, path)
+		return false
+	}
+	if !event.Has(fsnotify.Create) && !event.Has(fsnotify.Write) {
+		return false
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	if info.IsDir() {
+		if !event.Has(fsnotify.Create) || !w.inTree(path) {
+			return false
+		}
+		// Files may have been created before the directory was watched
+		w.watchTree(path, true)
+		return len(w.pending) > 0
+	}
+	if _, ok := w.root(path); !ok || !info.Mode().IsRegular() {
+		return false
+	}
+	if state, ok := w.written[path]; ok {
+		if state == (fileState{modTime: info.ModTime(), size: info.Size()}) {
+			return false
+		}
+		delete(w.written, path)
+	}
+	w.pending[path] = time.Now()
+	return true
+}
+
+// flush minifies the files that have settled. It returns false once the
+// Watcher is closed.
+func (w *Watcher) flush(now time.Time) bool {
+	for path, changed := range w.pending {
+		if now.Sub(changed) < w.opts.Debounce {
+			continue
+		}
+		delete(w.pending, path)
+		root, ok := w.root(path)
+		if !ok {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+
+		event := WatchEvent{Path: path, Output: w.target(root, path)}
+		start := time.Now()
+		event.InputBytes, event.OutputBytes, event.Err = minifyFileJob(w.minifier, fileJob{path: path, target: event.Output}, true)
+		event.Duration = time.Since(start)
+		if event.Err == nil && event.Output == path {
+			// Remember the rewrite, so that its notifications are ignored
+			if info, err := os.Stat(path); err == nil {
+				w.written[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+			}
+		}
+		if !w.send(event) {
+			return false
+		}
+	}
+	return true
+}
+
+// nextSettle returns how long until the next pending file settles
+func (w *Watcher) nextSettle(now time.Time) (time.Duration, bool) {
+	var wait time.Duration
+	found := false
+	for _, changed := range w.pending {
+		if d := changed.Add(w.opts.Debounce).Sub(now); !found || d < wait {
+			wait, found = d, true
+		}
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	return wait, found
+}
+
+// send delivers event, returning false if the Watcher is closed first
+func (w *Watcher) send(event WatchEvent) bool {
+	select {
+	case w.events <- event:
+		return true
+	case <-w.done:
+		return false
+	}
+}
+
+// watchTree watches dir and the directories below it, except the output
+// directory. If pending is set, the files found are taken as changed.
+func (w *Watcher) watchTree(dir string, pending bool) error {
+	now := time.Now()
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if path != dir && w.isOutput(path) {
+				return filepath.SkipDir
+			}
+			return w.notify.Add(path)
+		}
+		if pending && d.Type().IsRegular() && matchAny(w.patterns, d.Name()) {
+			w.pending[path] = now
+		}
+		return nil
+	})
+}
+
+// root returns the watched path that path is minified for: path itself if it
+// is a watched file, or the watched directory holding it if its name matches
+// the patterns
+func (w *Watcher) root(path string) (string, bool) {
+	for _, file := range w.files {
+		if filepath.Clean(file) == filepath.Clean(path) {
+			return file, true
+		}
+	}
+	if !matchAny(w.patterns, filepath.Base(path)) {
+		return "", false
+	}
+	for _, dir := range w.dirs {
+		if within(dir, path) {
+			return dir, true
+		}
+	}
+	return "", false
+}
+
+// inTree reports whether path is inside a watched directory
+func (w *Watcher) inTree(path string) bool {
+	for _, dir := range w.dirs {
+		if within(dir, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// isOutput reports whether path is the output directory or inside it
+func (w *Watcher) isOutput(path string) bool {
+	if w.outputDir == "" {
+		return false
+	}
+	abs, err := filepath.Abs(path)
+	return err == nil && (abs == w.outputDir || within(w.outputDir, abs))
+}
+
+// within reports whether path is strictly inside dir
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// target returns the output file for path, found under root
+func (w *Watcher) target(root, path string) string {
+	if w.opts.OutputDir == "" {
+		return path
+	}
+	if path == root {
+		return filepath.Join(w.opts.OutputDir, filepath.Base(path))
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return filepath.Join(w.opts.OutputDir, filepath.Base(path))
+	}
+	return filepath.Join(w.opts.OutputDir, rel)
+}
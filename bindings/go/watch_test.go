@@ -0,0 +1,106 @@
+package zmin
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// nextEvent waits for the next event from w
+func nextEvent(t *testing.T, w *Watcher) WatchEvent {
+	t.Helper()
+	select {
+	case event := <-w.Events:
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for a watch event")
+		return WatchEvent{}
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "src")
+	out := filepath.Join(root, "out")
+	writeTree(t, src, map[string]string{"sub/a.json": `{ "a" : 1 }`, "notes.txt": "x"})
+
+	w, err := NewWatcher(WatchOptions{
+		OutputDir: out,
+		Debounce:  20 * time.Millisecond,
+	}, src)
+	if err != nil {
+		t.Fatalf("NewWatcher failed: %v", err)
+	}
+	defer w.Close()
+
+	writeTree(t, src, map[string]string{"sub/b.json": `[ 1, 2 ]`})
+	event := nextEvent(t, w)
+	if event.Err != nil {
+		t.Fatalf("Unexpected error: %v", event.Err)
+	}
+	if event.Path != filepath.Join(src, "sub", "b.json") || event.Output != filepath.Join(out, "sub", "b.json") {
+		t.Errorf("Unexpected event %+v", event)
+	}
+	if event.InputBytes != 8 || event.OutputBytes != 5 {
+		t.Errorf("Expected sizes 8 -> 5, got %d -> %d", event.InputBytes, event.OutputBytes)
+	}
+	if got := readFile(t, out, "sub/b.json"); got != `[1,2]` {
+		t.Errorf("Expected %q, got %q", `[1,2]`, got)
+	}
+
+	// Existing files are picked up once they change
+	if err := os.WriteFile(filepath.Join(src, "sub", "a.json"), []byte(`{"a": }`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if event := nextEvent(t, w); !errors.Is(event.Err, ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON, got %+v", event)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if _, ok := <-w.Events; ok {
+		t.Errorf("Expected Events to be closed")
+	}
+}
+
+func TestWatcherInPlace(t *testing.T) {
+	root := t.TempDir()
+	w, err := NewWatcher(WatchOptions{Debounce: 20 * time.Millisecond}, root)
+	if err != nil {
+		t.Fatalf("NewWatcher failed: %v", err)
+	}
+	defer w.Close()
+
+	path := filepath.Join(root, "a.json")
+	if err := os.WriteFile(path, []byte(`{ "a" : [ 1, 2 ] }`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if event := nextEvent(t, w); event.Err != nil || event.Path != path || event.Output != path {
+		t.Fatalf("Unexpected event %+v", event)
+	}
+	if got := readFile(t, root, "a.json"); got != `{"a":[1,2]}` {
+		t.Errorf("Expected %q, got %q", `{"a":[1,2]}`, got)
+	}
+
+	// The Watcher's own rewrite is not taken for a change
+	select {
+	case event := <-w.Events:
+		t.Errorf("Unexpected event for the Watcher's own write: %+v", event)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// Files in directories created later are picked up
+	writeTree(t, root, map[string]string{"sub/b.json": `[ 3 ]`})
+	if event := nextEvent(t, w); event.Err != nil || event.Path != filepath.Join(root, "sub", "b.json") {
+		t.Errorf("Unexpected event %+v", event)
+	}
+}
+
+func TestWatcherMissingPath(t *testing.T) {
+	if _, err := NewWatcher(WatchOptions{}, filepath.Join(t.TempDir(), "missing")); !os.IsNotExist(err) {
+		t.Errorf("Expected a not-exist error, got %v", err)
+	}
+}
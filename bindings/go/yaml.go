@@ -0,0 +1,183 @@
+package zmin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MinifyYAML parses a YAML document and returns it as minified JSON. Mapping
+// keys keep their order, anchors and aliases are expanded, and merge keys
+// ("<<") are applied. Scalars are typed by the YAML 1.2 core schema;
+// timestamps and binary values become strings. Non-scalar mapping keys and
+// non-finite floats cannot be represented in JSON and return an error, as
+// does input holding more than one document.
+func MinifyYAML(input []byte) ([]byte, error) {
+	data, err := convertYAML(input)
+	if err != nil {
+		return nil, err
+	}
+	return MinifyBytes(data, SPORT)
+}
+
+// yamlMaxAliasDepth bounds alias expansion, so that documents nesting aliases
+// to blow up exponentially fail instead of exhausting memory
+const yamlMaxAliasDepth = 64
+
+// convertYAML converts a YAML document to compact JSON
+func convertYAML(input []byte) ([]byte, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(input))
+	var doc yaml.Node
+	if err := decoder.Decode(&doc); err == io.EOF {
+		return []byte("null"), nil // empty document
+	} else if err != nil {
+		return nil, err
+	}
+	// A trailing "---" starts an empty document, which is allowed
+	for {
+		var next yaml.Node
+		if err := decoder.Decode(&next); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if !emptyYAMLDocument(&next) {
+			return nil, fmt.Errorf("line %d: YAML input has more than one document", next.Line)
+		}
+	}
+	return appendYAMLNode(make([]byte, 0, len(input)), &doc, 0)
+}
+
+// emptyYAMLDocument reports whether a document node holds nothing at all
+func emptyYAMLDocument(doc *yaml.Node) bool {
+	if len(doc.Content) == 0 {
+		return true
+	}
+	n := doc.Content[0]
+	return len(doc.Content) == 1 && n.Kind == yaml.ScalarNode && n.ShortTag() == "!!null" && n.Value == ""
+}
+
+// appendYAMLNode appends the JSON form of n to dst
+func appendYAMLNode(dst []byte, n *yaml.Node, aliases int) ([]byte, error) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return append(dst, "null"...), nil
+		}
+		return appendYAMLNode(dst, n.Content[0], aliases)
+	case yaml.AliasNode:
+		if aliases >= yamlMaxAliasDepth {
+			return nil, fmt.Errorf("line %d: YAML aliases nested too deeply", n.Line)
+		}
+		return appendYAMLNode(dst, n.Alias, aliases+1)
+	case yaml.SequenceNode:
+		dst = append(dst, '[')
+		for i, elem := range n.Content {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			var err error
+			if dst, err = appendYAMLNode(dst, elem, aliases); err != nil {
+				return nil, err
+			}
+		}
+		return append(dst, ']'), nil
+	case yaml.MappingNode:
+		keys, values, err := yamlMembers(n, aliases)
+		if err != nil {
+			return nil, err
+		}
+		dst = append(dst, '{')
+		for i, key := range keys {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = appendCanonicalString(dst, key)
+			dst = append(dst, ':')
+			if dst, err = appendYAMLNode(dst, values[key], aliases); err != nil {
+				return nil, err
+			}
+		}
+		return append(dst, '}'), nil
+	case yaml.ScalarNode:
+		return appendYAMLScalar(dst, n)
+	}
+	return nil, fmt.Errorf("line %d: unsupported YAML node", n.Line)
+}
+
+// yamlMembers returns the keys of a mapping in order, with their values.
+// Members pulled in by merge keys take the position of the merge key and
+// are overridden by the mapping's own members, as the YAML merge key
+// specification requires.
+func yamlMembers(n *yaml.Node, aliases int) ([]string, map[string]*yaml.Node, error) {
+	var keys []string
+	values := make(map[string]*yaml.Node)
+	set := func(key string, value *yaml.Node, override bool) {
+		if _, ok := values[key]; !ok {
+			keys = append(keys, key)
+		} else if !override {
+			return
+		}
+		values[key] = value
+	}
+
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := resolveYAMLAlias(n.Content[i]), n.Content[i+1]
+		if key.Kind == yaml.ScalarNode && key.ShortTag() == "!!merge" {
+			sources := []*yaml.Node{resolveYAMLAlias(value)}
+			if sources[0].Kind == yaml.SequenceNode {
+				sources = sources[0].Content
+			}
+			for _, source := range sources {
+				source = resolveYAMLAlias(source)
+				if source.Kind != yaml.MappingNode {
+					return nil, nil, fmt.Errorf("line %d: YAML merge key needs a mapping", source.Line)
+				}
+				mergedKeys, mergedValues, err := yamlMembers(source, aliases+1)
+				if err != nil {
+					return nil, nil, err
+				}
+				for _, k := range mergedKeys {
+					set(k, mergedValues[k], false)
+				}
+			}
+			continue
+		}
+		if key.Kind != yaml.ScalarNode {
+			return nil, nil, fmt.Errorf("line %d: YAML mapping key is not a scalar", key.Line)
+		}
+		set(key.Value, value, true)
+	}
+	return keys, values, nil
+}
+
+// resolveYAMLAlias follows aliases to the node they refer to
+func resolveYAMLAlias(n *yaml.Node) *yaml.Node {
+	for i := 0; n.Kind == yaml.AliasNode && i < yamlMaxAliasDepth; i++ {
+		n = n.Alias
+	}
+	return n
+}
+
+// appendYAMLScalar appends the JSON form of a scalar, typed by its resolved tag
+func appendYAMLScalar(dst []byte, n *yaml.Node) ([]byte, error) {
+	switch n.ShortTag() {
+	case "!!null":
+		return append(dst, "null"...), nil
+	case "!!bool", "!!int", "!!float":
+		var value interface{}
+		if err := n.Decode(&value); err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n.Line, err)
+		}
+		return append(dst, data...), nil
+	default:
+		return appendCanonicalString(dst, n.Value), nil
+	}
+}
@@ -0,0 +1,48 @@
+package zmin
+
+import (
+	"testing"
+)
+
+func TestMinifyYAML(t *testing.T) {
+	input := `
+defaults: &defaults
+  image: app:1.0
+  replicas: 2
+service:
+  <<: *defaults
+  replicas: 3
+  name: "web <api>"
+  ports: [80, 443]
+  ratio: 0.5
+  enabled: yes
+  debug: false
+  empty:
+  created: 2024-01-02
+  tags:
+    - a
+    - b
+`
+	output, err := MinifyYAML([]byte(input))
+	if err != nil {
+		t.Fatalf("MinifyYAML failed: %v", err)
+	}
+	expected := `{"defaults":{"image":"app:1.0","replicas":2},"service":{"image":"app:1.0","replicas":3,` +
+		`"name":"web <api>","ports":[80,443],"ratio":0.5,"enabled":"yes","debug":false,"empty":null,` +
+		`"created":"2024-01-02","tags":["a","b"]}}`
+	if string(output) != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+
+	for _, input := range []string{"a: [1,\n", "? [a]\n: 1\n", "a: .nan\n", "a: 1\n---\nb: 2"} {
+		if _, err := MinifyYAML([]byte(input)); err == nil {
+			t.Errorf("MinifyYAML(%q): expected an error", input)
+		}
+	}
+
+	for input, expected := range map[string]string{"": "null", "---\na: 1\n": `{"a":1}`, "a: 1\n---\n": `{"a":1}`} {
+		if output, err := MinifyYAML([]byte(input)); err != nil || string(output) != expected {
+			t.Errorf("MinifyYAML(%q): got %q (%v), expected %q", input, output, err, expected)
+		}
+	}
+}
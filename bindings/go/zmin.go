@@ -1,39 +1,19 @@
 // Package zmin provides Go bindings for the zmin high-performance JSON minifier.
 package zmin
 
-/*
-#cgo LDFLAGS: -L. -lzmin
-#include <stdlib.h>
-#include <stdint.h>
-
-// Result structure from C API
-typedef struct {
-    char* data;
-    size_t size;
-    int error_code;
-} zmin_result_t;
-
-// Function declarations
-void zmin_init(void);
-zmin_result_t zmin_minify(const char* input, size_t input_size);
-zmin_result_t zmin_minify_mode(const char* input, size_t input_size, int mode);
-int zmin_validate(const char* input, size_t input_size);
-void zmin_free_result(zmin_result_t* result);
-const char* zmin_get_version(void);
-const char* zmin_get_error_message(int error_code);
-*/
-import "C"
 import (
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"sync"
-	"unsafe"
 )
 
-// ProcessingMode represents the JSON processing mode
+// ProcessingMode represents the JSON processing mode. Documents of 2GiB or
+// more held in memory are minified incrementally in Go rather than in a
+// single native call, so the mode is ignored for them, as it is by MinifyLarge.
 type ProcessingMode int
 
 const (
@@ -43,8 +23,33 @@ const (
 	SPORT ProcessingMode = 1
 	// TURBO mode - Maximum performance mode
 	TURBO ProcessingMode = 2
+	// AUTO mode - Picks ECO, SPORT or TURBO for each input from its size and
+	// the memory available, including cgroup limits
+	AUTO ProcessingMode = 3
 )
 
+// modeNames maps each mode to its name
+var modeNames = [...]string{ECO: "eco", SPORT: "sport", TURBO: "turbo", AUTO: "auto"}
+
+// String returns the lower-case name of the mode
+func (m ProcessingMode) String() string {
+	if m >= 0 && int(m) < len(modeNames) {
+		return modeNames[m]
+	}
+	return fmt.Sprintf("ProcessingMode(%d)", int(m))
+}
+
+// ParseMode returns the mode named s, case-insensitively: "eco", "sport",
+// "turbo" or "auto"
+func ParseMode(s string) (ProcessingMode, error) {
+	for mode, name := range modeNames {
+		if strings.EqualFold(s, name) {
+			return ProcessingMode(mode), nil
+		}
+	}
+	return 0, fmt.Errorf("%w %q", ErrInvalidMode, s)
+}
+
 var (
 	// ErrInvalidJSON is returned when the input is not valid JSON
 	ErrInvalidJSON = errors.New("invalid JSON")
@@ -54,20 +59,134 @@ var (
 	ErrInvalidMode = errors.New("invalid mode")
 	// ErrUnknown is returned for unknown errors
 	ErrUnknown = errors.New("unknown error")
+	// ErrBufferTooSmall is returned when an output buffer cannot hold the result
+	ErrBufferTooSmall = errors.New("buffer too small")
+	// ErrInputTooLarge is returned when the input exceeds the configured size limit
+	ErrInputTooLarge = errors.New("input too large")
+	// ErrOutputTooLarge is returned when the output exceeds the configured size limit
+	ErrOutputTooLarge = errors.New("output too large")
+	// ErrTooDeep is returned when the input nests deeper than the configured limit
+	ErrTooDeep = errors.New("maximum nesting depth exceeded")
+	// ErrStringTooLong is returned when a string exceeds the configured length limit
+	ErrStringTooLong = errors.New("string too long")
+	// ErrPointerNotFound is returned when a JSON Pointer refers to no value
+	ErrPointerNotFound = errors.New("JSON pointer not found")
+	// ErrTestFailed is returned when a "test" operation of a JSON Patch fails
+	ErrTestFailed = errors.New("JSON patch test failed")
+	// ErrFieldTooLarge is returned when a value exceeds its Options.MaxFieldBytes limit
+	ErrFieldTooLarge = errors.New("field too large")
+	// ErrClosed is returned when using a Minifier after Close
+	ErrClosed = errors.New("minifier closed")
+	// ErrTimeout is returned when a minification outlasts the limit set with
+	// WithTimeout
+	ErrTimeout = errors.New("minification timed out")
+	// ErrUnsafeInteger is returned when Options.RejectUnsafeIntegers is set and
+	// an integer cannot be represented exactly by a float64
+	ErrUnsafeInteger = errors.New("integer exceeds 2^53")
+	// ErrLibraryUnavailable is returned when the native library cannot be
+	// loaded or fails its self-test
+	ErrLibraryUnavailable = errors.New("zmin library unavailable")
+	// ErrIncompatibleLibrary is returned when the native library is a version
+	// these bindings do not support
+	ErrIncompatibleLibrary = errors.New("incompatible zmin library")
+	// ErrUnsupportedCPU is returned when the CPU lacks instructions the native
+	// library was compiled for
+	ErrUnsupportedCPU = errors.New("unsupported CPU")
 )
 
-var initOnce sync.Once
+// Specific classes of invalid JSON reported by the native library. They all
+// match ErrInvalidJSON with errors.Is.
+var (
+	// ErrUnterminatedString is returned when a string is missing its closing quote
+	ErrUnterminatedString = fmt.Errorf("%w: unterminated string", ErrInvalidJSON)
+	// ErrUnexpectedEOF is returned when the input ends in the middle of a value
+	ErrUnexpectedEOF = fmt.Errorf("%w: unexpected end of input", ErrInvalidJSON)
+	// ErrInvalidEscape is returned for malformed escape sequences in strings
+	ErrInvalidEscape = fmt.Errorf("%w: invalid escape sequence", ErrInvalidJSON)
+	// ErrInvalidNumber is returned for malformed numbers
+	ErrInvalidNumber = fmt.Errorf("%w: invalid number", ErrInvalidJSON)
+	// ErrTrailingComma is returned for a comma before a closing bracket
+	ErrTrailingComma = fmt.Errorf("%w: trailing comma", ErrInvalidJSON)
+	// ErrDuplicateKey is returned when an object contains the same key twice
+	ErrDuplicateKey = fmt.Errorf("%w: duplicate key", ErrInvalidJSON)
+	// ErrUnexpectedCharacter is returned for a character that cannot appear
+	// at its position
+	ErrUnexpectedCharacter = fmt.Errorf("%w: unexpected character", ErrInvalidJSON)
+)
+
+// selfTest is minified by Init to check the native library
+const selfTest, selfTestOutput = `{ "zmin": [1, true, null] }`, `{"zmin":[1,true,null]}`
+
+var (
+	initOnce sync.Once
+	initErr  error
+)
 
-// init initializes the zmin library
-func init() {
+// Init initializes the native library and checks that it can be used: that
+// it is a version these bindings support, that the CPU has the instructions
+// it was compiled for, and that it minifies a sample document correctly.
+// Problems are reported as ErrIncompatibleLibrary, ErrUnsupportedCPU and
+// ErrLibraryUnavailable. Init runs once; later calls return the same result.
+// The EnvISA environment variable restricts the instruction sets used, as
+// InitWithOptions does.
+//
+// Calling Init is optional, since the first call needing the library
+// initializes it and fails with the same error, but calling it at startup
+// reports problems before any input is processed. With cgo, a shared library
+// missing altogether stops the program before it starts, as the library is
+// linked when the program is loaded; built with the zmin_dlopen tag, the
+// library is loaded by Init, which reports it missing as
+// ErrLibraryUnavailable.
+func Init() error {
 	initOnce.Do(func() {
-		C.zmin_init()
+		initErr = initLibrary(initOptionsFromEnv())
 	})
+	return initErr
 }
 
-// Version returns the zmin library version
+// initLibrary initializes and checks the native library
+func initLibrary(opts InitOptions) error {
+	if err := nativeLoad(); err != nil {
+		return err
+	}
+	if err := checkVersion(nativeVersion()); err != nil {
+		return err
+	}
+	if err := setISA(opts); err != nil {
+		return err
+	}
+	if code := nativeInit(); code != 0 {
+		return getError(code)
+	}
+
+	output := make([]byte, len(selfTest))
+	size, code := nativeMinifyInto(output, []byte(selfTest), SPORT)
+	if code != 0 {
+		return fmt.Errorf("%w: self-test failed: %v", ErrLibraryUnavailable, getError(code))
+	}
+	if string(output[:size]) != selfTestOutput {
+		return fmt.Errorf("%w: self-test produced %q", ErrLibraryUnavailable, output[:size])
+	}
+	return nil
+}
+
+// checkVersion reports whether these bindings support the library version,
+// which they do if it has the major version of BindingVersion
+func checkVersion(version string) error {
+	want, _, _ := strings.Cut(BindingVersion, ".")
+	if major, _, _ := strings.Cut(version, "."); major != want {
+		return fmt.Errorf("%w: version %s, need %s.x", ErrIncompatibleLibrary, version, want)
+	}
+	return nil
+}
+
+// Version returns the zmin library version, or "" if the library cannot be
+// loaded
 func Version() string {
-	return C.GoString(C.zmin_get_version())
+	if nativeLoad() != nil {
+		return ""
+	}
+	return nativeVersion()
 }
 
 // Minify minifies JSON data using the default SPORT mode
@@ -75,30 +194,24 @@ func Minify(input interface{}) (string, error) {
 	return MinifyWithMode(input, SPORT)
 }
 
-// MinifyWithMode minifies JSON data using the specified processing mode
+// MinifyWithMode minifies JSON data using the specified processing mode. The
+// mode is ignored for inputs of 2GiB or more; see ProcessingMode.
 func MinifyWithMode(input interface{}, mode ProcessingMode) (string, error) {
-	// Convert input to string
-	jsonStr, err := toJSONString(input)
-	if err != nil {
-		return "", err
-	}
-
-	// Convert to C string
-	cInput := C.CString(jsonStr)
-	defer C.free(unsafe.Pointer(cInput))
-
-	// Call C function
-	result := C.zmin_minify_mode(cInput, C.size_t(len(jsonStr)), C.int(mode))
-	defer C.zmin_free_result(&result)
+	return minifyString(input, mode, defaultBufferPool)
+}
 
-	// Check for errors
-	if result.error_code != 0 {
-		return "", getError(result.error_code)
-	}
+// minifyString minifies input to a string, using pool for intermediate buffers
+func minifyString(input interface{}, mode ProcessingMode, pool BufferPool) (string, error) {
+	return minifyStringWith(input, pool, modeFunc(mode))
+}
 
-	// Convert result to Go string
-	output := C.GoStringN(result.data, C.int(result.size))
-	return output, nil
+// minifyStringWith minifies input to a string with into
+func minifyStringWith(input interface{}, pool BufferPool, into minifyFunc) (string, error) {
+	var output string
+	err := minifyPooled(input, pool, into, func(out []byte) {
+		output = string(out)
+	})
+	return output, err
 }
 
 // Validate checks if the input is valid JSON
@@ -108,41 +221,135 @@ func Validate(input interface{}) bool {
 	if err != nil {
 		return false
 	}
+	return validateBytes([]byte(jsonStr))
+}
 
-	// Convert to C string
-	cInput := C.CString(jsonStr)
-	defer C.free(unsafe.Pointer(cInput))
-
-	// Call C function
-	errorCode := C.zmin_validate(cInput, C.size_t(len(jsonStr)))
-	return errorCode == 0
+// validateBytes checks if input is valid JSON without copying it
+func validateBytes(input []byte) bool {
+	if b := customBackend(); b != nil {
+		return b.Validate(input) == nil
+	}
+	if Init() != nil {
+		return false
+	}
+	return nativeValidate(input) == 0
 }
 
-// MinifyBytes minifies JSON data from bytes
+// MinifyBytes minifies JSON data from bytes. The input is passed to the
+// native library without copying and the output is written directly into
+// the returned slice. Inputs of 2GiB or more are minified in Go instead, and
+// the mode is ignored for them.
 func MinifyBytes(input []byte, mode ProcessingMode) ([]byte, error) {
-	output, err := MinifyWithMode(string(input), mode)
+	// Minification only removes bytes, so the input size always suffices
+	output := make([]byte, len(input))
+	n, err := minifyInto(output, input, mode)
 	if err != nil {
 		return nil, err
 	}
-	return []byte(output), nil
+	return output[:n], nil
+}
+
+// MinifyAppend appends the minified form of src to dst using the default SPORT
+// mode and returns the extended buffer. Reusing dst across calls avoids
+// per-call allocations. On error dst is returned unchanged.
+func MinifyAppend(dst, src []byte) ([]byte, error) {
+	return minifyAppend(dst, src, SPORT)
+}
+
+func minifyAppend(dst, src []byte, mode ProcessingMode) ([]byte, error) {
+	return appendWith(dst, src, modeFunc(mode))
+}
+
+// appendWith appends the output of into for src to dst
+func appendWith(dst, src []byte, into minifyFunc) ([]byte, error) {
+	start := len(dst)
+	if cap(dst)-start < len(src) {
+		grown := make([]byte, start, start+len(src))
+		copy(grown, dst)
+		dst = grown
+	}
+	n, err := into(dst[start:start+len(src)], src)
+	if err != nil {
+		return dst[:start], err
+	}
+	return dst[:start+n], nil
+}
+
+// BufferTooSmallError is returned by MinifyInto when dst cannot hold the
+// minified output. It matches ErrBufferTooSmall with errors.Is.
+type BufferTooSmallError struct {
+	Required int // size of the minified output in bytes
+}
+
+func (e *BufferTooSmallError) Error() string {
+	return fmt.Sprintf("%s: %d bytes required", ErrBufferTooSmall, e.Required)
+}
+
+// Is reports whether target is ErrBufferTooSmall
+func (e *BufferTooSmallError) Is(target error) bool {
+	return target == ErrBufferTooSmall
+}
+
+// MinifyInto minifies src into the caller-provided buffer dst and returns the
+// number of bytes written. If dst is too small, it returns a
+// *BufferTooSmallError holding the required size; a buffer of len(src) bytes
+// is always large enough.
+func MinifyInto(dst, src []byte, mode ProcessingMode) (n int, err error) {
+	n, err = minifyInto(dst, src, mode)
+	if err == ErrBufferTooSmall {
+		return 0, &BufferTooSmallError{Required: n}
+	}
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
 }
 
-// MinifyReader minifies JSON data from an io.Reader
+// MinifyReader minifies JSON data from an io.Reader. Gzip input is detected
+// by its magic bytes and decompressed.
 func MinifyReader(r io.Reader, mode ProcessingMode) (string, error) {
-	data, err := io.ReadAll(r)
+	rc, err := decompressReader(r)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
 	if err != nil {
 		return "", err
 	}
 	return MinifyWithMode(string(data), mode)
 }
 
-// MinifyFile minifies a JSON file
+// MinifyToWriter minifies JSON data from an io.Reader and writes the result
+// to an io.Writer. The output is written straight from the native buffer, so
+// no Go copy of the result is made. It returns the number of bytes written.
+func MinifyToWriter(r io.Reader, w io.Writer, mode ProcessingMode) (int64, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+
+	var written int
+	err = withMinified(data, mode, func(output []byte) error {
+		var err error
+		written, err = w.Write(output)
+		return err
+	})
+	return int64(written), err
+}
+
+// MinifyFile minifies a JSON file. Gzip input is detected by its magic bytes
+// and decompressed, and an outputPath ending in ".gz" is written gzipped, so
+// "data.json.gz" can be minified straight to "data.min.json.gz".
 func MinifyFile(inputPath, outputPath string, mode ProcessingMode) error {
 	// Read input file
 	input, err := os.ReadFile(inputPath)
 	if err != nil {
 		return err
 	}
+	if input, err = decompressBytes(input, 0); err != nil {
+		return err
+	}
 
 	// Minify
 	output, err := MinifyWithMode(string(input), mode)
@@ -151,7 +358,7 @@ func MinifyFile(inputPath, outputPath string, mode ProcessingMode) error {
 	}
 
 	// Write output file
-	return os.WriteFile(outputPath, []byte(output), 0644)
+	return writeOutputFile(outputPath, []byte(output), compressOptions{})
 }
 
 // ValidateFile validates a JSON file
@@ -163,6 +370,85 @@ func ValidateFile(filePath string) bool {
 	return Validate(string(input))
 }
 
+// minifyInto minifies src into dst with the default backend and returns the
+// output length. If dst is too small, the required size is returned along
+// with the error.
+func minifyInto(dst, src []byte, mode ProcessingMode) (int, error) {
+	if b := customBackend(); b != nil {
+		return backendMinifyInto(b, dst, src, mode)
+	}
+	return minifyNative(dst, src, mode)
+}
+
+// minifyNative minifies src into dst with the backend compiled in. Both
+// slices are handed to the native library directly; cgo keeps Go memory
+// pinned for the duration of the call.
+func minifyNative(dst, src []byte, mode ProcessingMode) (int, error) {
+	if len(src) >= largeInputSize {
+		return minifyLarge(dst, src)
+	}
+	if alreadyMinified(src, mode) {
+		if len(dst) < len(src) {
+			return len(src), ErrBufferTooSmall
+		}
+		return copy(dst, src), nil
+	}
+	if err := Init(); err != nil {
+		return 0, err
+	}
+	mode = resolveMode(mode, len(src))
+	size, code := nativeMinifyInto(dst, src, mode)
+	if code != 0 {
+		return size, locateError(src, getError(code))
+	}
+	return size, nil
+}
+
+// minifyFunc minifies src into dst like minifyInto, with the processing mode
+// or native context bound
+type minifyFunc func(dst, src []byte) (int, error)
+
+// modeFunc returns a minifyFunc for the stateless native API
+func modeFunc(mode ProcessingMode) minifyFunc {
+	return func(dst, src []byte) (int, error) {
+		return minifyInto(dst, src, mode)
+	}
+}
+
+// withMinified minifies input and passes the native output buffer to fn, or
+// input itself if it is already minified. The buffer is freed when fn
+// returns, so fn must not retain it.
+func withMinified(input []byte, mode ProcessingMode, fn func(output []byte) error) error {
+	if b := customBackend(); b != nil {
+		output, err := b.Minify(input, mode)
+		if err != nil {
+			return err
+		}
+		return fn(output)
+	}
+	if len(input) >= largeInputSize {
+		output := make([]byte, len(input))
+		n, err := minifyLarge(output, input)
+		if err != nil {
+			return err
+		}
+		return fn(output[:n])
+	}
+	if alreadyMinified(input, mode) {
+		return fn(input)
+	}
+	if err := Init(); err != nil {
+		return err
+	}
+	mode = resolveMode(mode, len(input))
+	output, release, code := nativeMinify(input, mode)
+	if code != 0 {
+		return locateError(input, getError(code))
+	}
+	defer release()
+	return fn(output)
+}
+
 // toJSONString converts various input types to JSON string
 func toJSONString(input interface{}) (string, error) {
 	switch v := input.(type) {
@@ -186,54 +472,111 @@ func toJSONString(input interface{}) (string, error) {
 	}
 }
 
-// getError converts C error code to Go error
-func getError(errorCode C.int) error {
-	switch errorCode {
+// getError converts a native error code to a Go error
+func getError(errorCode int) error {
+	if err := errorForCode(errorCode); err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: %s", ErrUnknown, nativeErrorMessage(errorCode))
+}
+
+// errorForCode returns the sentinel error for a documented C error code, or
+// nil if the code is not documented
+func errorForCode(code int) error {
+	switch code {
 	case -1:
 		return ErrInvalidJSON
 	case -2:
 		return ErrOutOfMemory
 	case -3:
 		return ErrInvalidMode
+	case -4:
+		return ErrBufferTooSmall
+	case -5:
+		return ErrTooDeep
+	case -6:
+		return ErrInputTooLarge
+	case -7:
+		return ErrUnterminatedString
+	case -8:
+		return ErrUnexpectedEOF
+	case -9:
+		return ErrInvalidEscape
+	case -10:
+		return ErrInvalidNumber
+	case -11:
+		return ErrTrailingComma
+	case -12:
+		return ErrDuplicateKey
+	case -13:
+		return ErrUnexpectedCharacter
+	case -14:
+		return ErrUnsupportedCPU
 	default:
-		errMsg := C.GoString(C.zmin_get_error_message(errorCode))
-		return fmt.Errorf("%w: %s", ErrUnknown, errMsg)
+		return nil
 	}
 }
 
-// Minifier provides a reusable minifier instance
-type Minifier struct {
-	mode ProcessingMode
+// UnsafeResult holds minified output in memory owned by the native library.
+// Bytes exposes that memory directly, so large results are never copied onto
+// the Go heap. The result must be released with Close, after which slices
+// returned by Bytes must no longer be used.
+type UnsafeResult struct {
+	output  []byte
+	release func()
+	closed  bool
 }
 
-// NewMinifier creates a new minifier with the specified mode
-func NewMinifier(mode ProcessingMode) *Minifier {
-	return &Minifier{mode: mode}
+// MinifyUnsafe minifies JSON bytes and returns the output without copying it
+// out of native memory. The caller must Close the result.
+func MinifyUnsafe(input []byte, mode ProcessingMode) (*UnsafeResult, error) {
+	if b := customBackend(); b != nil {
+		output, err := b.Minify(input, mode)
+		if err != nil {
+			return nil, err
+		}
+		return &UnsafeResult{output: output, release: func() {}}, nil
+	}
+	if err := Init(); err != nil {
+		return nil, err
+	}
+	mode = resolveMode(mode, len(input))
+	output, release, code := nativeMinify(input, mode)
+	if code != 0 {
+		return nil, locateError(input, getError(code))
+	}
+	return &UnsafeResult{output: output, release: release}, nil
 }
 
-// Minify minifies JSON using the configured mode
-func (m *Minifier) Minify(input interface{}) (string, error) {
-	return MinifyWithMode(input, m.mode)
+// Bytes returns the minified output. The slice refers to native memory and is
+// only valid until Close is called; it must not be appended to.
+func (r *UnsafeResult) Bytes() []byte {
+	if r.closed || len(r.output) == 0 {
+		return nil
+	}
+	return r.output[:len(r.output):len(r.output)]
 }
 
-// MinifyBytes minifies JSON bytes using the configured mode
-func (m *Minifier) MinifyBytes(input []byte) ([]byte, error) {
-	return MinifyBytes(input, m.mode)
+// Len returns the length of the minified output
+func (r *UnsafeResult) Len() int {
+	if r.closed {
+		return 0
+	}
+	return len(r.output)
 }
 
-// MinifyReader minifies JSON from reader using the configured mode
-func (m *Minifier) MinifyReader(r io.Reader) (string, error) {
-	return MinifyReader(r, m.mode)
+// String returns a Go copy of the minified output
+func (r *UnsafeResult) String() string {
+	return string(r.Bytes())
 }
 
-// MinifyFile minifies a file using the configured mode
-func (m *Minifier) MinifyFile(inputPath, outputPath string) error {
-	return MinifyFile(inputPath, outputPath, m.mode)
+// Close frees the native memory holding the output. It is safe to call Close
+// more than once.
+func (r *UnsafeResult) Close() error {
+	if !r.closed {
+		r.closed = true
+		r.output = nil
+		r.release()
+	}
+	return nil
 }
-
-// Default minifiers for each mode
-var (
-	EcoMinifier   = NewMinifier(ECO)
-	SportMinifier = NewMinifier(SPORT)
-	TurboMinifier = NewMinifier(TURBO)
-)
\ No newline at end of file
@@ -0,0 +1,110 @@
+//go:build !cgo && !zmin_dlopen && !zmin_wasm && !zmin_embed
+
+package zmin
+
+// Without cgo, and unless another backend is selected with the zmin_dlopen or
+// zmin_wasm build tag, the package minifies in pure Go. Output is identical,
+// but throughput is lower and the processing mode has no effect.
+
+import (
+	"strings"
+	"unsafe"
+)
+
+// backendName identifies the backend compiled in
+const backendName = "go"
+
+// nativeLoad has nothing to load
+func nativeLoad() error {
+	return nil
+}
+
+func nativeInit() int {
+	return 0
+}
+
+// nativeVersion returns the version of the bindings, which implement the
+// library themselves
+func nativeVersion() string {
+	return BindingVersion
+}
+
+func nativeErrorMessage(code int) string {
+	return "Unknown error"
+}
+
+// nativeMinifyInto minifies src into dst and returns the output size and
+// error code, as zmin_minify_into does
+func nativeMinifyInto(dst, src []byte, mode ProcessingMode) (int, int) {
+	if mode < ECO || mode > TURBO {
+		return 0, -3
+	}
+	return goMinifyInto(dst, src)
+}
+
+// nativeMinify minifies src into a new buffer
+func nativeMinify(src []byte, mode ProcessingMode) (output []byte, release func(), code int) {
+	output = make([]byte, len(src))
+	size, code := nativeMinifyInto(output, src, mode)
+	if code != 0 {
+		return nil, nil, code
+	}
+	return output[:size], func() {}, 0
+}
+
+// nativeMinifyBatch minifies the documents stored back to back in src, as
+// zmin_minify_batch does
+func nativeMinifyBatch(src []byte, sizes []uintptr, mode ProcessingMode, dst []byte, outputSizes []uintptr, codes []int32) int {
+	if mode < ECO || mode > TURBO {
+		return -3
+	}
+	offset := 0
+	for i, size := range sizes {
+		end := offset + int(size)
+		n, code := nativeMinifyInto(dst[offset:end], src[offset:end], mode)
+		outputSizes[i], codes[i] = uintptr(n), int32(code)
+		offset = end
+	}
+	return 0
+}
+
+func nativeValidate(src []byte) int {
+	if scanDocument(src) != nil {
+		return -1
+	}
+	return 0
+}
+
+// nativeCreateMinifier returns nil: there are no minifier contexts, so
+// Minifiers use the stateless functions
+func nativeCreateMinifier(mode ProcessingMode, threads int) unsafe.Pointer {
+	return nil
+}
+
+func nativeDestroyMinifier(handle unsafe.Pointer) {}
+
+func nativeMinifierMinifyInto(handle unsafe.Pointer, dst, src []byte) (int, int) {
+	return 0, -99
+}
+
+func nativeModeSupported(mode ProcessingMode) bool {
+	return true
+}
+
+// nativeSIMDFeatures returns no features, beyond those the Go runtime uses
+// to search strings
+func nativeSIMDFeatures() uint {
+	return 0
+}
+
+func nativeMaxInputSize() uint64 {
+	return ^uint64(0)
+}
+
+// nativeSetSIMDLevel accepts only scalar code
+func nativeSetSIMDLevel(name string) int {
+	if strings.EqualFold(name, "scalar") {
+		return 0
+	}
+	return -14
+}
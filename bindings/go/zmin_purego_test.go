@@ -0,0 +1,27 @@
+//go:build !cgo && !zmin_dlopen && !zmin_wasm && !zmin_embed
+
+package zmin
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPureGoFallback(t *testing.T) {
+	if _, err := Minify(`{"a": }`); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON, got %v", err)
+	}
+
+	// Output that does not fit is sized like the native library does
+	src := []byte(`[ 1, 2, 3 ]`)
+	dst := make([]byte, 3)
+	if n, code := nativeMinifyInto(dst, src, SPORT); n != 7 || code != -4 {
+		t.Errorf("Expected size 7 and code -4, got %d and %d", n, code)
+	}
+	if err := InitWithOptions(InitOptions{ForceISA: "AVX2"}); !errors.Is(err, ErrUnsupportedCPU) {
+		t.Errorf("Expected ErrUnsupportedCPU, got %v", err)
+	}
+	if err := InitWithOptions(InitOptions{DisableSIMD: true}); err != nil {
+		t.Errorf("InitWithOptions failed: %v", err)
+	}
+}
@@ -1,6 +1,9 @@
 package zmin
 
 import (
+	"bytes"
+	"errors"
+	"strings"
 	"testing"
 )
 
@@ -66,6 +69,21 @@ func TestMinifyBytes(t *testing.T) {
 	}
 }
 
+func TestParseMode(t *testing.T) {
+	for _, mode := range []ProcessingMode{ECO, SPORT, TURBO, AUTO} {
+		parsed, err := ParseMode(strings.ToUpper(mode.String()))
+		if err != nil || parsed != mode {
+			t.Errorf("ParseMode(%q): expected %d, got %d, %v", mode, mode, parsed, err)
+		}
+	}
+	if _, err := ParseMode("fast"); !errors.Is(err, ErrInvalidMode) {
+		t.Errorf("Expected ErrInvalidMode, got %v", err)
+	}
+	if got := ProcessingMode(7).String(); got != "ProcessingMode(7)" {
+		t.Errorf("Expected %q, got %q", "ProcessingMode(7)", got)
+	}
+}
+
 func TestVersion(t *testing.T) {
 	version := Version()
 	if version == "" {
@@ -73,8 +91,26 @@ func TestVersion(t *testing.T) {
 	}
 }
 
+func TestInit(t *testing.T) {
+	if err := Init(); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	if err := Init(); err != nil {
+		t.Errorf("Expected second Init to succeed, got %v", err)
+	}
+
+	if err := checkVersion("1.4.2"); err != nil {
+		t.Errorf("Expected version 1.4.2 to be supported, got %v", err)
+	}
+	for _, version := range []string{"2.0.0", "0.9.1", ""} {
+		if err := checkVersion(version); !errors.Is(err, ErrIncompatibleLibrary) {
+			t.Errorf("Version %q: expected ErrIncompatibleLibrary, got %v", version, err)
+		}
+	}
+}
+
 func TestMinifier(t *testing.T) {
-	minifier := NewMinifier(TURBO)
+	minifier := NewMinifier(WithMode(TURBO))
 	input := `{"test": true}`
 
 	output, err := minifier.Minify(input)
@@ -146,4 +182,188 @@ func BenchmarkMinifyWithMode(b *testing.B) {
 			}
 		}
 	})
-} 
\ No newline at end of file
+}
+
+func TestMinifyToWriter(t *testing.T) {
+	input := `{ "key": "value", "array": [1, 2, 3] }`
+	var buf bytes.Buffer
+
+	n, err := MinifyToWriter(strings.NewReader(input), &buf, SPORT)
+	if err != nil {
+		t.Fatalf("MinifyToWriter failed: %v", err)
+	}
+
+	expected := `{"key":"value","array":[1,2,3]}`
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+	if n != int64(len(expected)) {
+		t.Errorf("Expected %d bytes written, got %d", len(expected), n)
+	}
+
+	if _, err := MinifyToWriter(strings.NewReader(`{"key":}`), &buf, SPORT); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON, got %v", err)
+	}
+}
+
+func TestMinifyBytesZeroCopy(t *testing.T) {
+	input := []byte(`{ "key": "value", "nested": { "array": [ 1, 2, 3 ] } }`)
+	saved := append([]byte(nil), input...)
+
+	output, err := MinifyBytes(input, TURBO)
+	if err != nil {
+		t.Fatalf("MinifyBytes failed: %v", err)
+	}
+	if string(output) != `{"key":"value","nested":{"array":[1,2,3]}}` {
+		t.Errorf("Unexpected output %q", output)
+	}
+	if !bytes.Equal(input, saved) {
+		t.Error("MinifyBytes modified its input")
+	}
+
+	if _, err := MinifyBytes(nil, SPORT); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON for empty input, got %v", err)
+	}
+}
+
+func TestMinifyAppend(t *testing.T) {
+	buf := []byte("prefix:")
+	buf, err := MinifyAppend(buf, []byte(`{ "a": 1 }`))
+	if err != nil {
+		t.Fatalf("MinifyAppend failed: %v", err)
+	}
+	buf, err = NewMinifier(WithMode(ECO)).MinifyAppend(buf, []byte(`[ true ]`))
+	if err != nil {
+		t.Fatalf("Minifier.MinifyAppend failed: %v", err)
+	}
+	if string(buf) != `prefix:{"a":1}[true]` {
+		t.Errorf("Unexpected output %q", buf)
+	}
+
+	// Errors leave the buffer unchanged
+	out, err := MinifyAppend(buf, []byte(`{`))
+	if !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON, got %v", err)
+	}
+	if string(out) != string(buf) {
+		t.Errorf("Expected buffer to be unchanged, got %q", out)
+	}
+
+	// A buffer with enough capacity is reused
+	reused := make([]byte, 0, 64)
+	out, _ = MinifyAppend(reused, []byte(`{ "b": 2 }`))
+	if &out[0] != &reused[:1][0] {
+		t.Error("Expected MinifyAppend to reuse the buffer")
+	}
+}
+
+func BenchmarkMinifyAppend(b *testing.B) {
+	input := []byte(`{"key": "value", "array": [1, 2, 3, 4, 5]}`)
+	buf := make([]byte, 0, len(input))
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var err error
+		buf, err = MinifyAppend(buf[:0], input)
+		if err != nil {
+			b.Fatalf("MinifyAppend failed: %v", err)
+		}
+	}
+}
+
+func TestMinifyInto(t *testing.T) {
+	src := []byte(`{ "key": "value" }`)
+	dst := make([]byte, 64)
+
+	n, err := MinifyInto(dst, src, SPORT)
+	if err != nil {
+		t.Fatalf("MinifyInto failed: %v", err)
+	}
+	if string(dst[:n]) != `{"key":"value"}` {
+		t.Errorf("Unexpected output %q", dst[:n])
+	}
+
+	_, err = MinifyInto(make([]byte, 4), src, SPORT)
+	if !errors.Is(err, ErrBufferTooSmall) {
+		t.Fatalf("Expected ErrBufferTooSmall, got %v", err)
+	}
+	var tooSmall *BufferTooSmallError
+	if !errors.As(err, &tooSmall) || tooSmall.Required != n {
+		t.Errorf("Expected required size %d, got %v", n, err)
+	}
+}
+
+func TestMinifyUnsafe(t *testing.T) {
+	result, err := MinifyUnsafe([]byte(`{ "key": [1, 2] }`), SPORT)
+	if err != nil {
+		t.Fatalf("MinifyUnsafe failed: %v", err)
+	}
+	if string(result.Bytes()) != `{"key":[1,2]}` || result.Len() != 13 {
+		t.Errorf("Unexpected output %q", result.Bytes())
+	}
+	if err := result.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	if result.Bytes() != nil || result.Len() != 0 {
+		t.Error("Expected no output after Close")
+	}
+	result.Close() // closing twice is safe
+
+	if _, err := MinifyUnsafe([]byte(`{`), SPORT); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON, got %v", err)
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	syntax := []error{
+		ErrUnterminatedString, ErrUnexpectedEOF, ErrInvalidEscape, ErrInvalidNumber,
+		ErrTrailingComma, ErrDuplicateKey, ErrUnexpectedCharacter,
+	}
+	for _, err := range syntax {
+		if !errors.Is(err, ErrInvalidJSON) {
+			t.Errorf("Expected %v to match ErrInvalidJSON", err)
+		}
+	}
+
+	codes := map[int]error{
+		-1: ErrInvalidJSON, -2: ErrOutOfMemory, -3: ErrInvalidMode, -4: ErrBufferTooSmall,
+		-5: ErrTooDeep, -6: ErrInputTooLarge, -7: ErrUnterminatedString, -8: ErrUnexpectedEOF,
+		-9: ErrInvalidEscape, -10: ErrInvalidNumber, -11: ErrTrailingComma, -12: ErrDuplicateKey,
+		-13: ErrUnexpectedCharacter, -14: ErrUnsupportedCPU,
+	}
+	for code, expected := range codes {
+		if err := errorForCode(code); err != expected {
+			t.Errorf("Code %d: expected %v, got %v", code, expected, err)
+		}
+	}
+	if err := errorForCode(-99); err != nil {
+		t.Errorf("Expected undocumented code to map to nil, got %v", err)
+	}
+}
+
+func TestMinifyErrorSnippet(t *testing.T) {
+	input := "{\n  \"name\": \"John\",\n  \"age\": 30,\n}"
+	_, err := Minify(input)
+	if !errors.Is(err, ErrInvalidJSON) {
+		t.Fatalf("Expected ErrInvalidJSON, got %v", err)
+	}
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("Expected *SyntaxError, got %T", err)
+	}
+	if syntaxErr.Offset != 33 || syntaxErr.Line != 4 {
+		t.Errorf("Unexpected position: offset %d, line %d", syntaxErr.Offset, syntaxErr.Line)
+	}
+	expected := `...": "John",   "age": 30, >>}<<`
+	if syntaxErr.Snippet != expected {
+		t.Errorf("Expected snippet %q, got %q", expected, syntaxErr.Snippet)
+	}
+	if !strings.Contains(err.Error(), "offset 33") || !strings.Contains(err.Error(), ">>}<<") {
+		t.Errorf("Expected message to include offset and snippet, got %q", err.Error())
+	}
+
+	// Errors that are not about syntax are not wrapped
+	if _, err := MinifyWithMode(`{}`, ProcessingMode(9)); err != ErrInvalidMode {
+		t.Errorf("Expected ErrInvalidMode, got %v", err)
+	}
+}
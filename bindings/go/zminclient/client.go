@@ -0,0 +1,78 @@
+package zminclient
+
+import (
+	"bufio"
+	"net"
+	"sync"
+
+	zmin "github.com/hydepwns/zmin/go"
+)
+
+// Client is a connection to a zmind socket. It is safe for concurrent use,
+// but requests on one Client are sent one at a time; open several Clients to
+// run requests in parallel.
+type Client struct {
+	mu   sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+	w    *bufio.Writer
+}
+
+// Dial connects to the zmind socket at path
+func Dial(path string) (*Client, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn, r: bufio.NewReader(conn), w: bufio.NewWriter(conn)}, nil
+}
+
+// Close closes the connection
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Minify returns input minified by the server in mode
+func (c *Client) Minify(input []byte, mode zmin.ProcessingMode) ([]byte, error) {
+	if mode < 0 || mode >= defaultMode {
+		return nil, zmin.ErrInvalidMode
+	}
+	return c.do(opMinify, byte(mode), input)
+}
+
+// MinifyDefault returns input minified by the server in its default mode
+func (c *Client) MinifyDefault(input []byte) ([]byte, error) {
+	return c.do(opMinify, defaultMode, input)
+}
+
+// Validate returns nil if input is valid JSON, or the server's error
+func (c *Client) Validate(input []byte) error {
+	_, err := c.do(opValidate, defaultMode, input)
+	return err
+}
+
+// Format returns input indented by the server with two spaces
+func (c *Client) Format(input []byte) ([]byte, error) {
+	return c.do(opFormat, defaultMode, input)
+}
+
+// do sends a request and reads its response
+func (c *Client) do(op, mode byte, payload []byte) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := writeFrame(c.w, []byte{op, mode, 0, 0, 0, 0}, payload); err != nil {
+		return nil, err
+	}
+	if err := c.w.Flush(); err != nil {
+		return nil, err
+	}
+	header := make([]byte, 5)
+	output, err := readFrame(c.r, header, 0)
+	if err != nil {
+		return nil, err
+	}
+	if header[0] != statusOK {
+		return nil, errorOf(header[0], string(output))
+	}
+	return output, nil
+}
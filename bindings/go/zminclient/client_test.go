@@ -0,0 +1,93 @@
+package zminclient
+
+import (
+	"errors"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	zmin "github.com/hydepwns/zmin/go"
+)
+
+// serve starts a Server on a socket in a temporary directory and returns a
+// Client connected to it
+func serve(t *testing.T, s *Server) *Client {
+	path := filepath.Join(t.TempDir(), "zmind.sock")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- s.Serve(l) }()
+	c, err := Dial(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		c.Close()
+		l.Close()
+		if err := <-done; err != nil {
+			t.Errorf("Serve: %v", err)
+		}
+	})
+	return c
+}
+
+func TestClient(t *testing.T) {
+	c := serve(t, &Server{Mode: zmin.SPORT, MaxSize: 100})
+
+	output, err := c.Minify([]byte(`{ "a": [1, 2] }`), zmin.TURBO)
+	if err != nil || string(output) != `{"a":[1,2]}` {
+		t.Errorf("Expected %q, got %q, %v", `{"a":[1,2]}`, output, err)
+	}
+	output, err = c.MinifyDefault([]byte(` [ ] `))
+	if err != nil || string(output) != `[]` {
+		t.Errorf("Expected %q, got %q, %v", `[]`, output, err)
+	}
+	output, err = c.Format([]byte(`{"a":1}`))
+	if err != nil || string(output) != "{\n  \"a\": 1\n}" {
+		t.Errorf("Expected an indented document, got %q, %v", output, err)
+	}
+	if err := c.Validate([]byte(`[1]`)); err != nil {
+		t.Errorf("Expected a valid document, got %v", err)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	c := serve(t, &Server{Mode: zmin.SPORT, MaxSize: 100})
+
+	var remote *Error
+	if err := c.Validate([]byte(`[1,]`)); !errors.Is(err, zmin.ErrInvalidJSON) || !errors.As(err, &remote) {
+		t.Errorf("Expected a remote ErrInvalidJSON, got %v", err)
+	}
+	if _, err := c.MinifyDefault([]byte(strings.Repeat(" ", 200) + "1")); !errors.Is(err, zmin.ErrInputTooLarge) {
+		t.Errorf("Expected ErrInputTooLarge, got %v", err)
+	}
+	if _, err := c.Minify([]byte(`1`), zmin.ProcessingMode(7)); !errors.Is(err, zmin.ErrInvalidMode) {
+		t.Errorf("Expected ErrInvalidMode, got %v", err)
+	}
+	// The connection survives errors
+	if output, err := c.MinifyDefault([]byte(` 1 `)); err != nil || string(output) != "1" {
+		t.Errorf("Expected %q, got %q, %v", "1", output, err)
+	}
+}
+
+func TestClientConcurrent(t *testing.T) {
+	c := serve(t, &Server{Mode: zmin.SPORT})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if output, err := c.MinifyDefault([]byte(`{ "n": 1 }`)); err != nil || string(output) != `{"n":1}` {
+					t.Errorf("Expected %q, got %q, %v", `{"n":1}`, output, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
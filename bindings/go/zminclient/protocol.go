@@ -0,0 +1,119 @@
+// Package zminclient talks to a zmind sidecar over a Unix domain socket, with
+// a minimal length-prefixed protocol that avoids the overhead of HTTP for
+// high-QPS internal minification. The package also provides the server end,
+// which zmind runs with -socket, so that both sides share one implementation.
+//
+// A request is a header of one operation byte, one mode byte and a 4-byte
+// big-endian payload length, followed by the payload, the document. A
+// response is one status byte and a 4-byte big-endian length, followed by the
+// output on success or an error message otherwise. Any number of requests are
+// sent on a connection, each answered before the next is read.
+package zminclient
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	zmin "github.com/hydepwns/zmin/go"
+)
+
+// Operations
+const (
+	opMinify   = 1
+	opValidate = 2
+	opFormat   = 3
+)
+
+// defaultMode in a request selects the server's default mode
+const defaultMode = 0xff
+
+// Response statuses
+const (
+	statusOK          = 0
+	statusInvalidJSON = 1
+	statusTooLarge    = 2
+	statusInvalidMode = 3
+	statusError       = 4
+)
+
+// errFrameTooLarge is returned when a frame exceeds the maximum size
+var errFrameTooLarge = errors.New("frame too large")
+
+// Error is an error reported by the server. It wraps the matching zmin error,
+// such as zmin.ErrInvalidJSON, when there is one.
+type Error struct {
+	Message string
+	err     error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the zmin error reported by the server, if any
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// statusOf returns the status reporting err
+func statusOf(err error) byte {
+	switch {
+	case errors.Is(err, zmin.ErrInvalidJSON):
+		return statusInvalidJSON
+	case errors.Is(err, zmin.ErrInputTooLarge):
+		return statusTooLarge
+	case errors.Is(err, zmin.ErrInvalidMode):
+		return statusInvalidMode
+	default:
+		return statusError
+	}
+}
+
+// errorOf returns the error a status other than statusOK reports
+func errorOf(status byte, message string) error {
+	e := &Error{Message: message}
+	switch status {
+	case statusInvalidJSON:
+		e.err = zmin.ErrInvalidJSON
+	case statusTooLarge:
+		e.err = zmin.ErrInputTooLarge
+	case statusInvalidMode:
+		e.err = zmin.ErrInvalidMode
+	}
+	return e
+}
+
+// writeFrame writes a header followed by payload
+func writeFrame(w io.Writer, header []byte, payload []byte) error {
+	binary.BigEndian.PutUint32(header[len(header)-4:], uint32(len(payload)))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+// readFrame reads a header into header and returns the payload that follows,
+// which must not exceed max bytes. An oversized payload is skipped, so that
+// the connection stays usable, and reported as errFrameTooLarge.
+func readFrame(r io.Reader, header []byte, max int) ([]byte, error) {
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(header[len(header)-4:])
+	if max > 0 && int64(n) > int64(max) {
+		if _, err := io.CopyN(io.Discard, r, int64(n)); err != nil {
+			return nil, err
+		}
+		return nil, errFrameTooLarge
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return payload, nil
+}
@@ -0,0 +1,94 @@
+package zminclient
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	zmin "github.com/hydepwns/zmin/go"
+)
+
+// Server answers zminclient requests
+type Server struct {
+	// Mode is used for requests that do not select one
+	Mode zmin.ProcessingMode
+
+	// MaxSize rejects documents larger than this many bytes. Zero means no
+	// limit.
+	MaxSize int
+
+	once      sync.Once
+	minifiers map[zmin.ProcessingMode]*zmin.Minifier
+}
+
+// Serve accepts connections on l and serves each on its own goroutine until
+// l is closed. It returns nil once l has been closed.
+func (s *Server) Serve(l net.Listener) error {
+	s.once.Do(func() {
+		s.minifiers = make(map[zmin.ProcessingMode]*zmin.Minifier)
+		for _, mode := range []zmin.ProcessingMode{zmin.ECO, zmin.SPORT, zmin.TURBO, zmin.AUTO} {
+			s.minifiers[mode] = zmin.NewMinifier(zmin.WithMode(mode))
+		}
+	})
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go s.serveConn(conn)
+	}
+}
+
+// serveConn answers the requests on conn until it is closed
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	header := make([]byte, 6)
+	for {
+		var output []byte
+		payload, err := readFrame(r, header, s.MaxSize)
+		switch err {
+		case nil:
+			output, err = s.handle(header[0], header[1], payload)
+		case errFrameTooLarge:
+			err = zmin.ErrInputTooLarge
+		default:
+			return
+		}
+		status := byte(statusOK)
+		if err != nil {
+			status, output = statusOf(err), []byte(err.Error())
+		}
+		if writeFrame(w, []byte{status, 0, 0, 0, 0}, output) != nil || w.Flush() != nil {
+			return
+		}
+	}
+}
+
+// handle performs a request
+func (s *Server) handle(op, mode byte, payload []byte) ([]byte, error) {
+	switch op {
+	case opMinify:
+		m, ok := s.minifiers[zmin.ProcessingMode(mode)]
+		if mode == defaultMode {
+			m, ok = s.minifiers[s.Mode], true
+		}
+		if !ok || m == nil {
+			return nil, fmt.Errorf("%w %d", zmin.ErrInvalidMode, mode)
+		}
+		return m.MinifyBytes(payload)
+	case opValidate:
+		return nil, zmin.ValidateWithError(payload)
+	case opFormat:
+		output, err := zmin.Format(payload, zmin.FormatOptions{})
+		return []byte(output), err
+	default:
+		return nil, fmt.Errorf("unknown operation %d", op)
+	}
+}
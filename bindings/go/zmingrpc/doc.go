@@ -0,0 +1,7 @@
+// Package zmingrpc provides gRPC server interceptors that minify the JSON
+// carried in responses, for grpc-gateway deployments serving large JSON
+// payloads.
+//
+// The interceptors are a module of their own, so that only the programs using
+// them depend on gRPC.
+package zmingrpc
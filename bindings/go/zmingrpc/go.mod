@@ -0,0 +1,25 @@
+module github.com/hydepwns/zmin/go/zmingrpc
+
+go 1.21
+
+require (
+	github.com/hydepwns/zmin/go v0.0.0-00010101000000-000000000000
+	google.golang.org/genproto/googleapis/api v0.0.0-20240604185151-ef581f913117
+	google.golang.org/grpc v1.66.0
+	google.golang.org/protobuf v1.34.2
+)
+
+require (
+	github.com/BurntSushi/toml v1.4.0 // indirect
+	github.com/ebitengine/purego v0.8.1 // indirect
+	github.com/fsnotify/fsnotify v1.7.0 // indirect
+	github.com/klauspost/compress v1.17.9 // indirect
+	github.com/tetratelabs/wazero v1.8.1 // indirect
+	golang.org/x/net v0.26.0 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+	golang.org/x/text v0.16.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240604185151-ef581f913117 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/hydepwns/zmin/go => ..
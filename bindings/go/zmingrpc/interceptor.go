@@ -0,0 +1,178 @@
+package zmingrpc
+
+import (
+	"context"
+	"mime"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	zmin "github.com/hydepwns/zmin/go"
+)
+
+// httpBodyName is the full name of google.api.HttpBody
+const httpBodyName protoreflect.FullName = "google.api.HttpBody"
+
+// Option configures the interceptors
+type Option func(*config)
+
+type config struct {
+	fields   map[protoreflect.FullName]bool
+	minifier []zmin.Option
+}
+
+// WithFields minifies the string fields with the given full names, such as
+// "acme.v1.Report.payload", wherever they appear in a response. Fields that
+// do not hold valid JSON are left as they are.
+func WithFields(names ...string) Option {
+	return func(c *config) {
+		for _, name := range names {
+			c.fields[protoreflect.FullName(name)] = true
+		}
+	}
+}
+
+// WithMinifierOptions configures the Minifier used for the payloads
+func WithMinifierOptions(opts ...zmin.Option) Option {
+	return func(c *config) {
+		c.minifier = append(c.minifier, opts...)
+	}
+}
+
+// interceptor minifies the JSON payloads of response messages
+type interceptor struct {
+	fields map[protoreflect.FullName]bool
+	m      *zmin.Minifier
+}
+
+func newInterceptor(opts []Option) *interceptor {
+	cfg := config{fields: make(map[protoreflect.FullName]bool)}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &interceptor{fields: cfg.fields, m: zmin.NewMinifier(cfg.minifier...)}
+}
+
+// UnaryServerInterceptor minifies the JSON in unary responses: the data of
+// every google.api.HttpBody with a JSON content type, and the string fields
+// named by WithFields. Responses are modified in place.
+func UnaryServerInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
+	i := newInterceptor(opts)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err == nil {
+			i.minify(resp)
+		}
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor minifies the JSON in streamed responses, like
+// UnaryServerInterceptor, as each message is sent
+func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
+	i := newInterceptor(opts)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &serverStream{ServerStream: ss, i: i})
+	}
+}
+
+// serverStream minifies the messages sent on a stream
+type serverStream struct {
+	grpc.ServerStream
+	i *interceptor
+}
+
+func (s *serverStream) SendMsg(m interface{}) error {
+	s.i.minify(m)
+	return s.ServerStream.SendMsg(m)
+}
+
+// minify minifies the payloads of msg if it is a protobuf message
+func (i *interceptor) minify(msg interface{}) {
+	if pm, ok := msg.(proto.Message); ok {
+		i.walk(pm.ProtoReflect())
+	}
+}
+
+// walk minifies the payloads in m and the messages nested in it
+func (i *interceptor) walk(m protoreflect.Message) {
+	if m.Descriptor().FullName() == httpBodyName {
+		i.httpBody(m)
+		return
+	}
+
+	// Fields are set after the iteration, which must not mutate m
+	var updates []protoreflect.FieldDescriptor
+	var values []protoreflect.Value
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsList():
+			list := v.List()
+			for j := 0; j < list.Len(); j++ {
+				if isMessage(fd) {
+					i.walk(list.Get(j).Message())
+				} else if i.flagged(fd) {
+					list.Set(j, i.minifyString(list.Get(j)))
+				}
+			}
+		case fd.IsMap():
+			v.Map().Range(func(key protoreflect.MapKey, value protoreflect.Value) bool {
+				if isMessage(fd.MapValue()) {
+					i.walk(value.Message())
+				}
+				return true
+			})
+		case isMessage(fd):
+			i.walk(v.Message())
+		case i.flagged(fd):
+			updates = append(updates, fd)
+			values = append(values, i.minifyString(v))
+		}
+		return true
+	})
+	for j, fd := range updates {
+		m.Set(fd, values[j])
+	}
+}
+
+// httpBody minifies the data of an HttpBody with a JSON content type
+func (i *interceptor) httpBody(m protoreflect.Message) {
+	fields := m.Descriptor().Fields()
+	contentType, data := fields.ByName("content_type"), fields.ByName("data")
+	if contentType == nil || data == nil || !isJSON(m.Get(contentType).String()) {
+		return
+	}
+	if output, err := i.m.MinifyBytes(m.Get(data).Bytes()); err == nil {
+		m.Set(data, protoreflect.ValueOfBytes(output))
+	}
+}
+
+// flagged reports whether fd is a string field named by WithFields
+func (i *interceptor) flagged(fd protoreflect.FieldDescriptor) bool {
+	return fd.Kind() == protoreflect.StringKind && i.fields[fd.FullName()]
+}
+
+// minifyString returns the minified string v, or v if it is not valid JSON
+func (i *interceptor) minifyString(v protoreflect.Value) protoreflect.Value {
+	output, err := i.m.Minify(v.String())
+	if err != nil {
+		return v
+	}
+	return protoreflect.ValueOfString(output)
+}
+
+// isMessage reports whether fd holds messages
+func isMessage(fd protoreflect.FieldDescriptor) bool {
+	return fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind
+}
+
+// isJSON reports whether contentType is a JSON media type
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
@@ -0,0 +1,58 @@
+package zmingrpc
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestUnaryServerInterceptorHttpBody(t *testing.T) {
+	intercept := UnaryServerInterceptor()
+	tests := []struct {
+		contentType string
+		want        string
+	}{
+		{"application/json", `{"a":[1,2]}`},
+		{"text/plain", `{ "a": [1, 2] }`},
+	}
+	for _, tt := range tests {
+		resp, err := intercept(context.Background(), nil, &grpc.UnaryServerInfo{},
+			func(ctx context.Context, req interface{}) (interface{}, error) {
+				return &httpbody.HttpBody{ContentType: tt.contentType, Data: []byte(`{ "a": [1, 2] }`)}, nil
+			})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(resp.(*httpbody.HttpBody).Data); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.contentType, tt.want, got)
+		}
+	}
+}
+
+func TestUnaryServerInterceptorFields(t *testing.T) {
+	tests := []struct {
+		opts  []Option
+		input string
+		want  string
+	}{
+		{[]Option{WithFields("google.protobuf.StringValue.value")}, `{ "a": 1 }`, `{"a":1}`},
+		{[]Option{WithFields("google.protobuf.StringValue.value")}, `not json`, `not json`},
+		{nil, `{ "a": 1 }`, `{ "a": 1 }`},
+	}
+	for _, tt := range tests {
+		intercept := UnaryServerInterceptor(tt.opts...)
+		resp, err := intercept(context.Background(), nil, &grpc.UnaryServerInfo{},
+			func(ctx context.Context, req interface{}) (interface{}, error) {
+				return wrapperspb.String(tt.input), nil
+			})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := resp.(*wrapperspb.StringValue).Value; got != tt.want {
+			t.Errorf("Expected %q, got %q", tt.want, got)
+		}
+	}
+}
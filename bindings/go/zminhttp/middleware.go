@@ -0,0 +1,159 @@
+// Package zminhttp minifies JSON over HTTP with zmin.
+package zminhttp
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+
+	zmin "github.com/hydepwns/zmin/go"
+)
+
+// maxBuffer is the largest response body buffered in full; larger bodies are
+// minified as they stream through
+const maxBuffer = 8 << 20
+
+// Middleware minifies the JSON responses of next. A response is minified when
+// its Content-Type is application/json or ends in +json, or is one of the
+// types set by WithContentTypes, and it carries no Content-Encoding. Other
+// responses pass through untouched, as do bodies smaller than the minimum
+// size, responses marked Cache-Control: no-transform and those opted out with
+// OptOutHeader. Responses are buffered so that Content-Length can be
+// set to the minified size, and sent as written if they turn out not to be
+// valid JSON. A response that outgrows the buffer, or whose handler flushes
+// it, is minified as it streams instead, without a Content-Length.
+func Middleware(next http.Handler, opts ...Option) http.Handler {
+	p := NewPolicy(opts...)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p.Skip(r.Method, r.Header) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		rw := &responseWriter{ResponseWriter: w, p: p}
+		defer rw.finish()
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// Response writer states
+const (
+	stateUndecided   = iota // no header written yet
+	stateBuffering          // buffering a JSON body
+	stateStreaming          // minifying a JSON body as it is written
+	statePassthrough        // forwarding a body that is not minified
+)
+
+// responseWriter buffers JSON responses and minifies them once the handler
+// returns
+type responseWriter struct {
+	http.ResponseWriter
+	p      *Policy
+	state  int
+	status int
+	buf    bytes.Buffer
+	stream *zmin.StreamMinifier
+}
+
+// WriteHeader decides whether the response is minified from its headers
+func (w *responseWriter) WriteHeader(status int) {
+	if w.state != stateUndecided {
+		return
+	}
+	w.status = status
+	if !w.p.Minifiable(status, w.Header()) {
+		w.Header().Del(OptOutHeader)
+		w.state = statePassthrough
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	w.state = stateBuffering
+	w.Header().Del("Content-Length")
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if w.state == stateUndecided {
+		w.WriteHeader(http.StatusOK)
+	}
+	switch w.state {
+	case stateBuffering:
+		if w.buf.Len()+len(p) <= maxBuffer {
+			return w.buf.Write(p)
+		}
+		if err := w.startStream(); err != nil {
+			return 0, err
+		}
+		return w.writeStream(p)
+	case stateStreaming:
+		return w.writeStream(p)
+	default:
+		return w.ResponseWriter.Write(p)
+	}
+}
+
+// Flush switches a buffered response to streaming, so that what the handler
+// wrote so far reaches the client
+func (w *responseWriter) Flush() {
+	if w.state == stateUndecided {
+		w.WriteHeader(http.StatusOK)
+	}
+	switch w.state {
+	case stateBuffering:
+		if w.startStream() != nil {
+			return
+		}
+		w.stream.Flush()
+	case stateStreaming:
+		w.stream.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying ResponseWriter, for http.ResponseController
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// startStream sends the header and streams the buffered body through a
+// StreamMinifier
+func (w *responseWriter) startStream() error {
+	w.state = stateStreaming
+	w.ResponseWriter.WriteHeader(w.status)
+	w.stream = zmin.NewStreamMinifier(w.ResponseWriter)
+	buffered := w.buf.Bytes()
+	w.buf = bytes.Buffer{}
+	_, err := w.writeStream(buffered)
+	return err
+}
+
+// writeStream minifies p through the stream. Once the body stops being valid
+// JSON the rest of it is forwarded as written.
+func (w *responseWriter) writeStream(p []byte) (int, error) {
+	n, err := w.stream.Write(p)
+	if err == nil {
+		return n, nil
+	}
+	w.state = statePassthrough
+	w.stream.Flush()
+	if _, err := w.ResponseWriter.Write(p[n:]); err != nil {
+		return n, err
+	}
+	return len(p), nil
+}
+
+// finish writes the minified buffered body once the handler has returned
+func (w *responseWriter) finish() {
+	if w.state == stateStreaming {
+		w.stream.Close()
+	}
+	// Undecided responses are left to net/http, which sends an empty 200
+	if w.state != stateBuffering {
+		return
+	}
+
+	body := w.p.Minify(w.buf.Bytes())
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.Write(body)
+}
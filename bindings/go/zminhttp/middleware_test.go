@@ -0,0 +1,161 @@
+package zminhttp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// largeJSON is a pretty-printed document above the minimum size
+var largeJSON = "{\n" + strings.Repeat("  \"key\": \"value\",\n", 40) + "  \"last\": true\n}\n"
+
+func serve(h http.Handler, method string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, "/", nil))
+	return rec
+}
+
+func jsonHandler(contentType, body string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		io.WriteString(w, body)
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	rec := serve(Middleware(jsonHandler("application/json; charset=utf-8", largeJSON)), http.MethodGet)
+	want := "{" + strings.Repeat(`"key":"value",`, 40) + `"last":true}`
+	if got := rec.Body.String(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if got := rec.Header().Get("Content-Length"); got != strconv.Itoa(len(want)) {
+		t.Errorf("Expected Content-Length %d, got %s", len(want), got)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+}
+
+func TestMiddlewarePassthrough(t *testing.T) {
+	encoded := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "identity")
+		io.WriteString(w, largeJSON)
+	})
+	tests := []struct {
+		name    string
+		handler http.Handler
+		want    string
+	}{
+		{"text", jsonHandler("text/plain", largeJSON), largeJSON},
+		{"small", jsonHandler("application/json", `{ "a": 1 }`), `{ "a": 1 }`},
+		{"invalid", jsonHandler("application/json", largeJSON+"{"), largeJSON + "{"},
+		{"encoded", encoded, largeJSON},
+	}
+	for _, tt := range tests {
+		rec := serve(Middleware(tt.handler), http.MethodGet)
+		if got := rec.Body.String(); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+		if got := rec.Header().Get("Content-Length"); got != "" && got != strconv.Itoa(len(tt.want)) {
+			t.Errorf("%s: expected Content-Length %d, got %s", tt.name, len(tt.want), got)
+		}
+	}
+}
+
+func TestMiddlewareProblemJSON(t *testing.T) {
+	rec := serve(Middleware(jsonHandler("application/problem+json", largeJSON)), http.MethodGet)
+	if strings.Contains(rec.Body.String(), "\n") {
+		t.Errorf("Expected a minified body, got %q", rec.Body.String())
+	}
+}
+
+func TestMiddlewareStatus(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, largeJSON)
+	})
+	rec := serve(Middleware(h), http.MethodPost)
+	if rec.Code != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "\n") {
+		t.Errorf("Expected a minified body, got %q", rec.Body.String())
+	}
+}
+
+func TestMiddlewareFlush(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, "[\n  1,\n")
+		w.(http.Flusher).Flush()
+		io.WriteString(w, "  2\n]\n")
+	})
+	rec := serve(Middleware(h), http.MethodGet)
+	if got := rec.Body.String(); got != "[1,2]" {
+		t.Errorf("Expected %q, got %q", "[1,2]", got)
+	}
+	if !rec.Flushed {
+		t.Error("Expected the response to be flushed")
+	}
+	if got := rec.Header().Get("Content-Length"); got != "" {
+		t.Errorf("Expected no Content-Length, got %s", got)
+	}
+}
+
+func TestMiddlewareMinSize(t *testing.T) {
+	h := jsonHandler("application/json", `{ "a": 1 }`)
+	if got := serve(Middleware(h, WithMinSize(0)), http.MethodGet).Body.String(); got != `{"a":1}` {
+		t.Errorf("Expected %q, got %q", `{"a":1}`, got)
+	}
+	big := jsonHandler("application/json", largeJSON)
+	if got := serve(Middleware(big, WithMinSize(len(largeJSON)+1)), http.MethodGet).Body.String(); got != largeJSON {
+		t.Errorf("Expected the body unchanged, got %q", got)
+	}
+}
+
+func TestMiddlewareContentTypes(t *testing.T) {
+	h := Middleware(jsonHandler("application/vnd.api+json", largeJSON), WithContentTypes("Application/JSON"))
+	if got := serve(h, http.MethodGet).Body.String(); got != largeJSON {
+		t.Errorf("Expected the body unchanged, got %q", got)
+	}
+	h = Middleware(jsonHandler("text/x-json; charset=utf-8", largeJSON), WithContentTypes("text/x-json"))
+	if got := serve(h, http.MethodGet).Body.String(); strings.Contains(got, "\n") {
+		t.Errorf("Expected a minified body, got %q", got)
+	}
+}
+
+func TestMiddlewareOptOut(t *testing.T) {
+	h := Middleware(jsonHandler("application/json", largeJSON))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(OptOutHeader, "1")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if got := rec.Body.String(); got != largeJSON {
+		t.Errorf("Expected the body unchanged, got %q", got)
+	}
+
+	for _, header := range []string{OptOutHeader, "Cache-Control"} {
+		value := "1"
+		if header == "Cache-Control" {
+			value = "public, no-transform"
+		}
+		h := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set(header, value)
+			io.WriteString(w, largeJSON)
+		}))
+		rec := serve(h, http.MethodGet)
+		if got := rec.Body.String(); got != largeJSON {
+			t.Errorf("%s: expected the body unchanged, got %q", header, got)
+		}
+		if got := rec.Header().Get(OptOutHeader); got != "" {
+			t.Errorf("Expected %s to be removed, got %q", OptOutHeader, got)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package zminhttp
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+
+	zmin "github.com/hydepwns/zmin/go"
+)
+
+// defaultMinSize is the smallest response body worth minifying
+const defaultMinSize = 512
+
+// OptOutHeader is the header that keeps a response from being minified. A
+// client sets it on the request, or a handler on the response, with any
+// non-empty value; Middleware removes it from responses.
+const OptOutHeader = "X-Zmin-Skip"
+
+// Option configures Middleware and Policy
+type Option func(*config)
+
+type config struct {
+	minifier     []zmin.Option
+	minSize      int
+	contentTypes []string // nil for the JSON media types
+}
+
+// WithMinifierOptions configures the Minifier used for the responses
+func WithMinifierOptions(opts ...zmin.Option) Option {
+	return func(c *config) {
+		c.minifier = append(c.minifier, opts...)
+	}
+}
+
+// WithMinSize sets the smallest response body, in bytes, worth minifying
+// (default 512). Smaller bodies are sent as written.
+func WithMinSize(n int) Option {
+	return func(c *config) {
+		c.minSize = n
+	}
+}
+
+// WithContentTypes restricts minification to responses of the given media
+// types, such as "application/json" or "application/vnd.api+json", instead
+// of application/json and every type ending in +json. Parameters such as
+// charset are ignored when matching.
+func WithContentTypes(types ...string) Option {
+	return func(c *config) {
+		c.contentTypes = make([]string, len(types))
+		for i, t := range types {
+			c.contentTypes[i] = strings.ToLower(t)
+		}
+	}
+}
+
+// Policy decides which responses are minified and minifies them, applying the
+// rules of Middleware. Adapters for frameworks whose responses are not written
+// through an http.ResponseWriter use it directly. A Policy is safe for
+// concurrent use.
+type Policy struct {
+	cfg config
+	m   *zmin.Minifier
+}
+
+// NewPolicy returns a Policy configured by opts
+func NewPolicy(opts ...Option) *Policy {
+	p := &Policy{cfg: config{minSize: defaultMinSize}}
+	for _, opt := range opts {
+		opt(&p.cfg)
+	}
+	p.m = zmin.NewMinifier(p.cfg.minifier...)
+	return p
+}
+
+// Skip reports whether the response to a request with the given method and
+// header is left alone: HEAD responses have no body, and clients opt out with
+// OptOutHeader
+func (p *Policy) Skip(method string, header http.Header) bool {
+	return method == http.MethodHead || header.Get(OptOutHeader) != ""
+}
+
+// Minifiable reports whether a response with the given status and header is
+// minified
+func (p *Policy) Minifiable(status int, header http.Header) bool {
+	switch {
+	case status < 200, status == http.StatusNoContent, status == http.StatusPartialContent,
+		status == http.StatusNotModified:
+		return false
+	case header.Get("Content-Encoding") != "", header.Get(OptOutHeader) != "",
+		strings.Contains(strings.ToLower(header.Get("Cache-Control")), "no-transform"):
+		return false
+	}
+	if p.cfg.contentTypes == nil {
+		return isJSON(header.Get("Content-Type"))
+	}
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	for _, t := range p.cfg.contentTypes {
+		if mediaType == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Minify returns the minified body, or body itself when it is smaller than the
+// minimum size or not valid JSON
+func (p *Policy) Minify(body []byte) []byte {
+	if len(body) < p.cfg.minSize {
+		return body
+	}
+	output, err := p.m.MinifyBytes(body)
+	if err != nil {
+		return body
+	}
+	return output
+}
+
+// isJSON reports whether contentType is a JSON media type
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
@@ -0,0 +1,98 @@
+package zminhttp
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+
+	zmin "github.com/hydepwns/zmin/go"
+)
+
+// Transport is an http.RoundTripper that minifies the JSON bodies of outgoing
+// requests, shrinking uploads with a single line:
+//
+//	client := &http.Client{Transport: &zminhttp.Transport{}}
+//
+// A request body is minified when its Content-Type is a JSON media type and it
+// carries no Content-Encoding; bodies that are not valid JSON are sent as
+// written. A Transport is safe for concurrent use.
+type Transport struct {
+	// Base performs the requests. Defaults to http.DefaultTransport.
+	Base http.RoundTripper
+
+	// Options configures the Minifier used for the request bodies
+	Options []zmin.Option
+
+	// PrettyResponses indents the JSON bodies of responses, for debugging
+	PrettyResponses bool
+
+	once     sync.Once
+	minifier *zmin.Minifier
+}
+
+// RoundTrip minifies the body of req, sends it through Base and optionally
+// indents the response body. req itself is not modified.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if req.Body != nil && req.Body != http.NoBody && isJSON(req.Header.Get("Content-Type")) &&
+		req.Header.Get("Content-Encoding") == "" {
+		var err error
+		if req, err = t.minifyRequest(req); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil || !t.PrettyResponses {
+		return resp, err
+	}
+	if isJSON(resp.Header.Get("Content-Type")) && resp.Header.Get("Content-Encoding") == "" {
+		err = prettyResponse(resp)
+	}
+	return resp, err
+}
+
+// minifyRequest returns a copy of req with its body minified
+func (t *Transport) minifyRequest(req *http.Request) (*http.Request, error) {
+	t.once.Do(func() {
+		t.minifier = zmin.NewMinifier(t.Options...)
+	})
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if output, err := t.minifier.MinifyBytes(body); err == nil {
+		body = output
+	}
+
+	req = req.Clone(req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.ContentLength = int64(len(body))
+	req.Header.Del("Content-Length")
+	return req, nil
+}
+
+// prettyResponse replaces the body of resp with its indented form
+func prettyResponse(resp *http.Response) error {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	if pretty, err := zmin.Format(body, zmin.FormatOptions{TrailingNewline: true}); err == nil {
+		body = []byte(pretty)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	return nil
+}
@@ -0,0 +1,81 @@
+package zminhttp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// echoServer answers with the body it received, under the same Content-Type
+func echoServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.ContentLength != int64(len(body)) {
+			t.Errorf("Expected ContentLength %d, got %d", len(body), r.ContentLength)
+		}
+		w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func post(t *testing.T, client *http.Client, url, contentType, body string) string {
+	resp, err := client.Post(url, contentType, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	output, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.ContentLength >= 0 && resp.ContentLength != int64(len(output)) {
+		t.Errorf("Expected ContentLength %d, got %d", len(output), resp.ContentLength)
+	}
+	return string(output)
+}
+
+func TestTransport(t *testing.T) {
+	server := echoServer(t)
+	client := &http.Client{Transport: &Transport{}}
+
+	if got := post(t, client, server.URL, "application/json", `{ "a": [1, 2] }`); got != `{"a":[1,2]}` {
+		t.Errorf("Expected %q, got %q", `{"a":[1,2]}`, got)
+	}
+	if got := post(t, client, server.URL, "text/plain", `{ "a": 1 }`); got != `{ "a": 1 }` {
+		t.Errorf("Expected %q, got %q", `{ "a": 1 }`, got)
+	}
+	if got := post(t, client, server.URL, "application/json", `{ "a": `); got != `{ "a": ` {
+		t.Errorf("Expected %q, got %q", `{ "a": `, got)
+	}
+}
+
+func TestTransportPrettyResponses(t *testing.T) {
+	server := echoServer(t)
+	client := &http.Client{Transport: &Transport{PrettyResponses: true}}
+
+	want := "{\n  \"a\": 1\n}\n"
+	if got := post(t, client, server.URL, "application/json", `{"a": 1}`); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestTransportLeavesRequest(t *testing.T) {
+	server := echoServer(t)
+	req, err := http.NewRequest(http.MethodPut, server.URL, strings.NewReader(`{ "a": 1 }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := (&Transport{}).RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if req.ContentLength != 10 {
+		t.Errorf("Expected the request to keep ContentLength 10, got %d", req.ContentLength)
+	}
+}
@@ -0,0 +1,5 @@
+// Package zminecho provides the zminhttp middleware as Echo middleware.
+//
+// The adapter is a module of its own, so that only the programs using it
+// depend on Echo.
+package zminecho
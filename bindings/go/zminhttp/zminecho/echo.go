@@ -0,0 +1,49 @@
+package zminecho
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/hydepwns/zmin/go/zminhttp"
+)
+
+// contextKey carries the invocation of a request through zminhttp
+type contextKey struct{}
+
+// invocation is a call of the next handler and its outcome
+type invocation struct {
+	c   echo.Context
+	err error
+}
+
+// Middleware returns Echo middleware minifying the JSON responses of the
+// handlers after it, as zminhttp.Middleware does:
+//
+//	e := echo.New()
+//	e.Use(zminecho.Middleware(zminhttp.WithMinSize(1024)))
+//
+// Error responses written by the HTTPErrorHandler, after the middleware has
+// returned, are not minified.
+func Middleware(opts ...zminhttp.Option) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		h := zminhttp.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			call := r.Context().Value(contextKey{}).(*invocation)
+			res := call.c.Response()
+			original := res.Writer
+			res.Writer = w
+			defer func() {
+				res.Writer = original
+			}()
+			call.err = next(call.c)
+		}), opts...)
+
+		return func(c echo.Context) error {
+			call := &invocation{c: c}
+			req := c.Request()
+			h.ServeHTTP(c.Response().Writer, req.WithContext(context.WithValue(req.Context(), contextKey{}, call)))
+			return call.err
+		}
+	}
+}
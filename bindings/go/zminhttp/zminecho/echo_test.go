@@ -0,0 +1,47 @@
+package zminecho
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/hydepwns/zmin/go/zminhttp"
+)
+
+func TestMiddleware(t *testing.T) {
+	e := echo.New()
+	e.Use(Middleware(zminhttp.WithMinSize(0)))
+	e.GET("/", func(c echo.Context) error {
+		return c.JSONPretty(http.StatusCreated, map[string][]string{"tags": {"a", "b"}}, "  ")
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	want := `{"tags":["a","b"]}`
+	if got := rec.Body.String(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if rec.Code != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Length"); got != strconv.Itoa(len(want)) {
+		t.Errorf("Expected Content-Length %d, got %s", len(want), got)
+	}
+}
+
+func TestMiddlewareError(t *testing.T) {
+	e := echo.New()
+	e.Use(Middleware())
+	e.GET("/", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusTeapot)
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusTeapot {
+		t.Errorf("Expected status 418, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,5 @@
+// Package zminfiber provides the zminhttp middleware as Fiber middleware.
+//
+// The adapter is a module of its own, so that only the programs using it
+// depend on Fiber.
+package zminfiber
@@ -0,0 +1,44 @@
+package zminfiber
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/hydepwns/zmin/go/zminhttp"
+)
+
+// Middleware returns Fiber middleware minifying the JSON responses of the
+// handlers after it, by the rules of zminhttp.Middleware:
+//
+//	app := fiber.New()
+//	app.Use(zminfiber.Middleware(zminhttp.WithMinSize(1024)))
+//
+// Fiber responses are complete once the handlers return, so the body is
+// minified in place and Fiber sets Content-Length. Streamed bodies are left
+// alone.
+func Middleware(opts ...zminhttp.Option) fiber.Handler {
+	p := zminhttp.NewPolicy(opts...)
+	return func(c *fiber.Ctx) error {
+		if p.Skip(c.Method(), http.Header{zminhttp.OptOutHeader: {c.Get(zminhttp.OptOutHeader)}}) {
+			return c.Next()
+		}
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		res := c.Response()
+		header := http.Header{}
+		for _, key := range []string{"Content-Type", "Content-Encoding", "Cache-Control", zminhttp.OptOutHeader} {
+			if value := res.Header.Peek(key); len(value) > 0 {
+				header.Set(key, string(value))
+			}
+		}
+		res.Header.Del(zminhttp.OptOutHeader)
+		if res.IsBodyStream() || !p.Minifiable(res.StatusCode(), header) {
+			return nil
+		}
+		res.SetBody(p.Minify(res.Body()))
+		return nil
+	}
+}
@@ -0,0 +1,49 @@
+package zminfiber
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/hydepwns/zmin/go/zminhttp"
+)
+
+func TestMiddleware(t *testing.T) {
+	app := fiber.New()
+	app.Use(Middleware(zminhttp.WithMinSize(0)))
+	app.Get("/", func(c *fiber.Ctx) error {
+		c.Type("json")
+		return c.SendString("{\n  \"tags\": [\"a\", \"b\"]\n}\n")
+	})
+	app.Get("/text", func(c *fiber.Ctx) error {
+		return c.SendString("{ \"a\": 1 }")
+	})
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/", `{"tags":["a","b"]}`},
+		{"/text", `{ "a": 1 }`},
+	}
+	for _, tt := range tests {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(body) != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.path, tt.want, body)
+		}
+		if resp.ContentLength != int64(len(tt.want)) {
+			t.Errorf("%s: expected Content-Length %d, got %d", tt.path, len(tt.want), resp.ContentLength)
+		}
+	}
+}
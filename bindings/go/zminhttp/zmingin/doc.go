@@ -0,0 +1,5 @@
+// Package zmingin provides the zminhttp middleware as Gin middleware.
+//
+// The adapter is a module of its own, so that only the programs using it
+// depend on Gin.
+package zmingin
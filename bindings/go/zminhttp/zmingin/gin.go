@@ -0,0 +1,101 @@
+package zmingin
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/hydepwns/zmin/go/zminhttp"
+)
+
+// contextKey carries the *gin.Context of a request through zminhttp
+type contextKey struct{}
+
+// Middleware returns Gin middleware minifying the JSON responses of the
+// handlers after it, as zminhttp.Middleware does:
+//
+//	r := gin.New()
+//	r.Use(zmingin.Middleware(zminhttp.WithMinSize(1024)))
+//
+// Status and header writes are deferred until the body is written, as Gin
+// itself does, so that c.JSON can set the status before the Content-Type.
+// c.Writer.Size reports the size written by the handlers, before
+// minification.
+func Middleware(opts ...zminhttp.Option) gin.HandlerFunc {
+	h := zminhttp.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := r.Context().Value(contextKey{}).(*gin.Context)
+		original := c.Writer
+		rw := &responseWriter{ResponseWriter: original, w: w, status: http.StatusOK, size: -1}
+		c.Writer = rw
+		defer func() {
+			rw.WriteHeaderNow()
+			c.Writer = original
+		}()
+		c.Next()
+	}), opts...)
+
+	return func(c *gin.Context) {
+		r := c.Request.WithContext(context.WithValue(c.Request.Context(), contextKey{}, c))
+		h.ServeHTTP(c.Writer, r)
+	}
+}
+
+// responseWriter writes a Gin response through the zminhttp writer w. The
+// embedded gin.ResponseWriter still provides hijacking, pushing and close
+// notification.
+type responseWriter struct {
+	gin.ResponseWriter
+	w      http.ResponseWriter
+	status int
+	size   int // -1 until the header is written
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.w.Header()
+}
+
+// WriteHeader records the status, which is sent with the first write
+func (w *responseWriter) WriteHeader(status int) {
+	if status > 0 && !w.Written() {
+		w.status = status
+	}
+}
+
+// WriteHeaderNow sends the status if it has not been sent yet
+func (w *responseWriter) WriteHeaderNow() {
+	if !w.Written() {
+		w.size = 0
+		w.w.WriteHeader(w.status)
+	}
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	w.WriteHeaderNow()
+	n, err := w.w.Write(p)
+	w.size += n
+	return n, err
+}
+
+func (w *responseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *responseWriter) Status() int {
+	return w.status
+}
+
+func (w *responseWriter) Size() int {
+	return w.size
+}
+
+func (w *responseWriter) Written() bool {
+	return w.size != -1
+}
+
+func (w *responseWriter) Flush() {
+	w.WriteHeaderNow()
+	if f, ok := w.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
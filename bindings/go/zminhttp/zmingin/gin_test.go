@@ -0,0 +1,53 @@
+package zmingin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/hydepwns/zmin/go/zminhttp"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Middleware(zminhttp.WithMinSize(0)))
+	r.GET("/", func(c *gin.Context) {
+		c.IndentedJSON(http.StatusCreated, gin.H{"tags": []string{"a", "b"}})
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	want := `{"tags":["a","b"]}`
+	if got := rec.Body.String(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if rec.Code != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Length"); got != strconv.Itoa(len(want)) {
+		t.Errorf("Expected Content-Length %d, got %s", len(want), got)
+	}
+}
+
+func TestMiddlewareStatusOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Middleware())
+	r.GET("/", func(c *gin.Context) {
+		c.Status(http.StatusAccepted)
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("Expected status 202, got %d", rec.Code)
+	}
+	if strings.TrimSpace(rec.Body.String()) != "" {
+		t.Errorf("Expected no body, got %q", rec.Body.String())
+	}
+}
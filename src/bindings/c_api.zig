@@ -3,7 +3,10 @@
 //! This module provides a C-compatible API for using zmin from other languages.
 
 const std = @import("std");
+const builtin = @import("builtin");
 const zmin = @import("../root.zig");
+const simd_detector = @import("../platform/simd_detector.zig");
+const errorCode = @import("error_codes.zig").errorCode;
 
 /// Result structure for C API
 pub const ZminResult = extern struct {
@@ -12,8 +15,8 @@ pub const ZminResult = extern struct {
     error_code: c_int,
 };
 
-/// Thread-local allocator for C API
-threadlocal var c_allocator: ?std.mem.Allocator = null;
+/// Allocator for C API, shared by every thread calling into the library
+var c_allocator: ?std.mem.Allocator = null;
 
 /// Initialize the C API
 export fn zmin_init() void {
@@ -21,11 +24,82 @@ export fn zmin_init() void {
     c_allocator = std.heap.c_allocator;
 }
 
+/// Initialize the C API after checking that the CPU supports every
+/// instruction set extension the library was compiled for
+/// Returns 0, or -14 if the CPU lacks a required feature, in which case the
+/// library is left uninitialized.
+export fn zmin_init_checked() c_int {
+    if (!cpuSupported()) {
+        return -14; // Unsupported CPU
+    }
+    zmin_init();
+    return 0;
+}
+
+/// Whether the running CPU has all the features of the compilation target
+fn cpuSupported() bool {
+    const native = std.zig.system.resolveTargetQuery(.{}) catch return true;
+    if (native.cpu.arch != builtin.cpu.arch) {
+        return true;
+    }
+    return native.cpu.features.isSuperSetOf(builtin.cpu.features);
+}
+
 /// Get version string
 export fn zmin_get_version() [*c]const u8 {
     return "1.0.0";
 }
 
+/// Report whether a processing mode is supported on this CPU
+/// Returns 1 if it is, 0 if not or for an invalid mode.
+export fn zmin_mode_supported(mode: c_int) c_int {
+    const processing_mode: zmin.ProcessingMode = switch (mode) {
+        0 => .eco,
+        1 => .sport,
+        2 => .turbo,
+        else => return 0,
+    };
+    return @intFromBool(zmin.MinifierInterface.isModeSupported(processing_mode));
+}
+
+/// Get the SIMD instruction sets in use, as a bit set
+/// Bit n - 1 is set for the nth level of simd_detector.SimdLevel:
+/// SSE2, SSE4.1, AVX, AVX2, AVX-512, NEON, SVE, SVE2.
+export fn zmin_get_simd_features() c_uint {
+    const features = simd_detector.detect();
+    var bits: c_uint = 0;
+    inline for (@typeInfo(simd_detector.SimdLevel).@"enum".fields) |field| {
+        const level: simd_detector.SimdLevel = @enumFromInt(field.value);
+        if (level != .none and features.supportsSimdLevel(level)) {
+            bits |= @as(c_uint, 1) << (field.value - 1);
+        }
+    }
+    return bits;
+}
+
+/// Limit the SIMD instruction sets the library uses
+/// name: a level name such as "AVX2" or "NEON", matched case-insensitively,
+/// "scalar" to disable SIMD, or null or "" to use the best the CPU supports
+/// Returns 0, -3 for an unknown name, or -14 if the CPU lacks the level.
+export fn zmin_set_simd_level(name: [*c]const u8) c_int {
+    const requested = if (name == null) "" else std.mem.span(name);
+    var level: ?simd_detector.SimdLevel = null;
+    if (requested.len > 0) {
+        level = for (std.enums.values(simd_detector.SimdLevel)) |l| {
+            if (std.ascii.eqlIgnoreCase(requested, l.getName()) or std.ascii.eqlIgnoreCase(requested, @tagName(l))) {
+                break l;
+            }
+        } else return -3; // Invalid mode
+    }
+    simd_detector.setMaxLevel(level) catch return -14; // Unsupported CPU
+    return 0;
+}
+
+/// Get the largest input accepted by a single call
+export fn zmin_get_max_input_size() usize {
+    return std.math.maxInt(isize);
+}
+
 /// Minify JSON with default mode (SPORT)
 export fn zmin_minify(input: [*c]const u8, input_size: usize) ZminResult {
     return zmin_minify_mode(input, input_size, 1); // 1 = SPORT
@@ -34,6 +108,11 @@ export fn zmin_minify(input: [*c]const u8, input_size: usize) ZminResult {
 /// Minify JSON with specified mode
 /// mode: 0 = ECO, 1 = SPORT, 2 = TURBO
 export fn zmin_minify_mode(input: [*c]const u8, input_size: usize, mode: c_int) ZminResult {
+    return minifyMode(input, input_size, mode, null);
+}
+
+/// Minify with an optional cap on the threads used by TURBO mode
+fn minifyMode(input: [*c]const u8, input_size: usize, mode: c_int, thread_count: ?u32) ZminResult {
     const allocator = c_allocator orelse {
         return ZminResult{
             .data = null,
@@ -58,17 +137,11 @@ export fn zmin_minify_mode(input: [*c]const u8, input_size: usize, mode: c_int)
     const input_slice = input[0..input_size];
 
     // Minify
-    const output = zmin.minifyWithMode(allocator, input_slice, processing_mode) catch |err| {
-        const error_code: c_int = switch (err) {
-            error.InvalidJson => -1,
-            error.OutOfMemory => -2,
-            else => -99,
-        };
-
+    const output = zmin.minifyWithThreads(allocator, input_slice, processing_mode, thread_count) catch |err| {
         return ZminResult{
             .data = null,
             .size = 0,
-            .error_code = error_code,
+            .error_code = errorCode(err),
         };
     };
 
@@ -92,16 +165,111 @@ export fn zmin_minify_mode(input: [*c]const u8, input_size: usize, mode: c_int)
     };
 }
 
+/// Minify JSON into a caller-provided buffer
+/// Returns 0 on success and stores the output length in output_size.
+/// If the buffer is too small, returns -4 and stores the required size.
+export fn zmin_minify_into(
+    input: [*c]const u8,
+    input_size: usize,
+    mode: c_int,
+    output: [*c]u8,
+    output_capacity: usize,
+    output_size: *usize,
+) c_int {
+    return minifyInto(input, input_size, mode, null, output, output_capacity, output_size);
+}
+
+/// zmin_minify_into with an optional cap on the threads used by TURBO mode
+/// ECO and SPORT stream their output straight into the caller's buffer, with
+/// no intermediate copy; TURBO builds it in memory and copies it once.
+fn minifyInto(
+    input: [*c]const u8,
+    input_size: usize,
+    mode: c_int,
+    thread_count: ?u32,
+    output: [*c]u8,
+    output_capacity: usize,
+    output_size: *usize,
+) c_int {
+    const allocator = c_allocator orelse return -99; // Not initialized
+
+    const processing_mode = switch (mode) {
+        0 => zmin.ProcessingMode.eco,
+        1 => zmin.ProcessingMode.sport,
+        2 => zmin.ProcessingMode.turbo,
+        else => return -3, // Invalid mode
+    };
+
+    var stream = std.io.fixedBufferStream(input[0..input_size]);
+    var sink = BufferSink{ .buffer = if (output_capacity == 0) &.{} else output[0..output_capacity] };
+    zmin.MinifierInterface.minifyWithThreads(allocator, processing_mode, stream.reader(), sink.writer(), thread_count) catch |err| {
+        return errorCode(err);
+    };
+
+    output_size.* = sink.size;
+    if (sink.size > output_capacity) {
+        return -4; // Buffer too small
+    }
+    return 0;
+}
+
+/// Writer filling a caller-provided buffer. Output past the end is counted
+/// but dropped, so that the size a larger buffer needs can be reported.
+const BufferSink = struct {
+    buffer: []u8,
+    size: usize = 0,
+
+    fn write(self: *BufferSink, bytes: []const u8) error{}!usize {
+        if (self.size < self.buffer.len) {
+            const n = @min(bytes.len, self.buffer.len - self.size);
+            @memcpy(self.buffer[self.size..][0..n], bytes[0..n]);
+        }
+        self.size += bytes.len;
+        return bytes.len;
+    }
+
+    fn writer(self: *BufferSink) std.io.GenericWriter(*BufferSink, error{}, write) {
+        return .{ .context = self };
+    }
+};
+
+/// Minify a batch of documents in a single call
+/// The documents are stored back to back in input, with their lengths in
+/// input_sizes. Each output is written to output at the offset of its input,
+/// which always leaves enough room, and its length to output_sizes.
+/// error_codes receives 0 or the error code of each document.
+/// Returns 0, or -3 for an invalid mode.
+export fn zmin_minify_batch(
+    input: [*c]const u8,
+    input_sizes: [*c]const usize,
+    count: usize,
+    mode: c_int,
+    output: [*c]u8,
+    output_sizes: [*c]usize,
+    error_codes: [*c]c_int,
+) c_int {
+    if (mode < 0 or mode > 2) {
+        return -3; // Invalid mode
+    }
+
+    var offset: usize = 0;
+    var i: usize = 0;
+    while (i < count) : (i += 1) {
+        const size = input_sizes[i];
+        output_sizes[i] = 0;
+        error_codes[i] = zmin_minify_into(input + offset, size, mode, output + offset, size, &output_sizes[i]);
+        offset += size;
+    }
+    return 0;
+}
+
 /// Validate JSON
 /// Returns 0 for valid, error code for invalid
 export fn zmin_validate(input: [*c]const u8, input_size: usize) c_int {
     const input_slice = input[0..input_size];
 
     zmin.validate(input_slice) catch |err| {
-        return switch (err) {
-            error.InvalidJson => -1,
-            else => -99,
-        };
+        return errorCode(err);
     };
 
     return 0;
@@ -125,6 +293,17 @@ export fn zmin_get_error_message(error_code: c_int) [*c]const u8 {
         -1 => "Invalid JSON",
         -2 => "Out of memory",
         -3 => "Invalid mode",
+        -4 => "Buffer too small",
+        -5 => "Maximum nesting depth exceeded",
+        -6 => "Input too large",
+        -7 => "Unterminated string",
+        -8 => "Unexpected end of input",
+        -9 => "Invalid escape sequence",
+        -10 => "Invalid number",
+        -11 => "Trailing comma",
+        -12 => "Duplicate key",
+        -13 => "Unexpected character",
+        -14 => "Unsupported CPU",
         -99 => "Unknown error",
         else => "Unknown error code",
     };
@@ -139,17 +318,20 @@ export fn zmin_estimate_output_size(input_size: usize) usize {
 // Additional helpers for specific language bindings
 
 /// Create a new minifier instance (for languages that prefer object-oriented API)
+/// Returns null for an invalid mode or if the library is not initialized.
 export fn zmin_create_minifier(mode: c_int) ?*anyopaque {
     const allocator = c_allocator orelse return null;
 
+    const processing_mode: zmin.ProcessingMode = switch (mode) {
+        0 => .eco,
+        1 => .sport,
+        2 => .turbo,
+        else => return null,
+    };
+
     const minifier = allocator.create(MinifierState) catch return null;
     minifier.* = MinifierState{
-        .mode = switch (mode) {
-            0 => .eco,
-            1 => .sport,
-            2 => .turbo,
-            else => .sport,
-        },
+        .mode = processing_mode,
         .allocator = allocator,
     };
 
@@ -176,7 +358,38 @@ export fn zmin_minifier_minify(minifier: ?*anyopaque, input: [*c]const u8, input
     return zmin_minify_mode(input, input_size, @intFromEnum(state.mode));
 }
 
+/// Minify into a caller-provided buffer using a minifier instance
+/// Same contract as zmin_minify_into.
+export fn zmin_minifier_minify_into(
+    minifier: ?*anyopaque,
+    input: [*c]const u8,
+    input_size: usize,
+    output: [*c]u8,
+    output_capacity: usize,
+    output_size: *usize,
+) c_int {
+    const ptr = minifier orelse return -99;
+
+    const state: *MinifierState = @ptrCast(@alignCast(ptr));
+    return minifyInto(input, input_size, @intFromEnum(state.mode), state.thread_count, output, output_capacity, output_size);
+}
+
+/// Cap the threads TURBO mode may use for a minifier instance
+/// threads: 0 = one per CPU (default)
+/// Returns 0, or -3 for a negative thread count.
+export fn zmin_minifier_set_threads(minifier: ?*anyopaque, threads: c_int) c_int {
+    const ptr = minifier orelse return -99;
+    if (threads < 0) {
+        return -3;
+    }
+
+    const state: *MinifierState = @ptrCast(@alignCast(ptr));
+    state.thread_count = if (threads == 0) null else @intCast(threads);
+    return 0;
+}
+
 const MinifierState = struct {
     mode: zmin.ProcessingMode,
     allocator: std.mem.Allocator,
+    thread_count: ?u32 = null,
 };
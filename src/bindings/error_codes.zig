@@ -0,0 +1,39 @@
+//! Error codes shared by the C API and the WebAssembly exports
+
+const std = @import("std");
+
+/// Error codes reported for zmin errors, matched by error name so that
+/// errors from every processing mode are covered
+const error_codes = .{
+    .{ "InvalidJson", -1 },
+    .{ "OutOfMemory", -2 },
+    .{ "UnsupportedMode", -3 },
+    .{ "NestingTooDeep", -5 },
+    .{ "DepthLimitExceeded", -5 },
+    .{ "JsonTooLarge", -6 },
+    .{ "UnterminatedString", -7 },
+    .{ "UnexpectedEndOfInput", -8 },
+    .{ "InvalidEscapeSequence", -9 },
+    .{ "InvalidUnicodeEscape", -9 },
+    .{ "InvalidUnicode", -9 },
+    .{ "InvalidNumber", -10 },
+    .{ "TrailingComma", -11 },
+    .{ "DuplicateKey", -12 },
+    .{ "InvalidCharacter", -13 },
+    .{ "UnexpectedCharacter", -13 },
+};
+
+/// Map a zmin error to its C API error code
+pub fn errorCode(err: anyerror) c_int {
+    const name = @errorName(err);
+    inline for (error_codes) |entry| {
+        if (std.mem.eql(u8, name, entry[0])) {
+            return entry[1];
+        }
+    }
+    // Remaining parser errors all describe malformed input
+    if (std.mem.startsWith(u8, name, "Invalid")) {
+        return -1;
+    }
+    return -99;
+}
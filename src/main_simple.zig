@@ -2,6 +2,7 @@ const std = @import("std");
 const zmin_lib = @import("zmin_lib");
 const MinifyingParser = zmin_lib.minifier.MinifyingParser;
 const ParallelMinifier = zmin_lib.parallel.ParallelMinifier;
+const errorCode = @import("bindings/error_codes.zig").errorCode;
 
 const version = "1.0.0";
 
@@ -16,6 +17,7 @@ const Config = struct {
     stats: bool = false,
     threads: ?usize = null,
     parallel: bool = true,
+    serve: bool = false,
 };
 
 pub fn main() !void {
@@ -39,6 +41,11 @@ pub fn main() !void {
         return;
     }
 
+    if (config.serve) {
+        try serve(allocator);
+        return;
+    }
+
     // Set up input reader
     var input_reader: std.io.AnyReader = undefined;
     var input_file: ?std.fs.File = null;
@@ -84,6 +91,53 @@ pub fn main() !void {
     }
 }
 
+/// Minify and validate documents framed on stdin until it is closed, so that
+/// clients such as the Go bindings can keep the process running. A request is
+/// an operation byte ('m' to minify, 'v' to validate), a processing mode byte
+/// and the document, preceded by its length as a little-endian u32. A reply
+/// is the C API error code as a little-endian i32 and the output, preceded by
+/// its length as a little-endian u32.
+fn serve(allocator: std.mem.Allocator) !void {
+    const reader = std.io.getStdIn().reader();
+    var buffered = std.io.bufferedWriter(std.io.getStdOut().writer());
+    const writer = buffered.writer();
+
+    var input = std.ArrayList(u8).init(allocator);
+    defer input.deinit();
+
+    while (true) {
+        var header: [6]u8 = undefined;
+        const header_size = try reader.readAll(&header);
+        if (header_size == 0) return;
+        if (header_size < header.len) return error.UnexpectedEndOfInput;
+
+        try input.resize(std.mem.readInt(u32, header[2..6], .little));
+        try reader.readNoEof(input.items);
+
+        var output: []u8 = &.{};
+        defer allocator.free(output);
+        const code: i32 = switch (header[0]) {
+            'm' => blk: {
+                const mode = std.meta.intToEnum(zmin_lib.ProcessingMode, header[1]) catch break :blk -3;
+                output = zmin_lib.minify(allocator, input.items, mode) catch |err| break :blk errorCode(err);
+                break :blk 0;
+            },
+            'v' => blk: {
+                zmin_lib.validate(input.items) catch |err| break :blk errorCode(err);
+                break :blk 0;
+            },
+            else => -3,
+        };
+
+        var reply: [8]u8 = undefined;
+        std.mem.writeInt(i32, reply[0..4], code, .little);
+        std.mem.writeInt(u32, reply[4..8], @intCast(output.len), .little);
+        try writer.writeAll(&reply);
+        try writer.writeAll(output);
+        try buffered.flush();
+    }
+}
+
 const ProcessStats = struct {
     bytes_read: u64 = 0,
     bytes_written: u64 = 0,
@@ -203,6 +257,8 @@ fn parseArgs(allocator: std.mem.Allocator, args: []const []const u8) !Config {
                 std.debug.print("Error: thread count must be greater than 0\n", .{});
                 std.process.exit(1);
             }
+        } else if (std.mem.eql(u8, arg, "--serve")) {
+            config.serve = true;
         } else if (std.mem.eql(u8, arg, "--single-threaded")) {
             config.parallel = false;
             config.threads = 1;
@@ -257,6 +313,7 @@ fn printHelp() void {
         \\    -s, --stats          Show processing statistics
         \\    -t, --threads        Number of threads for parallel processing
         \\    --single-threaded    Force single-threaded mode
+        \\    --serve              Process framed requests from stdin until it closes
         \\
         \\EXAMPLES:
         \\    # Minify a file
@@ -14,6 +14,18 @@ pub const MinifierInterface = struct {
         mode: ProcessingMode,
         reader: anytype,
         writer: anytype,
+    ) !void {
+        return minifyWithThreads(allocator, mode, reader, writer, null);
+    }
+
+    /// Minify JSON from reader to writer, capping the threads TURBO mode may
+    /// use (null detects the CPU count)
+    pub fn minifyWithThreads(
+        allocator: std.mem.Allocator,
+        mode: ProcessingMode,
+        reader: anytype,
+        writer: anytype,
+        thread_count: ?u32,
     ) !void {
         const config = ModeConfig.fromMode(mode);
 
@@ -40,7 +52,7 @@ pub const MinifierInterface = struct {
                 const output = try allocator.alloc(u8, input.len);
                 defer allocator.free(output);
 
-                const result = try minifier.minify(input, turbo.TurboConfig{});
+                const result = try minifier.minify(input, turbo.TurboConfig{ .thread_count = thread_count });
                 defer allocator.free(result.output);
                 @memcpy(output[0..result.output.len], result.output);
                 try writer.writeAll(output[0..result.output.len]);
@@ -53,12 +65,22 @@ pub const MinifierInterface = struct {
         allocator: std.mem.Allocator,
         mode: ProcessingMode,
         input: []const u8,
+    ) ![]u8 {
+        return minifyStringWithThreads(allocator, mode, input, null);
+    }
+
+    /// Minify JSON string, capping the threads TURBO mode may use
+    pub fn minifyStringWithThreads(
+        allocator: std.mem.Allocator,
+        mode: ProcessingMode,
+        input: []const u8,
+        thread_count: ?u32,
     ) ![]u8 {
         var stream = std.io.fixedBufferStream(input);
         var output = std.ArrayList(u8).init(allocator);
         defer output.deinit();
 
-        try minify(allocator, mode, stream.reader(), output.writer());
+        try minifyWithThreads(allocator, mode, stream.reader(), output.writer(), thread_count);
         return output.toOwnedSlice();
     }
 
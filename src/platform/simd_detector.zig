@@ -154,6 +154,9 @@ pub const CpuFeatures = struct {
 var cached_features: ?CpuFeatures = null;
 var features_mutex = std.Thread.Mutex{};
 
+/// Highest SIMD level reported by detect(), null for no limit
+var max_level: ?SimdLevel = null;
+
 /// Detect CPU features (cached)
 pub fn detect() CpuFeatures {
     features_mutex.lock();
@@ -163,11 +166,47 @@ pub fn detect() CpuFeatures {
         return features;
     }
     
-    const features = detectUncached();
+    var features = detectUncached();
+    if (max_level) |level| {
+        limitFeatures(&features, level);
+    }
     cached_features = features;
     return features;
 }
 
+/// Limit the SIMD levels reported by detect() to level and below, or lift
+/// the limit with null. Used to force scalar code or a specific instruction
+/// set, for example under emulation.
+pub fn setMaxLevel(level: ?SimdLevel) error{UnsupportedSimdLevel}!void {
+    if (level) |l| {
+        if (!detectUncached().supportsSimdLevel(l)) {
+            return error.UnsupportedSimdLevel;
+        }
+    }
+    
+    features_mutex.lock();
+    defer features_mutex.unlock();
+    max_level = level;
+    cached_features = null;
+}
+
+/// Clear the features of SIMD levels above level
+fn limitFeatures(features: *CpuFeatures, level: SimdLevel) void {
+    const max = @intFromEnum(level);
+    if (max < @intFromEnum(SimdLevel.sse2)) features.sse2 = false;
+    if (max < @intFromEnum(SimdLevel.sse4_1)) features.sse4_1 = false;
+    if (max < @intFromEnum(SimdLevel.avx)) features.avx = false;
+    if (max < @intFromEnum(SimdLevel.avx2)) features.avx2 = false;
+    if (max < @intFromEnum(SimdLevel.avx512)) {
+        features.avx512f = false;
+        features.avx512bw = false;
+        features.avx512vl = false;
+    }
+    if (max < @intFromEnum(SimdLevel.neon)) features.neon = false;
+    if (max < @intFromEnum(SimdLevel.sve)) features.sve = false;
+    if (max < @intFromEnum(SimdLevel.sve2)) features.sve2 = false;
+}
+
 /// Detect CPU features (uncached)
 pub fn detectUncached() CpuFeatures {
     var features = CpuFeatures{};
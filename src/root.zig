@@ -34,6 +34,11 @@ pub fn minifyWithMode(allocator: std.mem.Allocator, input: []const u8, mode: Pro
     return MinifierInterface.minifyString(allocator, mode, input);
 }
 
+/// Minify with an upper bound on the threads TURBO mode may use (null detects the CPU count)
+pub fn minifyWithThreads(allocator: std.mem.Allocator, input: []const u8, mode: ProcessingMode, thread_count: ?u32) ![]u8 {
+    return MinifierInterface.minifyStringWithThreads(allocator, mode, input, thread_count);
+}
+
 pub fn validate(input: []const u8) !void {
     // Simple validation - just try to parse as JSON
     const null_writer = std.io.null_writer.any();
@@ -5,6 +5,7 @@
 
 const std = @import("std");
 const zmin = @import("../root.zig");
+const errorCode = @import("../bindings/error_codes.zig").errorCode;
 
 /// Global allocator for WASM (uses a fixed buffer)
 var wasm_buffer: [16 * 1024 * 1024]u8 = undefined; // 16MB buffer
@@ -50,6 +51,59 @@ export fn zmin_minify_mode(input_ptr: [*]const u8, input_len: u32, mode: i32) Wa
     return minifyWithMode(input_ptr, input_len, mode);
 }
 
+/// Minify JSON into a host-provided buffer, with the error codes of the C API
+/// Returns 0 on success and stores the output length at output_size.
+/// If the buffer is too small, returns -4 and stores the required size.
+/// Working memory is released before returning, so hosts only free the
+/// buffers they allocated, with zmin_free in the reverse order.
+export fn zmin_minify_into(
+    input_ptr: [*]const u8,
+    input_len: u32,
+    mode: i32,
+    output_ptr: [*]u8,
+    output_capacity: u32,
+    output_size: *u32,
+) i32 {
+    const processing_mode: zmin.ProcessingMode = switch (mode) {
+        0 => .eco,
+        1 => .sport,
+        2 => .turbo,
+        else => return @intFromEnum(WasmError.invalid_mode),
+    };
+
+    // Everything allocated past this point is working memory of this call
+    const mark = wasm_fba.end_index;
+    defer wasm_fba.end_index = mark;
+
+    const output = zmin.minifyWithMode(wasm_allocator, input_ptr[0..input_len], processing_mode) catch |err| {
+        return errorCode(err);
+    };
+
+    output_size.* = @intCast(output.len);
+    if (output.len > output_capacity) {
+        return @intFromEnum(WasmError.buffer_too_small);
+    }
+    @memcpy(output_ptr[0..output.len], output);
+    return 0;
+}
+
+/// Report whether a processing mode is supported, as in the C API
+export fn zmin_mode_supported(mode: i32) i32 {
+    const processing_mode: zmin.ProcessingMode = switch (mode) {
+        0 => .eco,
+        1 => .sport,
+        2 => .turbo,
+        else => return 0,
+    };
+    return @intFromBool(zmin.MinifierInterface.isModeSupported(processing_mode));
+}
+
+/// Get the largest input that fits in memory alongside its output and the
+/// parser's working memory
+export fn zmin_get_max_input_size() u32 {
+    return wasm_buffer.len / 4;
+}
+
 /// Validate JSON without minifying
 /// Returns 0 for valid, error code for invalid
 export fn zmin_validate(input_ptr: [*]const u8, input_len: u32) i32 {
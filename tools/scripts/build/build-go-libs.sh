@@ -0,0 +1,26 @@
+#!/bin/bash
+
+# Cross-compile libzmin for the platforms embedded by the Go bindings' zmin_embed
+# build tag, into bindings/go/lib/<GOOS>_<GOARCH>/
+set -e
+
+cd "$(dirname "$0")/../../.."
+
+# zig target, Go platform directory and library name
+targets=(
+    "x86_64-linux-gnu linux_amd64 libzmin.so"
+    "aarch64-linux-gnu linux_arm64 libzmin.so"
+    "x86_64-macos darwin_amd64 libzmin.dylib"
+    "aarch64-macos darwin_arm64 libzmin.dylib"
+    "x86_64-windows-gnu windows_amd64 zmin.dll"
+    "aarch64-windows-gnu windows_arm64 zmin.dll"
+)
+
+for entry in "${targets[@]}"; do
+    read -r target platform name <<< "$entry"
+    out="bindings/go/lib/$platform"
+    mkdir -p "$out"
+    echo "Building $out/$name..."
+    zig build-lib -dynamic -lc -O ReleaseFast -target "$target" \
+        src/bindings/c_api.zig -femit-bin="$out/$name"
+done